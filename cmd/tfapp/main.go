@@ -10,7 +10,6 @@ import (
 
 	"tfapp/internal/cli"
 	"tfapp/internal/config"
-	apperrors "tfapp/internal/errors"
 	"tfapp/internal/ui"
 )
 
@@ -42,17 +41,18 @@ func main() {
 	// Display a message if the config was created
 	if configCreated {
 		configPath, _ := config.ConfigFilePath()
-		fmt.Printf("%sCreated default configuration file at %s%s\n", ui.ColorInfo, configPath, ui.ColorReset)
-		fmt.Printf("%sYou can edit this file to customize the application colors.%s\n", ui.ColorInfo, ui.ColorReset)
-		fmt.Println()
+		// Written to stderr so it never corrupts machine-readable output on stdout
+		fmt.Fprintf(os.Stderr, "%sCreated default configuration file at %s%s\n", ui.ColorInfo, configPath, ui.ColorReset)
+		fmt.Fprintf(os.Stderr, "%sYou can edit this file to customize the application colors.%s\n", ui.ColorInfo, ui.ColorReset)
+		fmt.Fprintln(os.Stderr)
 	}
 
 	// Parse command-line flags
-	flags := cli.ParseFlags()
+	flags := cli.ParseFlags(cfg)
 
 	// Create and run the application
-	app := cli.NewApp()
+	app := cli.NewApp(cfg)
 	if err := app.Run(ctx, flags); err != nil {
-		apperrors.ExitWithError(err, 1)
+		cli.ExitWithError(err, 1)
 	}
 }
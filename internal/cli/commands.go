@@ -2,16 +2,21 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
+	"tfapp/internal/config"
+	"tfapp/internal/cost"
 	apperrors "tfapp/internal/errors"
+	"tfapp/internal/history"
 	"tfapp/internal/models"
+	"tfapp/internal/policy"
+	"tfapp/internal/risk"
 	"tfapp/internal/terraform"
 	"tfapp/internal/ui"
-	"tfapp/internal/ui/checkbox"
 	"tfapp/internal/ui/menu"
 	"tfapp/internal/utils"
 )
@@ -21,26 +26,113 @@ type App struct {
 	tfExecutor models.Executor
 	tfPlan     models.PlanService
 	tfApply    models.ApplyService
+	cfg        *config.Config
+	policy     *policy.Policy
+	catalog    *cost.Catalog
+	risk       *risk.Scorer
+	binary     string         // Binary terraform commands run, the default one if empty
+	history    *history.Store // Nil when the history is disabled
+	entry      *history.Entry // History entry of the running command
+	configErr  error          // Reported by Run, so that the app can always be created
 }
 
 // NewApp creates a new instance of the application.
-func NewApp() *App {
+func NewApp(cfg *config.Config) *App {
+	app := &App{cfg: cfg}
+
+	var err error
+	if app.policy, err = policy.New(cfg.Policy); err != nil {
+		app.configErr = apperrors.NewConfigurationError("policy", "Invalid policy rule", err)
+	}
+
+	if app.risk, err = risk.NewScorer(cfg.Risk); err != nil && app.configErr == nil {
+		app.configErr = apperrors.NewConfigurationError("risk", "Invalid risk weights", err)
+	}
+	if app.catalog, err = loadCatalog(cfg); err != nil && app.configErr == nil {
+		app.configErr = apperrors.NewConfigurationError("cost", "Invalid pricing catalog", err)
+	}
+
+	// A missing binary is reported before the app is created, for the commands that need it
+	app.binary, _ = terraform.FindBinary(cfg.Terraform.Binary)
+
+	applyManager := app.useDir("")
+	if !cfg.History.Disabled {
+		path, err := cfg.HistoryPath()
+		if err != nil && app.configErr == nil {
+			app.configErr = apperrors.NewConfigurationError("history", "Invalid history file", err)
+		}
+		app.history = history.NewStore(path)
+		applyManager.RegisterApplyCallback(app.recordApply)
+	}
+
+	return app
+}
+
+// useDir creates the terraform services of the app, running terraform in dir
+// (the current directory if empty), and returns the apply service.
+func (a *App) useDir(dir string) *terraform.ApplyManager {
 	executor := terraform.NewCommandExecutor()
-	return &App{
-		tfExecutor: executor,
-		tfPlan:     terraform.NewPlanManager(executor),
-		tfApply:    terraform.NewApplyManager(executor),
+	if a.binary != "" {
+		executor.SetBinary(a.binary)
+	}
+	executor.SetDir(dir)
+	applyManager := terraform.NewApplyManager(executor)
+
+	planManager := terraform.NewPlanManager(executor)
+	planManager.SetPolicy(a.policy)
+	planManager.SetCatalog(a.catalog)
+	planManager.SetRisk(a.risk)
+	planManager.SetDefaultArgs(a.cfg.Terraform.PlanArgs())
+	applyManager.SetPlanService(planManager)
+
+	a.tfExecutor = executor
+	a.tfPlan = planManager
+	a.tfApply = applyManager
+	return applyManager
+}
+
+// loadCatalog loads the pricing catalog used for cost estimates. Without a
+// catalog file cost estimates are disabled, unless the catalog was configured
+// explicitly, in which case the missing file is an error.
+func loadCatalog(cfg *config.Config) (*cost.Catalog, error) {
+	path, explicit, err := cfg.CatalogPath()
+	if err != nil {
+		return nil, err
 	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) && !explicit {
+		return nil, nil
+	}
+	return cost.LoadCatalog(path, cfg.Cost.HoursPerMonth)
 }
 
-// Run executes the main application logic.
+// Run executes the main application logic and records the run in the history.
 func (a *App) Run(ctx context.Context, flags *Flags) error {
+	if a.configErr != nil {
+		return a.configErr
+	}
+
+	a.startHistory(flags)
+	err := a.run(ctx, flags)
+	a.finishHistory(err)
+	return err
+}
+
+// run executes the command or the default plan and menu flow.
+func (a *App) run(ctx context.Context, flags *Flags) error {
+	if flags.Command != "" {
+		return a.runSubcommand(ctx, flags.Command, flags.CommandArgs)
+	}
+
 	// Create a temporary file for the plan
 	tmpPlanFile, err := createTempPlanFile()
 	if err != nil {
 		return fmt.Errorf("Failed to create temporary plan file: %w", err)
 	}
-	defer os.Remove(tmpPlanFile) // Clean up the temporary file when done
+	defer removeTempPlanFile(tmpPlanFile)
+
+	if flags.NonInteractive() {
+		return a.runNonInteractive(ctx, tmpPlanFile, flags)
+	}
 
 	// Handle initialization if requested
 	if flags.Init || flags.InitUpgrade {
@@ -49,16 +141,107 @@ func (a *App) Run(ctx context.Context, flags *Flags) error {
 		}
 	}
 
+	if err := a.useWorkspace(ctx, flags.Workspace); err != nil {
+		return err
+	}
+
 	// Generate the plan
 	resources, err := a.tfPlan.CreatePlan(ctx, tmpPlanFile, flags.AdditionalFlags, false)
+	if apperrors.IsErrNoChanges(err) {
+		a.recordNoChanges()
+		reportNoChanges()
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("Planning failed: %w", err)
+		return planFailed(err)
 	}
+	a.recordPlan(ctx, tmpPlanFile, flags.AdditionalFlags)
 
 	// Show the menu for the user to choose an action
 	return a.handleMenuSelection(ctx, tmpPlanFile, resources, flags)
 }
 
+// runNonInteractive plans without any Bubble Tea program and reports the result
+// as text or JSON. With -detailed-exitcode, a plan with changes exits with code 2.
+// A plan that destroys protected resources fails unless -allow-protected is given,
+// and so does a plan failing a policy rule of error severity.
+func (a *App) runNonInteractive(ctx context.Context, planFile string, flags *Flags) error {
+	jsonOutput := flags.Output == OutputJSON
+	a.setQuiet(jsonOutput)
+
+	if flags.Init || flags.InitUpgrade {
+		if err := a.handleInit(ctx, flags.Init, flags.InitUpgrade); err != nil {
+			return fmt.Errorf("Initialization failed: %w", err)
+		}
+	}
+
+	if err := a.useWorkspace(ctx, flags.Workspace); err != nil {
+		return err
+	}
+
+	resources, err := a.tfPlan.CreatePlan(ctx, planFile, flags.AdditionalFlags, false)
+	noChanges := apperrors.IsErrNoChanges(err)
+	if err != nil && !noChanges {
+		return fmt.Errorf("Planning failed: %w", err)
+	}
+	if noChanges {
+		a.recordNoChanges()
+	} else {
+		a.recordPlan(ctx, planFile, flags.AdditionalFlags)
+	}
+	if noChanges && !jsonOutput {
+		reportNoChanges()
+	}
+
+	hasChanges := len(resources) > 0
+	policyReport := &policy.Report{}
+	if hasChanges || jsonOutput {
+		plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+		if err != nil {
+			return fmt.Errorf("Planning failed: %w", err)
+		}
+		policyReport = a.policy.Check(plan)
+
+		if jsonOutput {
+			report := terraform.NewPlanReport(plan)
+			report.ProtectedViolations = policyReport.Violations
+			report.PolicyFailures = policyReport.Failures
+			report.CostEstimate = a.catalog.Estimate(plan)
+			report.Risk = a.risk.Assess(plan)
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return fmt.Errorf("error writing plan report: %w", err)
+			}
+			hasChanges = report.HasChanges
+		}
+	}
+
+	if violations := policyReport.Violations; len(violations) > 0 && !flags.AllowProtected {
+		return fmt.Errorf("%w: %d protected resources would be destroyed or replaced; rerun with -allow-protected to override",
+			apperrors.ErrProtectedResources, len(violations))
+	}
+	if failures := policyReport.Errors(); len(failures) > 0 {
+		return fmt.Errorf("%w: %d policy checks failed", apperrors.ErrPolicyFailed, len(failures))
+	}
+
+	if flags.DetailedExitCode && hasChanges {
+		return apperrors.NewExitError(2, nil)
+	}
+	return nil
+}
+
+// setQuiet switches the executor to quiet mode and, if requested,
+// suppresses the plan service's human-readable output as well.
+func (a *App) setQuiet(quietPlan bool) {
+	if executor, ok := a.tfExecutor.(*terraform.CommandExecutor); ok {
+		executor.SetQuiet(true)
+	}
+	if planManager, ok := a.tfPlan.(*terraform.PlanManager); ok {
+		planManager.SetQuiet(quietPlan)
+	}
+}
+
 // handleInit processes the initialization flags.
 func (a *App) handleInit(ctx context.Context, performInit, performUpgrade bool) error {
 	if !performInit && !performUpgrade {
@@ -87,22 +270,39 @@ func (a *App) handleMenuSelection(ctx context.Context, planFile string, resource
 	switch selection {
 	case "Apply Plan":
 		menu.ClearMenuOutput()
-		return a.tfApply.Apply(ctx, planFile)
+		proceed, err := a.confirmPolicy(ctx, planFile, flags.AllowProtected)
+		if err != nil {
+			return err
+		}
+		if !proceed {
+			if a.cfg.Policy.OnViolation == config.OnViolationRefuse {
+				// Let the user leave the protected resources out with a target or exclude apply
+				return a.handleMenuSelection(ctx, planFile, resources, flags)
+			}
+			return nil
+		}
+		if proceed, err := a.confirmRisk(ctx, planFile); err != nil || !proceed {
+			return err
+		}
+		return a.recoverApply(ctx, a.tfApply.Apply(ctx, planFile), flags)
 	case "Show Full Plan":
 		utils.ClearTerminal()
 		err := a.tfPlan.ShowPlan(ctx, planFile)
 		if err != nil {
 			return err
 		}
-		// Call DisplayPlanSummary directly and capture updated resources
-		updatedResources, err := terraform.DisplayPlanSummary(ctx, planFile)
+		// Redisplay the summary from the cached plan and capture updated resources
+		updatedResources, err := a.tfPlan.DisplayPlanSummary(ctx, planFile)
 		if err != nil {
 			return err
 		}
 		return a.handleMenuSelection(ctx, planFile, updatedResources, flags)
 	case "Do a target apply":
 		menu.ClearMenuOutput()
-		return a.handleTargetApply(ctx, resources, flags)
+		return a.handleTargetApply(ctx, planFile, flags)
+	case "Apply all except...":
+		menu.ClearMenuOutput()
+		return a.handleExcludeApply(ctx, planFile, flags)
 	case "Exit":
 		menu.ClearMenuOutput()
 		fmt.Println("Exiting without applying changes.")
@@ -116,89 +316,6 @@ func (a *App) handleMenuSelection(ctx context.Context, planFile string, resource
 	}
 }
 
-// handleTargetApply processes targeted resource application.
-func (a *App) handleTargetApply(ctx context.Context, resources []models.Resource, flags *Flags) error {
-	// Filter resources to exclude drifted and moved resources
-	targetableResources := make([]models.Resource, 0, len(resources))
-	for _, resource := range resources {
-		// Skip drifted resources (containing "has drifted")
-		if strings.Contains(resource.Line, "has drifted") {
-			continue
-		}
-
-		// Skip moved resources (containing "moved from")
-		if strings.Contains(resource.Line, "moved from") {
-			continue
-		}
-
-		// Only include resources with standard actions: create, update, destroy, replace
-		if resource.Action == "create" || resource.Action == "update" ||
-			resource.Action == "destroy" || resource.Action == "replace" {
-			targetableResources = append(targetableResources, resource)
-		}
-	}
-
-	// If no targetable resources, inform the user
-	if len(targetableResources) == 0 {
-		utils.ClearTerminal()
-		fmt.Printf("%sNo resources available for targeted apply. Drifted and moved resources are excluded from targeting.%s\n", ui.ColorInfo, ui.ColorReset)
-		return nil
-	}
-
-	// Convert resources to checkbox options
-	checkboxOptions := make([]checkbox.Option, 0, len(targetableResources))
-	for _, resource := range targetableResources {
-		checkboxOptions = append(checkboxOptions, checkbox.Option{
-			Name:        resource.Name,
-			Description: resource.Action,
-			Checked:     false,
-		})
-	}
-
-	// Show checkbox menu
-	selectedOptions, err := checkbox.Show(checkboxOptions)
-	if err != nil {
-		return apperrors.NewUserInteractionError("resource selection", "Failed to show resource selection menu", err)
-	}
-
-	if selectedOptions == nil || len(selectedOptions) == 0 {
-		utils.ClearTerminal()
-		fmt.Printf("%sNo resources selected for targeted apply.%s\n", ui.ColorInfo, ui.ColorReset)
-		return nil
-	}
-
-	utils.ClearTerminal()
-
-	// Filter out any existing -target flags
-	filteredFlags := make([]string, 0)
-	for _, flag := range flags.AdditionalFlags {
-		if !strings.HasPrefix(flag, "-target=") {
-			filteredFlags = append(filteredFlags, flag)
-		}
-	}
-	flags.AdditionalFlags = filteredFlags
-
-	// Add new target flags
-	for _, opt := range selectedOptions {
-		flags.AdditionalFlags = append(flags.AdditionalFlags, "-target="+opt.Name)
-	}
-
-	tmpPlanFile, err := createTempPlanFile()
-	if err != nil {
-		return fmt.Errorf("Failed to create temporary plan file: %w", err)
-	}
-	defer os.Remove(tmpPlanFile) // Clean up the temporary file when done
-
-	// Generate the plan
-	new_resources, err := a.tfPlan.CreatePlan(ctx, tmpPlanFile, flags.AdditionalFlags, true)
-	if err != nil {
-		return fmt.Errorf("Planning failed: %w", err)
-	}
-
-	// Show the menu for the user to choose an action
-	return a.handleMenuSelection(ctx, tmpPlanFile, new_resources, flags)
-}
-
 // createTempPlanFile creates a temporary file for the Terraform plan.
 func createTempPlanFile() (string, error) {
 	// Create a temporary directory
@@ -214,3 +331,14 @@ func createTempPlanFile() (string, error) {
 	// Create a temporary file path
 	return filepath.Join(tempDir, "terraform.tfplan"), nil
 }
+
+// removeTempPlanFile removes a plan file created by createTempPlanFile along with its directory.
+func removeTempPlanFile(planFile string) {
+	os.RemoveAll(filepath.Dir(planFile))
+}
+
+// reportNoChanges tells the user that the plan contains nothing to apply.
+func reportNoChanges() {
+	fmt.Printf("%s%sNo changes detected in plan. Your infrastructure is up-to-date.%s\n",
+		ui.ColorInfo, ui.TextBold, ui.ColorReset)
+}
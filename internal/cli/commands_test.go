@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/testutil"
+)
+
+func TestRunJSONOutput(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{Output: OutputJSON, DetailedExitCode: true})
+	})
+
+	var exitErr *apperrors.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || exitErr.Err != nil {
+		t.Fatalf("Run error = %v, want exit code 2 without a message", err)
+	}
+	testutil.Golden(t, "run_json_create", []byte(out))
+}
+
+func TestRunJSONOutputNoChanges(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("no_changes")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{Output: OutputJSON, DetailedExitCode: true})
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(out, `"has_changes": false`) {
+		t.Errorf("output does not report the absence of changes:\n%s", out)
+	}
+}
+
+func TestRunDetailedExitCodeMoveOnly(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("show", testutil.Response{Stdout: `{
+  "format_version": "1.2",
+  "applyable": true,
+  "complete": true,
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.assets",
+      "previous_address": "aws_s3_bucket.static",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "assets",
+      "change": {"actions": ["no-op"]}
+    }
+  ]
+}`})
+
+	for _, output := range []string{OutputText, OutputJSON} {
+		var err error
+		out := testutil.CaptureStdout(t, func() {
+			err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{CI: true, Output: output, DetailedExitCode: true})
+		})
+		var exitErr *apperrors.ExitError
+		if !errors.As(err, &exitErr) || exitErr.Code != 2 {
+			t.Errorf("Run with -output=%s error = %v, want exit code 2 for a move", output, err)
+		}
+		if !strings.Contains(out, "aws_s3_bucket.static") {
+			t.Errorf("-output=%s does not list the move:\n%s", output, out)
+		}
+	}
+}
+
+func TestRunCIText(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{CI: true, Output: OutputText})
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	for _, want := range []string{"aws_db_instance.main", "Plan: 2 to add, 1 to change, 3 to destroy."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if fake.CalledWith("apply") {
+		t.Error("terraform apply was called in CI mode")
+	}
+}
+
+func TestRunPlanningFailure(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("plan", testutil.Response{Stderr: "Error: Invalid provider configuration\n", ExitCode: 1})
+
+	var err error
+	testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{Output: OutputJSON, DetailedExitCode: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "Planning failed") {
+		t.Fatalf("Run error = %v, want a planning failure", err)
+	}
+	var exitErr *apperrors.ExitError
+	if errors.As(err, &exitErr) {
+		t.Errorf("planning failure was reported with exit code %d, want the default error exit", exitErr.Code)
+	}
+}
+
+func TestRunWithOpenTofu(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTofu(t)
+	t.Setenv(terraform.BinaryEnv, "tofu")
+	fake.OnVersion("OpenTofu", "1.8.0")
+	fake.OnShowPlan("create")
+
+	if _, err := runCommand(t, "plan"); err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	// OpenTofu 1.8.0 streams the plan with -json
+	if !fake.CalledWith("plan", "-json", "-input=false", "-out") {
+		t.Errorf("the plan was not made with tofu: %q", fake.Calls())
+	}
+
+	// -exclude needs OpenTofu 1.9.0
+	_, err := runCommand(t, "plan", "--", "-exclude=aws_instance.web")
+	if err == nil || !strings.Contains(err.Error(), "-exclude needs OpenTofu 1.9.0 or later, found 1.8.0") {
+		t.Errorf("plan with -exclude returned %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+)
+
+// runConfigCommand prints the effective configuration, with the source of each
+// value, or the path of the user configuration file.
+func (a *App) runConfigCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	action := "show"
+	if fs.NArg() > 0 {
+		action = fs.Arg(0)
+	}
+	if fs.NArg() > 1 {
+		return apperrors.NewValidationError("config", "expected a single action", apperrors.ErrInvalidInput)
+	}
+
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		return apperrors.NewConfigurationError("config", "Unable to locate the configuration file", err)
+	}
+
+	switch action {
+	case "path":
+		fmt.Println(path)
+		return nil
+	case "show":
+		cfg, sources, _, err := config.LoadConfigWithSources()
+		if err != nil {
+			return apperrors.NewConfigurationError("config", "Unable to load the configuration file", err)
+		}
+		data, err := config.Annotate(cfg, sources)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("# User configuration: %s\n", path)
+		if projectFile, err := config.FindProjectConfig("."); err == nil && projectFile != "" {
+			fmt.Printf("# Project configuration: %s\n", projectFile)
+		}
+		fmt.Printf("# Each value is followed by its source: a file, an environment variable or the default.\n%s", data)
+		return nil
+	default:
+		return apperrors.NewValidationError("config", fmt.Sprintf("unknown action %q (expected show or path)", action), apperrors.ErrInvalidInput)
+	}
+}
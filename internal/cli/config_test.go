@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	"tfapp/internal/testutil"
+)
+
+func TestConfigShowSources(t *testing.T) {
+	chdirTemp(t)
+	project := "colors:\n  info: '#333333'\n"
+	if err := os.WriteFile(config.ProjectConfigFile, []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TFAPP_STACKS_PARALLELISM", "2")
+
+	out, err := runCommand(t, "config", "show")
+	if err != nil {
+		t.Fatalf("config show returned error: %v", err)
+	}
+	for _, want := range []string{
+		"# Project configuration: ",
+		".tfapp.yaml",
+		"parallelism: 2 # env TFAPP_STACKS_PARALLELISM",
+		"error: '#ff3333' # ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(out, "info: '#333333' # ") || strings.Contains(out, "info: '#333333' # default") {
+		t.Errorf("project color is not attributed to the project file:\n%s", out)
+	}
+}
+
+func TestPlanWithProjectArgs(t *testing.T) {
+	chdirTemp(t)
+	project := "terraform:\n  var_files: [common.tfvars]\n  args: [-lock=false]\n"
+	if err := os.WriteFile(config.ProjectConfigFile, []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	cfg, _, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	app := NewApp(cfg)
+	app.setQuiet(false)
+	testutil.CaptureStdout(t, func() {
+		err = app.Run(context.Background(), &Flags{Command: "plan", CommandArgs: []string{"--", "-var=env=prod"}})
+	})
+	if err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+
+	for _, call := range fake.Calls() {
+		if call[0] != "plan" {
+			continue
+		}
+		args := strings.Join(call, " ")
+		varFile := strings.Index(args, "-var-file=")
+		if varFile < 0 || !strings.Contains(args, "common.tfvars") || !strings.Contains(args, "-lock=false") {
+			t.Fatalf("plan was not given the project arguments: %q", call)
+		}
+		if varFile > strings.Index(args, "-var=env=prod") {
+			t.Errorf("project arguments come after the command line ones: %q", call)
+		}
+		return
+	}
+	t.Fatalf("plan was not run: %q", fake.Calls())
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/planjson"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/utils"
+)
+
+// runDestroyCommand plans the destruction of every resource, lets the user review
+// the plan and narrow it down to a selection, and applies it once the user has
+// typed the workspace name (or the number of resources) to confirm.
+func (a *App) runDestroyCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	init := fs.Bool("init", false, "Run terraform init before planning")
+	initUpgrade := fs.Bool("init-upgrade", false, "Run terraform init -upgrade before planning")
+	workspaceName := fs.String("workspace", "", "Run the command in workspace `NAME`, leaving the selected one as it is")
+	allowProtected := fs.Bool("allow-protected", false, "Allow destroying protected resources")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	tmpPlanFile, err := createTempPlanFile()
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary plan file: %w", err)
+	}
+	defer removeTempPlanFile(tmpPlanFile)
+
+	if err := a.handleInit(ctx, *init, *initUpgrade); err != nil {
+		return fmt.Errorf("Initialization failed: %w", err)
+	}
+
+	if err := a.useWorkspace(ctx, *workspaceName); err != nil {
+		return err
+	}
+
+	planFlags := append([]string{"-destroy"}, fs.Args()...)
+	_, err = a.tfPlan.CreatePlan(ctx, tmpPlanFile, planFlags, false)
+	if apperrors.IsErrNoChanges(err) {
+		a.recordNoChanges()
+		fmt.Printf("%s%sNothing to destroy.%s\n", ui.ColorInfo, ui.TextBold, ui.ColorReset)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Planning failed: %w", err)
+	}
+	a.recordPlan(ctx, tmpPlanFile, planFlags)
+
+	utils.ClearTerminal()
+	if err := a.tfPlan.ShowPlan(ctx, tmpPlanFile); err != nil {
+		return err
+	}
+
+	selection, err := a.selectTargets(ctx, tmpPlanFile, "Deselect resources to keep", true)
+	if err != nil || selection == nil {
+		return err
+	}
+	if len(selection.selected) == 0 {
+		utils.ClearTerminal()
+		fmt.Printf("%sNo resources selected for destroy.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	utils.ClearTerminal()
+	planFile := tmpPlanFile
+	if kept := keptResources(selection); len(kept) > 0 {
+		// Plan again, destroying only the selected resources
+		targetFile, err := createTempPlanFile()
+		if err != nil {
+			return fmt.Errorf("Failed to create temporary plan file: %w", err)
+		}
+		defer removeTempPlanFile(targetFile)
+
+		targetFlags := []string{"-destroy"}
+		for _, flag := range fs.Args() {
+			if !strings.HasPrefix(flag, "-target=") && !strings.HasPrefix(flag, "-exclude=") {
+				targetFlags = append(targetFlags, flag)
+			}
+		}
+		for _, target := range terraform.CoarseTargets(selection.tree, selection.selected) {
+			targetFlags = append(targetFlags, "-target="+target)
+		}
+
+		fmt.Printf("%sKeeping %d resources: %s%s\n", ui.ColorInfo, len(kept), strings.Join(kept, ", "), ui.ColorReset)
+		_, err = a.tfPlan.CreatePlan(ctx, targetFile, targetFlags, true)
+		if apperrors.IsErrNoChanges(err) {
+			a.recordNoChanges()
+			fmt.Printf("%s%sNothing to destroy.%s\n", ui.ColorInfo, ui.TextBold, ui.ColorReset)
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Planning failed: %w", err)
+		}
+		a.recordPlan(ctx, targetFile, targetFlags)
+		planFile = targetFile
+	} else {
+		if _, err := a.tfPlan.DisplayPlanSummary(ctx, planFile); err != nil {
+			return err
+		}
+	}
+
+	proceed, err := a.confirmPolicy(ctx, planFile, *allowProtected)
+	if err != nil || !proceed {
+		return err
+	}
+	if proceed, err := a.confirmRisk(ctx, planFile); err != nil || !proceed {
+		return err
+	}
+
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return err
+	}
+	workspace, err := terraform.CurrentWorkspace(ctx, a.tfExecutor)
+	if err != nil {
+		return err
+	}
+	return a.tfApply.Destroy(ctx, planFile, destroyConfirmation(workspace, countDestroyed(plan)))
+}
+
+// keptResources returns the resources of the destroy plan the user deselected.
+func keptResources(selection *targetSelection) []string {
+	var kept []string
+	for _, option := range terraform.FlattenTargetTree(selection.tree) {
+		if option.Node.IsLeaf() && option.Node.Targetable && !selection.selected[option.Node.Address] {
+			kept = append(kept, option.Node.Address)
+		}
+	}
+	return kept
+}
+
+// destroyConfirmation returns what the user must type to confirm a destroy:
+// the workspace name, or the number of resources when the workspace is the
+// default one, whose name would be typed out of habit.
+func destroyConfirmation(workspace string, count int) string {
+	if workspace == "" || workspace == terraform.DefaultWorkspace {
+		return strconv.Itoa(count)
+	}
+	return workspace
+}
+
+// countDestroyed returns the number of resource instances the plan deletes, including replacements.
+func countDestroyed(plan *planjson.Plan) int {
+	count := 0
+	for _, change := range plan.ResourceChanges {
+		if change.Mode != "data" && change.Change.Actions.Contains("delete") {
+			count++
+		}
+	}
+	return count
+}
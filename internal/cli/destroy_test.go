@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func TestDestroyConfirmation(t *testing.T) {
+	tests := []struct {
+		workspace string
+		count     int
+		want      string
+	}{
+		{"production", 3, "production"},
+		{"default", 3, "3"},
+		{"", 12, "12"},
+	}
+	for _, tt := range tests {
+		if got := destroyConfirmation(tt.workspace, tt.count); got != tt.want {
+			t.Errorf("destroyConfirmation(%q, %d) = %q, want %q", tt.workspace, tt.count, got, tt.want)
+		}
+	}
+}
+
+func TestCountDestroyed(t *testing.T) {
+	tests := map[string]int{
+		"create":  0,
+		"replace": 3, // Two replacements and one deletion
+	}
+	for fixture, want := range tests {
+		plan, err := planjson.Parse(testutil.PlanFixture(t, fixture))
+		if err != nil {
+			t.Fatalf("parsing %s: %v", fixture, err)
+		}
+		if got := countDestroyed(plan); got != want {
+			t.Errorf("countDestroyed(%s) = %d, want %d", fixture, got, want)
+		}
+	}
+}
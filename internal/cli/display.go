@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/diagnostics"
+)
+
+// displayError displays an error to the user. Terraform diagnostics are shown
+// with their source, like compiler errors; other errors as apperrors displays them.
+func displayError(err error) {
+	var diagErr *terraform.DiagnosticsError
+	if !errors.As(err, &diagErr) {
+		apperrors.DisplayError(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%sterraform %s failed:%s\n\n", ui.ColorError, diagErr.Command, ui.ColorReset)
+	diagnostics.Write(os.Stderr, diagErr.Diagnostics)
+	fmt.Fprintln(os.Stderr)
+}
+
+// ExitWithError displays an error and exits with non-zero status code.
+// If err is an ExitError, its code takes precedence and only its wrapped
+// error (if any) is displayed.
+func ExitWithError(err error, code int) {
+	var exitErr *apperrors.ExitError
+	if errors.As(err, &exitErr) {
+		displayError(exitErr.Err)
+		os.Exit(exitErr.Code)
+	}
+
+	displayError(err)
+	os.Exit(code)
+}
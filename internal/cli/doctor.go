@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/utils"
+)
+
+// checkStatus is the outcome of a doctor check.
+type checkStatus int
+
+const (
+	checkOK checkStatus = iota
+	checkWarning
+	checkFailed
+)
+
+// checkResult is the outcome of a doctor check along with a short explanation.
+type checkResult struct {
+	Name    string
+	Status  checkStatus
+	Message string
+}
+
+// runDoctorCommand checks the environment tfapp runs in and reports any problems.
+// It exits with code 1 if any check failed.
+func (a *App) runDoctorCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	results := a.runDoctorChecks(ctx)
+	failed := 0
+	for _, result := range results {
+		printCheckResult(result)
+		if result.Status == checkFailed {
+			failed++
+		}
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf("%s%s%d check(s) failed.%s\n", ui.ColorError, ui.TextBold, failed, ui.ColorReset)
+		return apperrors.NewExitError(1, nil)
+	}
+	fmt.Printf("%s%sEverything looks good.%s\n", ui.ColorSuccess, ui.TextBold, ui.ColorReset)
+	return nil
+}
+
+// runDoctorChecks runs every check in order. Checks that need terraform are
+// skipped when the binary cannot be found.
+func (a *App) runDoctorChecks(ctx context.Context) []checkResult {
+	var results []checkResult
+
+	path, err := terraform.FindBinary(a.cfg.Terraform.Binary)
+	if err != nil {
+		results = append(results, checkResult{"terraform binary", checkFailed, err.Error()})
+	} else {
+		results = append(results, checkResult{"terraform binary", checkOK, path})
+		results = append(results, a.checkVersion(ctx))
+	}
+
+	results = append(results, checkConfigurationFiles(), checkInitialized())
+
+	if err == nil {
+		results = append(results, a.checkState(ctx), a.checkWorkspace(ctx))
+	}
+
+	results = append(results, checkConfig(), checkTerminal())
+	return results
+}
+
+// checkVersion reports the terraform version and whether it is outdated.
+func (a *App) checkVersion(ctx context.Context) checkResult {
+	info, err := terraform.DetectVersion(ctx, a.tfExecutor)
+	if err != nil {
+		return checkResult{"terraform version", checkFailed, err.Error()}
+	}
+	if info.Outdated {
+		return checkResult{"terraform version", checkWarning, info.String() + " (a newer version is available)"}
+	}
+	return checkResult{"terraform version", checkOK, info.String()}
+}
+
+// checkConfigurationFiles reports whether the working directory contains a Terraform configuration.
+func checkConfigurationFiles() checkResult {
+	files, _ := filepath.Glob("*.tf")
+	jsonFiles, _ := filepath.Glob("*.tf.json")
+	files = append(files, jsonFiles...)
+	if len(files) == 0 {
+		return checkResult{"configuration", checkFailed, "no .tf files in the current directory"}
+	}
+	return checkResult{"configuration", checkOK, fmt.Sprintf("%d file(s)", len(files))}
+}
+
+// checkInitialized reports whether terraform init has been run in the working directory.
+func checkInitialized() checkResult {
+	if _, err := os.Stat(".terraform"); err != nil {
+		return checkResult{"initialization", checkWarning, "not initialized; run 'tfapp -init' or 'terraform init'"}
+	}
+	if _, err := os.Stat(".terraform.lock.hcl"); err != nil {
+		return checkResult{"initialization", checkWarning, "no .terraform.lock.hcl; provider versions are not locked"}
+	}
+	return checkResult{"initialization", checkOK, "initialized with a dependency lock file"}
+}
+
+// checkState reports whether the state can be read.
+func (a *App) checkState(ctx context.Context) checkResult {
+	state, err := terraform.CurrentStateVersion(ctx, a.tfExecutor)
+	if err != nil {
+		return checkResult{"state", checkFailed, err.Error()}
+	}
+	return checkResult{"state", checkOK, state.String()}
+}
+
+// checkWorkspace reports the selected workspace.
+func (a *App) checkWorkspace(ctx context.Context) checkResult {
+	output, err := a.tfExecutor.Output(ctx, []string{"workspace", "show"})
+	if err != nil {
+		return checkResult{"workspace", checkWarning, "unable to determine the selected workspace"}
+	}
+	return checkResult{"workspace", checkOK, strings.TrimSpace(string(output))}
+}
+
+// checkConfig reports whether the tfapp configuration files can be loaded.
+func checkConfig() checkResult {
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		return checkResult{"tfapp config", checkFailed, err.Error()}
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return checkResult{"tfapp config", checkWarning, path + " does not exist; defaults are used"}
+	}
+	if _, _, err := config.LoadConfig(); err != nil {
+		return checkResult{"tfapp config", checkFailed, err.Error()}
+	}
+	if projectFile, _ := config.FindProjectConfig("."); projectFile != "" {
+		return checkResult{"tfapp config", checkOK, path + ", overridden by " + projectFile}
+	}
+	return checkResult{"tfapp config", checkOK, path}
+}
+
+// checkTerminal reports whether the interactive UI can be used.
+func checkTerminal() checkResult {
+	if !utils.IsTerminal(os.Stdout) {
+		return checkResult{"terminal", checkWarning, "stdout is not a terminal; use -ci or -output=json"}
+	}
+	return checkResult{"terminal", checkOK, "interactive"}
+}
+
+// printCheckResult prints a check result on one line.
+func printCheckResult(result checkResult) {
+	symbol, color := "✓", ui.ColorSuccess
+	switch result.Status {
+	case checkWarning:
+		symbol, color = "!", ui.ColorWarning
+	case checkFailed:
+		symbol, color = "✗", ui.ColorError
+	}
+	fmt.Printf("%s%s%s %-18s %s\n", color, symbol, ui.ColorReset, result.Name, result.Message)
+}
@@ -5,29 +5,72 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"os/exec"
 
+	"tfapp/internal/config"
 	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
 	"tfapp/internal/ui"
 	"tfapp/internal/version"
 )
 
 // Flags represents the command-line flags for the application.
 type Flags struct {
-	Init            bool
-	InitUpgrade     bool
-	Version         bool
-	Help            bool
-	AdditionalFlags []string
+	Init             bool
+	InitUpgrade      bool
+	Version          bool
+	Help             bool
+	CI               bool
+	Output           string
+	DetailedExitCode bool
+	AllowProtected   bool
+	Workspace        string // Workspace selected for the run, empty to keep the selected one
+	AdditionalFlags  []string
+	Command          string   // Subcommand name, empty for the default plan and menu flow
+	CommandArgs      []string // Arguments following the subcommand name
+}
+
+// Output formats supported by the -output flag.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// NonInteractive reports whether tfapp should run without any interactive UI.
+// Requesting machine-readable output implies CI mode.
+func (f *Flags) NonInteractive() bool {
+	return f.CI || f.Output == OutputJSON
 }
 
 // ParseFlags parses the command-line flags and returns a Flags struct.
-func ParseFlags() *Flags {
+// When the first argument names a subcommand, the remaining arguments are
+// left for the subcommand to parse. The configuration names the binary that
+// must be installed.
+func ParseFlags(cfg *config.Config) *Flags {
+	if len(os.Args) > 1 {
+		if cmd := findSubcommand(os.Args[1]); cmd != nil {
+			if !cmd.WithoutTerraform {
+				if err := checkTerraformInstalled(cfg); err != nil {
+					ExitWithError(err, 1)
+				}
+			}
+			return &Flags{
+				Output:      OutputText,
+				Command:     cmd.Name,
+				CommandArgs: os.Args[2:],
+			}
+		}
+	}
+
 	// Define command-line flags
 	init := flag.Bool("init", false, "Run terraform init before planning")
 	initUpgrade := flag.Bool("init-upgrade", false, "Run terraform init -upgrade before planning")
 	showVersion := flag.Bool("version", false, "Show version information and exit")
 	help := flag.Bool("help", false, "Display help information")
+	ci := flag.Bool("ci", false, "Run non-interactively: plan, print a summary and exit")
+	output := flag.String("output", OutputText, "Output format for the plan summary (text or json)")
+	detailedExitCode := flag.Bool("detailed-exitcode", false, "Exit with 0 for no changes, 2 for changes and 1 for errors")
+	allowProtected := flag.Bool("allow-protected", false, "Allow applying plans that destroy or replace protected resources")
+	workspace := flag.String("workspace", "", "Plan and apply in workspace `NAME`, leaving the selected one as it is")
 
 	// Create custom usage function
 	flag.Usage = func() {
@@ -60,16 +103,24 @@ func ParseFlags() *Flags {
 
 	// Create the Flags struct
 	flags := &Flags{
-		Init:            *init,
-		InitUpgrade:     *initUpgrade,
-		Version:         *showVersion || hasLongVersion,
-		Help:            *help,
-		AdditionalFlags: flag.Args(),
+		Init:             *init,
+		InitUpgrade:      *initUpgrade,
+		Version:          *showVersion || hasLongVersion,
+		Help:             *help,
+		CI:               *ci,
+		Output:           *output,
+		DetailedExitCode: *detailedExitCode,
+		AllowProtected:   *allowProtected,
+		Workspace:        *workspace,
+		AdditionalFlags:  flag.Args(),
 	}
 
 	// Validate the flags
 	if err := validateFlags(flags); err != nil {
-		apperrors.ExitWithError(err, 1)
+		ExitWithError(err, 1)
+	}
+	if err := checkTerraformInstalled(cfg); err != nil {
+		ExitWithError(err, 1)
 	}
 
 	return flags
@@ -81,11 +132,23 @@ func DisplayHelp() {
 	fmt.Printf("Version: %s\n\n", version.Full())
 
 	fmt.Println("USAGE:")
-	fmt.Printf("  tfapp [tfapp-flags] -- [terraform-arguments]\n\n")
+	fmt.Printf("  tfapp [tfapp-flags] -- [terraform-arguments]\n")
+	fmt.Printf("  tfapp <command> [command-flags] [arguments]\n\n")
+
+	fmt.Println("COMMANDS:")
+	for _, cmd := range subcommands {
+		fmt.Printf("  %-20s %s\n", cmd.Name, cmd.Summary)
+	}
+	fmt.Printf("\n  Run 'tfapp <command> -help' for the flags of a command.\n\n")
 
 	fmt.Println("FLAGS:")
 	fmt.Printf("  %-20s %s\n", "-init", "Run terraform init before creating a plan")
 	fmt.Printf("  %-20s %s\n", "-init-upgrade", "Run terraform init -upgrade to update modules and providers")
+	fmt.Printf("  %-20s %s\n", "-ci", "Run non-interactively: plan, print a summary and exit")
+	fmt.Printf("  %-20s %s\n", "-output=json", "Print the plan summary as JSON (implies -ci)")
+	fmt.Printf("  %-20s %s\n", "-detailed-exitcode", "In CI mode, exit 0 for no changes, 2 for changes, 1 for errors")
+	fmt.Printf("  %-20s %s\n", "-allow-protected", "Override the protected resource policy")
+	fmt.Printf("  %-20s %s\n", "-workspace NAME", "Plan and apply in workspace NAME, leaving the selected one as it is")
 	fmt.Printf("  %-20s %s\n", "-version, --version", "Show version information and exit")
 	fmt.Printf("  %-20s %s\n\n", "-help, --help", "Display this help information")
 
@@ -108,6 +171,16 @@ func DisplayHelp() {
 	fmt.Printf("  # Use a variable file\n")
 	fmt.Printf("  tfapp -- -var-file=production.tfvars\n\n")
 
+	fmt.Printf("  # Machine-readable plan summary for pipelines\n")
+	fmt.Printf("  tfapp -output=json -detailed-exitcode > plan-summary.json\n\n")
+
+	fmt.Printf("  # Save a plan and apply it later\n")
+	fmt.Printf("  tfapp plan -save release-42 -- -var-file=production.tfvars\n")
+	fmt.Printf("  tfapp apply release-42\n\n")
+
+	fmt.Printf("  # Plan and apply in the production workspace\n")
+	fmt.Printf("  tfapp -workspace production\n\n")
+
 	fmt.Printf("  # Use auto-approval (non-interactive mode)\n")
 	fmt.Printf("  tfapp -- -auto-approve\n\n")
 
@@ -128,20 +201,34 @@ func validateFlags(flags *Flags) error {
 		)
 	}
 
-	// Check if Terraform is installed
-	if _, err := os.Stat("/usr/local/bin/terraform"); os.IsNotExist(err) {
-		if _, err = os.Stat("/usr/bin/terraform"); os.IsNotExist(err) {
-			// Check the PATH for terraform
-			_, err := exec.LookPath("terraform")
-			if err != nil {
-				return apperrors.NewConfigurationError(
-					"dependencies",
-					"Terraform executable not found in PATH",
-					err,
-				)
-			}
-		}
+	if flags.Output != OutputText && flags.Output != OutputJSON {
+		return apperrors.NewValidationError(
+			"output",
+			fmt.Sprintf("unsupported output format %q (expected %q or %q)", flags.Output, OutputText, OutputJSON),
+			apperrors.ErrInvalidInput,
+		)
 	}
 
+	if flags.DetailedExitCode && !flags.NonInteractive() {
+		return apperrors.NewValidationError(
+			"detailed-exitcode",
+			"-detailed-exitcode can only be used with -ci or -output=json",
+			apperrors.ErrInvalidInput,
+		)
+	}
+
+	return nil
+}
+
+// checkTerraformInstalled returns an error if the binary tfapp runs, terraform
+// or the one configured, cannot be found.
+func checkTerraformInstalled(cfg *config.Config) error {
+	if _, err := terraform.FindBinary(cfg.Terraform.Binary); err != nil {
+		return apperrors.NewConfigurationError(
+			"dependencies",
+			"Terraform executable not found",
+			err,
+		)
+	}
 	return nil
 }
@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/history"
+	"tfapp/internal/planjson"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	stacksui "tfapp/internal/ui/stacks"
+	"tfapp/internal/version"
+)
+
+// startHistory starts the history entry of a run.
+func (a *App) startHistory(flags *Flags) {
+	if a.history == nil || flags.Command == "history" {
+		return
+	}
+
+	entry := &history.Entry{
+		Time:         time.Now().UTC(),
+		User:         currentUser(),
+		Workspace:    terraform.SelectedWorkspace(),
+		TfappVersion: version.Full(),
+		Command:      flags.Command,
+		Args:         flags.CommandArgs,
+	}
+	if entry.Command == "" {
+		entry.Command = "menu"
+		if flags.NonInteractive() {
+			entry.Command = "ci"
+		}
+		entry.Args = flags.AdditionalFlags
+	}
+	if entry.Args == nil {
+		entry.Args = []string{}
+	}
+	entry.WorkingDir, _ = os.Getwd()
+	a.entry = entry
+}
+
+// finishHistory records the outcome of the run in the history. Failing to
+// write the history never fails the run.
+func (a *App) finishHistory(err error) {
+	entry := a.entry
+	if entry == nil {
+		return
+	}
+	a.entry = nil
+	entry.Seconds = time.Since(entry.Time).Round(time.Millisecond).Seconds()
+
+	var exitErr *apperrors.ExitError
+	status := errors.As(err, &exitErr) && exitErr.Err == nil && exitErr.Code == 2 // -detailed-exitcode reporting changes
+	switch {
+	case entry.Outcome == history.OutcomeApplied || entry.Outcome == history.OutcomeApplyFailed:
+		// Set by the apply
+	case apperrors.IsErrUserAborted(err):
+		entry.Outcome = history.OutcomeAborted
+	case err != nil && !status:
+		entry.Outcome = history.OutcomeFailed
+	case entry.Outcome == "":
+		entry.Outcome = history.OutcomeSucceeded
+	}
+	if err != nil && !status {
+		entry.Error = err.Error()
+	}
+
+	if err := a.history.Append(*entry); err != nil {
+		fmt.Fprintf(os.Stderr, "%sWarning: %s%s\n", ui.ColorWarning, err, ui.ColorReset)
+	}
+}
+
+// recordPlan records the plan of the run: what it changes and, for a targeted
+// plan, its targets. args are the arguments the plan was made with.
+func (a *App) recordPlan(ctx context.Context, planFile string, args []string) {
+	if a.entry == nil {
+		return
+	}
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return
+	}
+
+	counts := terraform.NewPlanReport(plan).Counts
+	a.entry.Counts = &history.Counts{Add: counts.Add, Change: counts.Change, Destroy: counts.Destroy, Replace: counts.Replace}
+	a.entry.TerraformVersion = plan.TerraformVersion
+	a.entry.Summary = string(a.renderSummary(plan))
+	a.entry.Targets = nil
+	for _, arg := range args {
+		if target, ok := strings.CutPrefix(arg, "-target="); ok {
+			a.entry.Targets = append(a.entry.Targets, target)
+		}
+	}
+	a.entry.Outcome = history.OutcomePlanned
+}
+
+// recordNoChanges records that the plan of the run had nothing to apply.
+func (a *App) recordNoChanges() {
+	if a.entry != nil {
+		a.entry.Counts = &history.Counts{}
+		a.entry.Outcome = history.OutcomeNoChanges
+	}
+}
+
+// recordApply records the result of terraform apply; it is registered with the apply service.
+func (a *App) recordApply(err error) {
+	if a.entry == nil {
+		return
+	}
+	a.entry.Outcome = history.OutcomeApplied
+	if err != nil {
+		a.entry.Outcome = history.OutcomeApplyFailed
+	}
+	var applyErr *apperrors.ApplyError
+	if errors.As(err, &applyErr) {
+		a.entry.Failed = applyErr.Failed
+	}
+}
+
+// recordStacks records a stacks session: the changes of all the plans, the
+// stacks that failed to apply and the final status of every stack.
+func (a *App) recordStacks(runs []*stackRun) {
+	if a.entry == nil {
+		return
+	}
+	counts := &history.Counts{}
+	planned, applied, failed := false, false, false
+	a.entry.Failed = nil
+	for _, run := range runs {
+		if run.plan != nil {
+			c := terraform.NewPlanReport(run.plan).Counts
+			counts.Add += c.Add
+			counts.Change += c.Change
+			counts.Destroy += c.Destroy
+			counts.Replace += c.Replace
+			planned = true
+		}
+		switch run.status {
+		case stacksui.StatusApplied:
+			applied = true
+		case stacksui.StatusApplyFailed:
+			failed = true
+			a.entry.Failed = append(a.entry.Failed, run.Name)
+		}
+	}
+	a.entry.Counts = counts
+
+	switch {
+	case failed:
+		a.entry.Outcome = history.OutcomeApplyFailed
+	case applied:
+		a.entry.Outcome = history.OutcomeApplied
+	case planned:
+		a.entry.Outcome = history.OutcomePlanned
+	default:
+		a.entry.Outcome = history.OutcomeNoChanges
+	}
+
+	var summary bytes.Buffer
+	stacksui.Write(&summary, stackRows(runs))
+	a.entry.Summary = summary.String()
+}
+
+// renderSummary renders the summary of a plan as printed after planning.
+func (a *App) renderSummary(plan *planjson.Plan) []byte {
+	var summary bytes.Buffer
+	terraform.WriteWorkspace(&summary, terraform.WorkspaceOf(a.tfExecutor))
+	terraform.WriteHighRisk(&summary, a.risk.Assess(plan))
+	terraform.WritePlanSummary(&summary, plan)
+	terraform.WriteCostEstimate(&summary, a.catalog.Estimate(plan))
+	terraform.WritePolicyReport(&summary, a.policy.Check(plan))
+	return summary.Bytes()
+}
+
+// currentUser returns the name of the user running tfapp.
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// runHistoryCommand lists past runs, or shows one of them.
+func (a *App) runHistoryCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	var filter history.Filter
+	fs.StringVar(&filter.Command, "command", "", "Only list runs of `COMMAND` (menu, ci, plan, apply, destroy, ...)")
+	fs.StringVar(&filter.Outcome, "outcome", "", "Only list runs with `OUTCOME` (applied, apply_failed, planned, no_changes, aborted, failed, succeeded)")
+	fs.StringVar(&filter.Workspace, "workspace", "", "Only list runs in `WORKSPACE`")
+	fs.StringVar(&filter.Text, "search", "", "Only list runs whose arguments, targets, failed resources or error contain `TEXT`")
+	fs.IntVar(&filter.Limit, "n", 20, "List at most `N` runs, 0 for all")
+	here := fs.Bool("here", false, "Only list runs in the current directory")
+	since := fs.String("since", "", "Only list runs since `TIME`, a duration such as 7d or a date such as 2006-01-02")
+	jsonOutput := fs.Bool("json", false, "Print the entries as JSON")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	if a.history == nil {
+		return apperrors.NewConfigurationError("history", "The history is disabled", nil)
+	}
+
+	if *since != "" {
+		start, err := history.ParseSince(*since, time.Now())
+		if err != nil {
+			return apperrors.NewValidationError("since", err.Error(), apperrors.ErrInvalidInput)
+		}
+		filter.Since = start
+	}
+	if *here {
+		dir, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("error getting working directory: %w", err)
+		}
+		filter.WorkingDir = dir
+	}
+
+	switch {
+	case fs.NArg() == 0:
+		entries, err := a.history.List(filter)
+		if err != nil {
+			return err
+		}
+		if *jsonOutput {
+			if entries == nil {
+				entries = []history.Entry{}
+			}
+			return printJSON(entries)
+		}
+		return listHistory(entries)
+	case fs.NArg() == 2 && fs.Arg(0) == "show":
+		id, err := strconv.Atoi(fs.Arg(1))
+		if err != nil {
+			return apperrors.NewValidationError("history", fmt.Sprintf("invalid history entry %q", fs.Arg(1)), apperrors.ErrInvalidInput)
+		}
+		entry, err := a.history.Get(id)
+		if err != nil {
+			return apperrors.NewValidationError("history", err.Error(), apperrors.ErrInvalidInput)
+		}
+		if *jsonOutput {
+			return printJSON(entry)
+		}
+		printHistoryEntry(entry)
+		return nil
+	default:
+		return apperrors.NewValidationError("history", "expected no arguments, or 'show ID'", apperrors.ErrInvalidInput)
+	}
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding history: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// listHistory prints a table of history entries.
+func listHistory(entries []history.Entry) error {
+	if len(entries) == 0 {
+		fmt.Printf("%sNo runs recorded.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTIME\tCOMMAND\tWORKSPACE\tCHANGES\tOUTCOME\tDURATION\tDIRECTORY")
+	for _, entry := range entries {
+		changes := "-"
+		if entry.Counts != nil {
+			changes = entry.Counts.String()
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", entry.ID, entry.Time.Local().Format("2006-01-02 15:04"),
+			entry.Command, entry.Workspace, changes, entry.Outcome, entry.Duration().Round(time.Second), entry.WorkingDir)
+	}
+	return w.Flush()
+}
+
+// printHistoryEntry prints every detail of a history entry, with the plan summary.
+func printHistoryEntry(entry *history.Entry) {
+	fmt.Printf("%s%sRun %d: %s%s\n", ui.ColorInfo, ui.TextBold, entry.ID, outcomeText(entry.Outcome), ui.ColorReset)
+	fmt.Printf("Time: %s (%s)\n", entry.Time.Local().Format(time.RFC1123), entry.Duration().Round(time.Millisecond))
+	fmt.Printf("User: %s\n", entry.User)
+	fmt.Printf("Directory: %s\n", entry.WorkingDir)
+	fmt.Printf("Workspace: %s\n", entry.Workspace)
+	fmt.Printf("Command: tfapp %s\n", entry.Command)
+	if len(entry.Args) > 0 {
+		fmt.Printf("Arguments: %v\n", entry.Args)
+	}
+	fmt.Printf("Versions: tfapp %s", entry.TfappVersion)
+	if entry.TerraformVersion != "" {
+		fmt.Printf(", terraform %s", entry.TerraformVersion)
+	}
+	fmt.Println()
+	if len(entry.Targets) > 0 {
+		fmt.Printf("Targets: %s\n", strings.Join(entry.Targets, ", "))
+	}
+	if len(entry.Failed) > 0 {
+		fmt.Printf("%sFailed resources: %s%s\n", ui.ColorError, strings.Join(entry.Failed, ", "), ui.ColorReset)
+	}
+	if entry.Error != "" {
+		fmt.Printf("%sError:%s %s\n", ui.ColorError, ui.ColorReset, entry.Error)
+	}
+	if entry.Summary != "" {
+		fmt.Println()
+		fmt.Print(entry.Summary)
+	}
+}
+
+// outcomeText describes an outcome for people.
+func outcomeText(outcome string) string {
+	switch outcome {
+	case history.OutcomeApplied:
+		return "applied"
+	case history.OutcomeApplyFailed:
+		return "apply failed"
+	case history.OutcomePlanned:
+		return "planned, not applied"
+	case history.OutcomeNoChanges:
+		return "no changes"
+	case history.OutcomeAborted:
+		return "aborted"
+	case history.OutcomeFailed:
+		return "failed"
+	}
+	return outcome
+}
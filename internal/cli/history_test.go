@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	"tfapp/internal/history"
+	"tfapp/internal/testutil"
+)
+
+// historyEntries returns the entries recorded in the history of the test's home directory.
+func historyEntries(t *testing.T) []history.Entry {
+	t.Helper()
+
+	path, err := config.DefaultConfig().HistoryPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := history.NewStore(path).List(history.Filter{})
+	if err != nil {
+		t.Fatalf("listing history: %v", err)
+	}
+	return entries
+}
+
+func TestRunsAreRecorded(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	if _, err := runCommand(t, "plan", "--", "-target=aws_instance.web"); err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	fake.On("plan", testutil.Response{Stderr: "Error: Invalid provider configuration\n", ExitCode: 1})
+	if _, err := runCommand(t, "plan"); err == nil {
+		t.Fatal("failing plan returned no error")
+	}
+
+	entries := historyEntries(t)
+	if len(entries) != 2 {
+		t.Fatalf("got %d history entries, want 2", len(entries))
+	}
+
+	failed, planned := entries[0], entries[1]
+	if planned.Command != "plan" || planned.Outcome != history.OutcomePlanned {
+		t.Errorf("first run recorded as %s %s, want plan planned", planned.Command, planned.Outcome)
+	}
+	if planned.Counts == nil || planned.Counts.Add != 2 {
+		t.Errorf("first run counts = %+v, want 2 to add", planned.Counts)
+	}
+	if len(planned.Targets) != 1 || planned.Targets[0] != "aws_instance.web" {
+		t.Errorf("first run targets = %q", planned.Targets)
+	}
+	if planned.Workspace != "default" || planned.WorkingDir == "" || planned.Summary == "" {
+		t.Errorf("first run is missing details: %+v", planned)
+	}
+	if failed.Outcome != history.OutcomeFailed || !strings.Contains(failed.Error, "Invalid provider configuration") {
+		t.Errorf("second run recorded as %s (%q), want failed", failed.Outcome, failed.Error)
+	}
+}
+
+func TestHistoryCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	if _, err := runCommand(t, "plan"); err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	fake.OnShowPlan("replace")
+	if _, err := runCommand(t, "plan"); err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+
+	out, err := runCommand(t, "history")
+	if err != nil {
+		t.Fatalf("history returned error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "2 ") || !strings.Contains(lines[2], "+2 ~0 -0") {
+		t.Errorf("history does not list both runs, newest first:\n%s", out)
+	}
+
+	// Listing the history is not recorded
+	out, err = runCommand(t, "history", "-json", "-search", "nothing-matches")
+	if err != nil {
+		t.Fatalf("history -json returned error: %v", err)
+	}
+	var listed []history.Entry
+	if err := json.Unmarshal([]byte(out), &listed); err != nil || listed == nil || len(listed) != 0 {
+		t.Errorf("history -search listed %s", out)
+	}
+	if entries := historyEntries(t); len(entries) != 2 {
+		t.Errorf("got %d history entries, want 2", len(entries))
+	}
+
+	out, err = runCommand(t, "history", "show", "1")
+	if err != nil {
+		t.Fatalf("history show returned error: %v", err)
+	}
+	for _, want := range []string{"Run 1: planned, not applied", "Command: tfapp plan", "aws_instance.web"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("history show does not contain %q:\n%s", want, out)
+		}
+	}
+
+	if _, err := runCommand(t, "history", "show", "9"); err == nil {
+		t.Error("history show of a missing entry returned no error")
+	}
+}
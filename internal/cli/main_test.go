@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain points HOME to a temporary directory, so that the history of the
+// runs made by the tests is not recorded in the real one.
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "tfapp-home")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "creating home directory: %v\n", err)
+		os.Exit(1)
+	}
+	os.Setenv("HOME", home)
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
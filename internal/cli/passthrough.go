@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/ui"
+	"tfapp/internal/utils"
+)
+
+// mutatingStateCommands are the terraform state subcommands that write the state.
+var mutatingStateCommands = map[string]bool{
+	"mv":               true,
+	"rm":               true,
+	"push":             true,
+	"replace-provider": true,
+}
+
+// runStateCommand runs a terraform state subcommand, asking for confirmation
+// before any subcommand that modifies the state.
+func (a *App) runStateCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if len(args) == 0 || isHelpArg(args[0]) {
+		fs.Usage()
+		fmt.Println("Run 'terraform state -help' for the list of state subcommands.")
+		return nil
+	}
+
+	terraformArgs := append([]string{"state"}, args...)
+	if mutatingStateCommands[args[0]] {
+		fmt.Printf("%sThis will modify the Terraform state: terraform %s%s\n",
+			ui.ColorWarning, utils.ShellJoin(terraformArgs), ui.ColorReset)
+		confirmed, err := confirm("Proceed? [yes/No]: ")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Printf("%sState change aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+			return nil
+		}
+	}
+
+	return a.passThrough(ctx, terraformArgs)
+}
+
+// runImportCommand runs terraform import with the given arguments.
+func (a *App) runImportCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if len(args) == 0 || isHelpArg(args[0]) {
+		fs.Usage()
+		return nil
+	}
+	return a.passThrough(ctx, append([]string{"import"}, args...))
+}
+
+// runValidateCommand runs terraform validate. Without arguments the
+// diagnostics are rendered with their source; arguments are passed through to
+// terraform as given.
+func (a *App) runValidateCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if len(args) > 0 && isHelpArg(args[0]) {
+		fs.Usage()
+		return nil
+	}
+	if len(args) == 0 {
+		return a.validate(ctx)
+	}
+	return a.passThrough(ctx, append([]string{"validate"}, args...))
+}
+
+// passThrough runs a terraform command attached to the terminal and exits
+// with terraform's exit code if it fails.
+func (a *App) passThrough(ctx context.Context, args []string) error {
+	err := a.tfExecutor.RunInteractive(ctx, args)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// terraform has already reported the problem
+		return apperrors.NewExitError(exitErr.ExitCode(), nil)
+	}
+	if err != nil {
+		return fmt.Errorf("error executing terraform %s: %w", args[0], err)
+	}
+	return nil
+}
+
+// isHelpArg reports whether arg asks for help.
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// confirm prints prompt and reports whether the user answered "yes".
+func confirm(prompt string) (bool, error) {
+	response, err := readLine(prompt)
+	if err != nil {
+		return false, err
+	}
+	return strings.ToLower(response) == "yes", nil
+}
+
+// readLine prints prompt and returns the line the user types, without surrounding spaces.
+func readLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/testutil"
+)
+
+func TestValidatePassesThroughExitCode(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("validate", testutil.Response{Stderr: "Error: Unsupported argument\n", ExitCode: 1})
+
+	_, err := runCommand(t, "validate", "-no-color")
+	var exitErr *apperrors.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("validate error = %v, want exit code 1", err)
+	}
+	if !fake.CalledWith("validate", "-no-color") {
+		t.Error("terraform validate was not called with the given arguments")
+	}
+}
+
+func TestStateRemoveRequiresConfirmation(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+
+	testutil.Stdin(t, "no\n")
+	if _, err := runCommand(t, "state", "rm", "aws_instance.web"); err != nil {
+		t.Fatalf("state rm returned error: %v", err)
+	}
+	if fake.CalledWith("state", "rm") {
+		t.Error("terraform state rm ran without confirmation")
+	}
+
+	testutil.Stdin(t, "yes\n")
+	if _, err := runCommand(t, "state", "rm", "aws_instance.web"); err != nil {
+		t.Fatalf("state rm returned error: %v", err)
+	}
+	if !fake.CalledWith("state", "rm", "aws_instance.web") {
+		t.Error("terraform state rm was not called after confirmation")
+	}
+}
+
+func TestStateListRunsWithoutConfirmation(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+
+	if _, err := runCommand(t, "state", "list"); err != nil {
+		t.Fatalf("state list returned error: %v", err)
+	}
+	if !fake.CalledWith("state", "list") {
+		t.Error("terraform state list was not called")
+	}
+}
+
+// invalidValidate is `terraform validate -json` output for an invalid configuration.
+const invalidValidate = `{"format_version":"1.0","valid":false,"error_count":1,"warning_count":0,"diagnostics":[{"severity":"error","summary":"Unsupported argument","detail":"An argument named \"instance_typ\" is not expected here.","range":{"filename":"main.tf","start":{"line":7,"column":3,"byte":120},"end":{"line":7,"column":15,"byte":132}},"snippet":{"context":"resource \"aws_instance\" \"web\"","code":"  instance_typ = \"t3.micro\"","start_line":7,"highlight_start_offset":2,"highlight_end_offset":14,"values":[]}}]}`
+
+func TestValidateRendersDiagnostics(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("validate", testutil.Response{Stdout: invalidValidate, ExitCode: 1})
+
+	out, err := runCommand(t, "validate")
+	var exitErr *apperrors.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Err != nil {
+		t.Fatalf("validate error = %v, want exit code 1 without a message", err)
+	}
+	if !fake.CalledWith("validate", "-json") {
+		t.Errorf("terraform validate was not called with -json: %q", fake.Calls())
+	}
+	for _, want := range []string{
+		"error:",
+		"Unsupported argument",
+		"main.tf:7:3",
+		`instance_typ` + "\x1b[0m" + ` = "t3.micro"`, // Highlighted source
+
+		"^^^^^^^^^^^^",
+		"1 errors, 0 warnings",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("validate", testutil.Response{Stdout: `{"format_version":"1.0","valid":true,"error_count":0,"warning_count":0,"diagnostics":[]}`})
+
+	out, err := runCommand(t, "validate")
+	if err != nil {
+		t.Fatalf("validate returned error: %v", err)
+	}
+	if !strings.Contains(out, "The configuration is valid.") {
+		t.Errorf("output = %q", out)
+	}
+}
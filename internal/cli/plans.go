@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/planjson"
+	"tfapp/internal/planstore"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	planviewer "tfapp/internal/ui/plan"
+)
+
+// runPlanCommand creates a plan, prints its summary and, with -save, stores it as an artifact.
+func (a *App) runPlanCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	save := fs.String("save", "", "Save the plan under `NAME` in "+planstore.DefaultDir)
+	init := fs.Bool("init", false, "Run terraform init before planning")
+	initUpgrade := fs.Bool("init-upgrade", false, "Run terraform init -upgrade before planning")
+	workspaceName := fs.String("workspace", "", "Run the command in workspace `NAME`, leaving the selected one as it is")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	if *save != "" {
+		if err := planstore.ValidateName(*save); err != nil {
+			return apperrors.NewValidationError("save", err.Error(), apperrors.ErrInvalidInput)
+		}
+	}
+
+	tmpPlanFile, err := createTempPlanFile()
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary plan file: %w", err)
+	}
+	defer removeTempPlanFile(tmpPlanFile)
+
+	if err := a.handleInit(ctx, *init, *initUpgrade); err != nil {
+		return fmt.Errorf("Initialization failed: %w", err)
+	}
+
+	if err := a.useWorkspace(ctx, *workspaceName); err != nil {
+		return err
+	}
+
+	// Record the state before planning; the saved plan is only valid against this version
+	var state *terraform.StateVersion
+	if *save != "" {
+		state, err = terraform.CurrentStateVersion(ctx, a.tfExecutor)
+		if err != nil {
+			return fmt.Errorf("Planning failed: %w", err)
+		}
+	}
+
+	_, err = a.tfPlan.CreatePlan(ctx, tmpPlanFile, fs.Args(), false)
+	if apperrors.IsErrNoChanges(err) {
+		a.recordNoChanges()
+		reportNoChanges()
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Planning failed: %w", err)
+	}
+	a.recordPlan(ctx, tmpPlanFile, fs.Args())
+
+	if *save == "" {
+		return nil
+	}
+	return a.savePlan(ctx, *save, tmpPlanFile, fs.Args(), state)
+}
+
+// savePlan stores the plan file, its JSON representation and rendered summary as a named artifact.
+func (a *App) savePlan(ctx context.Context, name, planFile string, args []string, state *terraform.StateVersion) error {
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return err
+	}
+
+	// Keep the output of show -json as is: the parsed plan only holds the fields tfapp uses
+	planJSON, err := a.tfPlan.PlanJSON(ctx, planFile)
+	if err != nil {
+		return err
+	}
+
+	summary := a.renderSummary(plan)
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("error getting working directory: %w", err)
+	}
+
+	metadata := planstore.Metadata{
+		Name:             name,
+		CreatedAt:        time.Now().UTC(),
+		WorkingDir:       workingDir,
+		Workspace:        terraform.WorkspaceOf(a.tfExecutor),
+		Args:             args,
+		TerraformVersion: plan.TerraformVersion,
+		Changes:          len(terraform.NewPlanReport(plan).Resources),
+	}
+	if state != nil {
+		metadata.StateLineage = state.Lineage
+		metadata.StateSerial = state.Serial
+	}
+
+	if err := planstore.IgnoreInGit(planstore.Root); err != nil {
+		return err
+	}
+	artifact, err := planstore.NewStore(planstore.DefaultDir).Save(metadata, planFile, planJSON, summary)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s%sSaved plan %q to %s%s\n", ui.ColorSuccess, ui.TextBold, name, artifact.Dir, ui.ColorReset)
+	fmt.Printf("Apply it later with: tfapp apply %s\n", name)
+	return nil
+}
+
+// runShowCommand lists the saved plans, or shows one of them.
+func (a *App) runShowCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	summaryOnly := fs.Bool("summary", false, "Print the saved summary without opening the plan viewer")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+
+	store := planstore.NewStore(planstore.DefaultDir)
+	switch fs.NArg() {
+	case 0:
+		return listSavedPlans(store)
+	case 1:
+	default:
+		return apperrors.NewValidationError("show", "expected at most one saved plan name", apperrors.ErrInvalidInput)
+	}
+
+	artifact, err := store.Open(fs.Arg(0))
+	if err != nil {
+		return apperrors.NewValidationError("show", err.Error(), apperrors.ErrInvalidInput)
+	}
+
+	if !*summaryOnly {
+		data, err := os.ReadFile(artifact.JSONFile())
+		if err != nil {
+			return fmt.Errorf("error reading saved plan: %w", err)
+		}
+		plan, err := planjson.Parse(data)
+		if err != nil {
+			return err
+		}
+		if err := planviewer.ShowPlan(plan); err != nil {
+			return err
+		}
+	}
+
+	return printSavedPlan(artifact)
+}
+
+// listSavedPlans prints a table of the saved plans, newest first.
+func listSavedPlans(store *planstore.Store) error {
+	artifacts, err := store.List()
+	if err != nil {
+		return err
+	}
+	if len(artifacts) == 0 {
+		fmt.Printf("%sNo saved plans. Save one with 'tfapp plan -save NAME'.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCREATED\tCHANGES\tSTATE")
+	for _, artifact := range artifacts {
+		m := artifact.Metadata
+		state := &terraform.StateVersion{Lineage: m.StateLineage, Serial: m.StateSerial}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", m.Name, m.CreatedAt.Local().Format("2006-01-02 15:04"), m.Changes, state)
+	}
+	return w.Flush()
+}
+
+// printSavedPlan prints the metadata and rendered summary of a saved plan.
+func printSavedPlan(artifact *planstore.Artifact) error {
+	summary, err := artifact.Summary()
+	if err != nil {
+		return err
+	}
+
+	m := artifact.Metadata
+	fmt.Printf("%s%sSaved plan %q%s\n", ui.ColorInfo, ui.TextBold, m.Name, ui.ColorReset)
+	fmt.Printf("Created: %s\n", m.CreatedAt.Local().Format(time.RFC1123))
+	fmt.Printf("Directory: %s\n", m.WorkingDir)
+	if m.Workspace != "" {
+		fmt.Printf("Workspace: %s\n", m.Workspace)
+	}
+	if len(m.Args) > 0 {
+		fmt.Printf("Arguments: %v\n", m.Args)
+	}
+	fmt.Print(string(summary))
+	return nil
+}
+
+// runApplyCommand applies a saved plan after checking that the state has not moved since it was made.
+func (a *App) runApplyCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	allowProtected := fs.Bool("allow-protected", false, "Allow applying a plan that destroys or replaces protected resources")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return apperrors.NewValidationError("apply", "expected the name or path of a saved plan", apperrors.ErrInvalidInput)
+	}
+
+	target := fs.Arg(0)
+	artifact, err := planstore.NewStore(planstore.DefaultDir).Open(target)
+	if err != nil {
+		// A plain plan file (e.g. from terraform plan -out) is applied as is
+		if info, statErr := os.Stat(target); statErr == nil && !info.IsDir() {
+			fmt.Printf("%s%s is not a saved tfapp plan; skipping the state check.%s\n", ui.ColorWarning, target, ui.ColorReset)
+			return a.applyUnlessProtected(ctx, target, *allowProtected)
+		}
+		return apperrors.NewValidationError("apply", err.Error(), apperrors.ErrInvalidInput)
+	}
+
+	// The plan only applies to the workspace it was made in, whichever is selected now
+	if err := a.useWorkspace(ctx, artifact.Metadata.Workspace); err != nil {
+		return err
+	}
+	if err := a.checkPlanState(ctx, artifact); err != nil {
+		return err
+	}
+
+	if err := printSavedPlan(artifact); err != nil {
+		return err
+	}
+	return a.applyUnlessProtected(ctx, artifact.PlanFile(), *allowProtected)
+}
+
+// applyUnlessProtected applies a plan file once the policy allows it and high risk changes are confirmed.
+func (a *App) applyUnlessProtected(ctx context.Context, planFile string, override bool) error {
+	proceed, err := a.confirmPolicy(ctx, planFile, override)
+	if err != nil || !proceed {
+		return err
+	}
+	if proceed, err := a.confirmRisk(ctx, planFile); err != nil || !proceed {
+		return err
+	}
+	return a.tfApply.Apply(ctx, planFile)
+}
+
+// checkPlanState returns ErrStalePlan if the state serial or lineage changed since the plan was saved.
+func (a *App) checkPlanState(ctx context.Context, artifact *planstore.Artifact) error {
+	current, err := terraform.CurrentStateVersion(ctx, a.tfExecutor)
+	if err != nil {
+		return err
+	}
+
+	saved := &terraform.StateVersion{Lineage: artifact.Metadata.StateLineage, Serial: artifact.Metadata.StateSerial}
+	if current == nil {
+		current = &terraform.StateVersion{}
+	}
+	if *current != *saved {
+		return fmt.Errorf("%w: %q was made against %s, but the state is now at %s. Create a new plan with 'tfapp plan -save %s'",
+			apperrors.ErrStalePlan, artifact.Metadata.Name, saved, current, artifact.Metadata.Name)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/planstore"
+	"tfapp/internal/testutil"
+)
+
+// chdirTemp runs the rest of the test in a fresh working directory.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+
+	original, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getting working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("changing directory: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(original) })
+}
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	app := NewApp(config.DefaultConfig())
+	app.setQuiet(false) // No spinner without a terminal, but keep the human-readable output
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = app.Run(context.Background(), &Flags{Command: args[0], CommandArgs: args[1:]})
+	})
+	return out, err
+}
+
+func TestSavedPlanLifecycle(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	fake.On("workspace list", testutil.Response{Stdout: "* default\n"})
+	fake.On("state pull", testutil.Response{Stdout: `{"serial": 4, "lineage": "l1"}`})
+
+	if _, err := runCommand(t, "plan", "-save", "release", "--", "-var=env=prod"); err != nil {
+		t.Fatalf("plan -save returned error: %v", err)
+	}
+
+	// The artifact keeps the show -json output as is and stays out of git
+	saved, err := os.ReadFile(filepath.Join(planstore.DefaultDir, "release", "plan.json"))
+	if err != nil || !bytes.Equal(saved, testutil.PlanFixture(t, "create")) {
+		t.Errorf("plan.json differs from the show -json output (%v)", err)
+	}
+	if ignore, err := os.ReadFile(filepath.Join(planstore.Root, ".gitignore")); err != nil || !strings.Contains(string(ignore), "*") {
+		t.Errorf(".gitignore = %q, %v; want one ignoring everything", ignore, err)
+	}
+
+	out, err := runCommand(t, "show")
+	if err != nil {
+		t.Fatalf("show returned error: %v", err)
+	}
+	if !strings.Contains(out, "release") || !strings.Contains(out, "serial 4 of lineage l1") {
+		t.Errorf("saved plan list does not show the plan:\n%s", out)
+	}
+
+	out, err = runCommand(t, "show", "-summary", "release")
+	if err != nil {
+		t.Fatalf("show -summary returned error: %v", err)
+	}
+	if !strings.Contains(out, "Plan: 2 to add, 0 to change, 0 to destroy.") || !strings.Contains(out, "-var=env=prod") {
+		t.Errorf("saved plan summary is incomplete:\n%s", out)
+	}
+
+	testutil.Stdin(t, "yes\n")
+	if _, err := runCommand(t, "apply", "release"); err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+	if !fake.CalledWith("apply") {
+		t.Error("terraform apply was not called for an up-to-date plan")
+	}
+}
+
+func TestApplyRefusesStalePlan(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	fake.On("workspace list", testutil.Response{Stdout: "* default\n"})
+	fake.On("state pull", testutil.Response{Stdout: `{"serial": 4, "lineage": "l1"}`})
+
+	if _, err := runCommand(t, "plan", "-save", "release"); err != nil {
+		t.Fatalf("plan -save returned error: %v", err)
+	}
+
+	fake.On("state pull", testutil.Response{Stdout: `{"serial": 5, "lineage": "l1"}`})
+	_, err := runCommand(t, "apply", "release")
+	if !apperrors.IsErrStalePlan(err) {
+		t.Fatalf("apply error = %v, want ErrStalePlan", err)
+	}
+	if fake.CalledWith("apply") {
+		t.Error("terraform apply was called for a stale plan")
+	}
+}
+
+func TestApplyUsesTheWorkspaceOfThePlan(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	fake.On("workspace list", testutil.Response{Stdout: "* default\n  prod\n  staging\n"})
+	fake.On("state pull", testutil.Response{Stdout: `{"serial": 4, "lineage": "l1"}`})
+
+	if _, err := runCommand(t, "plan", "-save", "release", "-workspace", "staging"); err != nil {
+		t.Fatalf("plan -save returned error: %v", err)
+	}
+	artifact, err := planstore.NewStore(planstore.DefaultDir).Open("release")
+	if err != nil || artifact.Metadata.Workspace != "staging" {
+		t.Fatalf("saved plan = %+v, %v; want one made in workspace staging", artifact, err)
+	}
+
+	// Another workspace is selected by the time the plan is applied
+	if err := os.MkdirAll(".terraform", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "environment"), []byte("prod"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	calls := len(fake.Calls())
+
+	testutil.Stdin(t, "yes\n")
+	if _, err := runCommand(t, "apply", "release"); err != nil {
+		t.Fatalf("apply returned error: %v", err)
+	}
+	workspaces := fake.Workspaces()
+	applied := false
+	for i, call := range fake.Calls()[calls:] {
+		if call[0] != "state" && call[0] != "apply" {
+			continue
+		}
+		applied = applied || call[0] == "apply"
+		if workspaces[calls+i] != "staging" {
+			t.Errorf("%q ran with TF_WORKSPACE=%q, want staging", call, workspaces[calls+i])
+		}
+	}
+	if !applied {
+		t.Errorf("terraform apply was not called: %q", fake.Calls())
+	}
+}
+
+func TestPlanSaveRejectsInvalidName(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+
+	if _, err := runCommand(t, "plan", "-save", "../x"); !apperrors.IsValidationError(err) {
+		t.Errorf("plan -save error = %v, want a validation error", err)
+	}
+	if fake.CalledWith("plan") {
+		t.Error("terraform plan was called with an invalid plan name")
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"tfapp/internal/config"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+)
+
+// policyOverride is what the user types to apply a plan the policy blocks.
+const policyOverride = "override"
+
+// confirmPolicy checks the plan against the policy and reports whether the
+// apply may go ahead. A plan destroying protected resources or failing a rule
+// of error severity is refused or needs a second typed confirmation, depending
+// on the configuration. allowProtected, set by -allow-protected, lets protected
+// resources through without asking.
+func (a *App) confirmPolicy(ctx context.Context, planFile string, allowProtected bool) (bool, error) {
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return false, err
+	}
+	report := a.policy.Check(plan)
+
+	violations, failures := report.Violations, report.Errors()
+	if allowProtected && len(violations) > 0 {
+		terraform.WriteProtectedViolations(os.Stdout, violations)
+		fmt.Printf("%sApplying despite protected resources (-allow-protected).%s\n", ui.ColorWarning, ui.ColorReset)
+		violations = nil
+	}
+	if len(violations) == 0 && len(failures) == 0 {
+		return true, nil
+	}
+
+	terraform.WriteProtectedViolations(os.Stdout, violations)
+	terraform.WritePolicyFailures(os.Stdout, failures, report.Rules)
+
+	if a.cfg.Policy.OnViolation == config.OnViolationRefuse {
+		fmt.Printf("%s%sRefusing to apply a plan that the policy blocks.%s\n", ui.ColorError, ui.TextBold, ui.ColorReset)
+		fmt.Println("Leave the resources out with \"Do a target apply\" or \"Apply all except...\".")
+		return false, nil
+	}
+
+	response, err := readLine(fmt.Sprintf("Type %s%s%s to apply anyway: ", ui.TextBold, policyOverride, ui.ColorReset))
+	if err != nil {
+		return false, err
+	}
+	if response != policyOverride {
+		fmt.Printf("%sApply aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+		return false, nil
+	}
+	return true, nil
+}
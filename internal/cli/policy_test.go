@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/testutil"
+)
+
+// newProtectedApp creates an app that protects the database of the replace fixture.
+func newProtectedApp(onViolation string) *App {
+	cfg := config.DefaultConfig()
+	cfg.Policy.ProtectedTypes = []string{"aws_db_instance"}
+	cfg.Policy.OnViolation = onViolation
+	return NewApp(cfg)
+}
+
+func TestRunCIFailsOnProtectedResources(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newProtectedApp(config.OnViolationConfirm).Run(context.Background(), &Flags{CI: true, Output: OutputText})
+	})
+	if !apperrors.IsErrProtectedResources(err) {
+		t.Fatalf("Run error = %v, want ErrProtectedResources", err)
+	}
+	if !strings.Contains(out, "aws_db_instance.main will be replaced (protected by aws_db_instance)") {
+		t.Errorf("summary does not flag the protected resource:\n%s", out)
+	}
+}
+
+func TestRunCIAllowProtected(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newProtectedApp(config.OnViolationConfirm).Run(context.Background(), &Flags{Output: OutputJSON, AllowProtected: true})
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if !strings.Contains(out, `"protected_violations"`) || !strings.Contains(out, `"rule": "aws_db_instance"`) {
+		t.Errorf("JSON report does not list the violation:\n%s", out)
+	}
+}
+
+func TestConfirmProtected(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	tests := []struct {
+		name        string
+		onViolation string
+		input       string
+		want        bool
+	}{
+		{"typed override", config.OnViolationConfirm, "override\n", true},
+		{"plain yes", config.OnViolationConfirm, "yes\n", false},
+		{"refused", config.OnViolationRefuse, "override\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.Stdin(t, tt.input)
+			app := newProtectedApp(tt.onViolation)
+
+			var got bool
+			var err error
+			testutil.CaptureStdout(t, func() {
+				got, err = app.confirmPolicy(context.Background(), "plan.tfplan", false)
+			})
+			if err != nil {
+				t.Fatalf("confirmPolicy returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("confirmPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCIFailsPolicyRules(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Rules = []config.RuleConfig{
+		{Name: "owner-tag", Severity: config.SeverityWarning, Condition: `after.tags.owner != null`},
+		{Name: "https-only", When: `type == "aws_security_group"`, Condition: `all(after.ingress, it.from_port == 443)`},
+	}
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(cfg).Run(context.Background(), &Flags{CI: true, Output: OutputText})
+	})
+	if !apperrors.IsErrPolicyFailed(err) || !strings.Contains(err.Error(), "1 policy checks failed") {
+		t.Fatalf("Run error = %v, want one failed policy check", err)
+	}
+	for _, want := range []string{"aws_security_group.web [https-only]", "aws_instance.web [owner-tag]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunRejectsInvalidRule(t *testing.T) {
+	testutil.InstallFakeTerraform(t)
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.Rules = []config.RuleConfig{{Name: "typo", Condition: `after.tags.owner =! null`}}
+
+	err := NewApp(cfg).Run(context.Background(), &Flags{CI: true, Output: OutputText})
+	if !apperrors.IsConfigurationError(err) || !strings.Contains(err.Error(), "Invalid policy rule") {
+		t.Fatalf("Run error = %v, want an invalid policy rule", err)
+	}
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/ui"
+)
+
+// recoverApply handles the result of an apply. When the apply failed on some
+// resources, it shows why and offers to plan again for the failed resources
+// only, through the same -target flow as a target apply.
+func (a *App) recoverApply(ctx context.Context, err error, flags *Flags) error {
+	var applyErr *apperrors.ApplyError
+	if !errors.As(err, &applyErr) || len(applyErr.Failed) == 0 {
+		return err
+	}
+
+	displayError(err)
+	retry, confirmErr := confirm(fmt.Sprintf("Re-plan the %d failed resources only? [yes/No]: ", len(applyErr.Failed)))
+	if confirmErr != nil {
+		return confirmErr
+	}
+	if !retry {
+		fmt.Printf("%sFix the errors above and run tfapp again to retry.%s\n", ui.ColorWarning, ui.ColorReset)
+		// The failure has been reported already
+		return apperrors.NewExitError(1, nil)
+	}
+
+	description := fmt.Sprintf("%d failed resources", len(applyErr.Failed))
+	return a.planTargets(ctx, description, applyErr.Failed, nil, flags)
+}
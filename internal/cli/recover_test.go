@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/testutil"
+)
+
+func TestRecoverApplyDeclined(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	testutil.Stdin(t, "no\n")
+
+	failure := apperrors.NewApplyError([]string{"aws_s3_bucket.logs"}, []string{"aws_db_instance.main"}, errors.New("exit status 1"))
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).recoverApply(context.Background(), failure, &Flags{})
+	})
+
+	var exitErr *apperrors.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 || exitErr.Err != nil {
+		t.Fatalf("recoverApply error = %v, want exit code 1 without a message", err)
+	}
+	if !strings.Contains(out, "Re-plan the 1 failed resources only?") {
+		t.Errorf("output does not offer to re-plan:\n%s", out)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("terraform was called after the re-plan was declined: %q", calls)
+	}
+}
+
+func TestRecoverApplyPassesOtherResults(t *testing.T) {
+	other := errors.New("error executing terraform apply")
+	for _, err := range []error{nil, other} {
+		if got := NewApp(config.DefaultConfig()).recoverApply(context.Background(), err, &Flags{}); got != err {
+			t.Errorf("recoverApply(%v) = %v", err, got)
+		}
+	}
+}
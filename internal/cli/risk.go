@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+)
+
+// riskAcknowledgement is what the user types to apply changes scored above the confirmation threshold.
+const riskAcknowledgement = "accept risk"
+
+// confirmRisk lists the changes whose risk score needs an extra confirmation
+// and reports whether the user accepted them. Plans without such changes
+// proceed without asking.
+func (a *App) confirmRisk(ctx context.Context, planFile string) (bool, error) {
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return false, err
+	}
+	assessment := a.risk.Assess(plan)
+
+	scores := assessment.NeedsConfirmation()
+	if len(scores) == 0 {
+		return true, nil
+	}
+
+	terraform.WriteRiskScores(os.Stdout, fmt.Sprintf("Changes scored %d or more need confirmation:", assessment.ConfirmAbove), scores)
+	response, err := readLine(fmt.Sprintf("Type %s%s%s to apply them: ", ui.TextBold, riskAcknowledgement, ui.ColorReset))
+	if err != nil {
+		return false, err
+	}
+	if response != riskAcknowledgement {
+		fmt.Printf("%sApply aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+		return false, nil
+	}
+	return true, nil
+}
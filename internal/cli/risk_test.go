@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/testutil"
+)
+
+func TestConfirmRisk(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	tests := []struct {
+		name         string
+		confirmAbove int
+		input        string
+		want         bool
+		asked        bool
+	}{
+		{"accepted", 80, "accept risk\n", true, true},
+		{"plain yes", 80, "yes\n", false, true},
+		{"below threshold", 200, "", true, false},
+		{"disabled", -1, "", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.Stdin(t, tt.input)
+			cfg := config.DefaultConfig()
+			cfg.Risk.ConfirmAbove = tt.confirmAbove
+			app := NewApp(cfg)
+
+			var got bool
+			var err error
+			out := testutil.CaptureStdout(t, func() {
+				got, err = app.confirmRisk(context.Background(), "plan.tfplan")
+			})
+			if err != nil {
+				t.Fatalf("confirmRisk returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("confirmRisk() = %v, want %v", got, tt.want)
+			}
+			if asked := strings.Contains(out, "aws_db_instance.main"); asked != tt.asked {
+				t.Errorf("listed the database = %v, want %v:\n%s", asked, tt.asked, out)
+			}
+		})
+	}
+}
+
+func TestInvalidRiskWeights(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Risk.Weights = map[string]int{"explode": 100}
+
+	err := NewApp(cfg).Run(context.Background(), &Flags{CI: true})
+	if !apperrors.IsConfigurationError(err) || !strings.Contains(err.Error(), "Invalid risk weights") {
+		t.Errorf("Run error = %v, want invalid risk weights", err)
+	}
+}
@@ -0,0 +1,369 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/planjson"
+	"tfapp/internal/stack"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	stacksui "tfapp/internal/ui/stacks"
+	"tfapp/internal/utils"
+)
+
+// stackRun is a stack planned, and maybe applied, in a stacks session.
+type stackRun struct {
+	stack.Stack
+	app       *App                    // Runs terraform in the stack's directory
+	apply     *terraform.ApplyManager // The app's apply service, which can apply without asking
+	workspace string
+	planFile  string
+	plan      *planjson.Plan // Set once planned with changes
+	status    stacksui.Status
+	err       error
+}
+
+// newStackRun prepares a stack for planning. The stack shares the configuration,
+// policy, catalog, risk scorer and binary of the app, but records nothing in the history.
+func (a *App) newStackRun(s stack.Stack) *stackRun {
+	app := &App{cfg: a.cfg, policy: a.policy, catalog: a.catalog, risk: a.risk, binary: a.binary}
+	apply := app.useDir(s.Dir)
+	// Stacks are planned side by side, so none of them may draw a spinner or print its summary
+	app.setQuiet(true)
+	return &stackRun{
+		Stack:     s,
+		app:       app,
+		apply:     apply,
+		workspace: terraform.SelectedWorkspaceIn(s.Dir),
+	}
+}
+
+// runStacksCommand plans every root module under a directory, running several
+// plans at the same time, and shows the plans in a dashboard from which a
+// stack's plan can be opened in the plan viewer and a selection of stacks applied.
+func (a *App) runStacksCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	defaultParallelism := a.cfg.Stacks.Parallelism
+	if defaultParallelism <= 0 {
+		defaultParallelism = config.DefaultParallelism
+	}
+	parallelism := fs.Int("parallelism", defaultParallelism, "Plan at most `N` stacks at the same time")
+	allowProtected := fs.Bool("allow-protected", false, "Allow applying plans that destroy or replace protected resources")
+	if err := parseSubcommandFlags(fs, args); err != nil {
+		return err
+	}
+	if *parallelism < 1 {
+		return apperrors.NewValidationError("parallelism", "-parallelism must be at least 1", apperrors.ErrInvalidInput)
+	}
+
+	// An optional directory comes before the terraform arguments
+	dir, planArgs := ".", fs.Args()
+	if len(planArgs) > 0 && !strings.HasPrefix(planArgs[0], "-") {
+		dir, planArgs = planArgs[0], planArgs[1:]
+		if len(planArgs) > 0 && planArgs[0] == "--" {
+			planArgs = planArgs[1:]
+		}
+	}
+
+	found, err := stack.Discover(dir)
+	if err != nil {
+		return apperrors.NewValidationError("stacks", err.Error(), apperrors.ErrInvalidInput)
+	}
+	if len(found) == 0 {
+		fmt.Printf("%sNo root modules found under %s.%s\n", ui.ColorInfo, dir, ui.ColorReset)
+		return nil
+	}
+
+	graph, err := a.loadStackGraph(dir, found)
+	if err != nil {
+		return err
+	}
+
+	runs := make([]*stackRun, len(found))
+	for i, s := range found {
+		runs[i] = a.newStackRun(s)
+	}
+	defer func() {
+		for _, run := range runs {
+			if run.planFile != "" {
+				removeTempPlanFile(run.planFile)
+			}
+		}
+	}()
+
+	title := fmt.Sprintf("Stacks under %s", dir)
+	if !utils.IsTerminal(os.Stdout) {
+		fmt.Printf("%sPlanning %d stacks...%s\n", ui.ColorInfo, len(runs), ui.ColorReset)
+		planStacks(ctx, runs, *parallelism, planArgs, nil)
+		return a.finishStacks(runs)
+	}
+
+	plan := func(ctx context.Context, update func(i int, stack stacksui.Stack)) {
+		planStacks(ctx, runs, *parallelism, planArgs, update)
+	}
+	var choice stacksui.Choice
+	for {
+		choice, err = stacksui.Show(ctx, title, stackRows(runs), choice, plan)
+		if err != nil {
+			return apperrors.NewUserInteractionError("stacks dashboard", "Failed to show stacks dashboard", err)
+		}
+		plan = nil
+
+		switch choice.Action {
+		case stacksui.ActionView:
+			run := runs[choice.Cursor]
+			if err := run.app.tfPlan.ShowPlan(ctx, run.planFile); err != nil {
+				return err
+			}
+		case stacksui.ActionApply:
+			if err := a.applyStacks(ctx, runs, graph, choice.Selected, *allowProtected); err != nil {
+				return err
+			}
+			return a.finishStacks(runs)
+		default:
+			return a.finishStacks(runs)
+		}
+	}
+}
+
+// loadStackGraph reads the dependencies between the stacks under dir from the
+// stack manifest, if there is one.
+func (a *App) loadStackGraph(dir string, stacks []stack.Stack) (*stack.Graph, error) {
+	path, explicit, err := a.cfg.StackManifestPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest *stack.Manifest
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) || explicit {
+		manifest, err = stack.LoadManifest(path)
+		if err != nil {
+			return nil, apperrors.NewConfigurationError("stack manifest", "Failed to load the stack manifest", err)
+		}
+	}
+
+	graph, err := stack.NewGraph(stacks, manifest)
+	if err != nil {
+		return nil, apperrors.NewValidationError("stack manifest", err.Error(), apperrors.ErrInvalidInput)
+	}
+	return graph, nil
+}
+
+// planStacks plans the stacks, at most parallelism at a time, passing the new
+// state of each stack to update, if set, as it changes.
+func planStacks(ctx context.Context, runs []*stackRun, parallelism int, args []string, update func(i int, stack stacksui.Stack)) {
+	notify := func(i int) {
+		if update != nil {
+			update(i, runs[i].row())
+		}
+	}
+
+	stacks := make([]stack.Stack, len(runs))
+	for i, run := range runs {
+		stacks[i] = run.Stack
+	}
+	stack.ForEach(ctx, stacks, parallelism, func(ctx context.Context, i int) {
+		runs[i].status = stacksui.StatusPlanning
+		notify(i)
+		runs[i].planStack(ctx, args)
+		notify(i)
+	})
+}
+
+// planStack plans the stack and records the outcome.
+func (r *stackRun) planStack(ctx context.Context, args []string) {
+	planFile, err := createTempPlanFile()
+	if err != nil {
+		r.status, r.err = stacksui.StatusFailed, err
+		return
+	}
+	r.planFile = planFile
+
+	_, err = r.app.tfPlan.CreatePlan(ctx, planFile, args, false)
+	if apperrors.IsErrNoChanges(err) {
+		r.status = stacksui.StatusNoChanges
+		return
+	}
+	if err == nil {
+		r.plan, err = r.app.tfPlan.LoadPlan(ctx, planFile)
+	}
+	if err != nil {
+		r.status, r.err = stacksui.StatusFailed, err
+		return
+	}
+	r.status = stacksui.StatusChanges
+}
+
+// row returns the dashboard row of the stack.
+func (r *stackRun) row() stacksui.Stack {
+	row := stacksui.Stack{Name: r.Name, Workspace: r.workspace, Status: r.status}
+	if r.plan != nil {
+		report := terraform.NewPlanReport(r.plan)
+		row.Changes = fmt.Sprintf("+%d ~%d -%d", report.Counts.Add, report.Counts.Change, report.Counts.Destroy)
+		for _, resource := range report.Resources {
+			row.Detail = append(row.Detail, fmt.Sprintf("%-8s %s", resource.Action, resource.Address))
+		}
+	}
+	if r.err != nil {
+		row.Detail = strings.Split(strings.TrimSpace(r.err.Error()), "\n")
+	}
+	return row
+}
+
+// stackRows returns the dashboard rows of the stacks.
+func stackRows(runs []*stackRun) []stacksui.Stack {
+	rows := make([]stacksui.Stack, len(runs))
+	for i, run := range runs {
+		rows[i] = run.row()
+	}
+	return rows
+}
+
+// applyStacks applies the plans of the selected stacks, once the user has
+// confirmed them all. The stacks are applied in waves following their declared
+// dependencies, one after the other, and a stack is skipped when a stack it
+// depends on is not applied. Each stack's plan is checked against the policy
+// and risk thresholds as in a single apply.
+func (a *App) applyStacks(ctx context.Context, runs []*stackRun, graph *stack.Graph, selected []bool, allowProtected bool) error {
+	waves := graph.Waves(func(i int) bool { return selected[i] })
+	count := 0
+	for _, wave := range waves {
+		count += len(wave)
+	}
+
+	if graph.HasDependencies() {
+		fmt.Printf("%s%sStacks to apply, in dependency order:%s\n", ui.ColorInfo, ui.TextBold, ui.ColorReset)
+		stacksui.WriteWaves(os.Stdout, waveRows(runs, graph, waves))
+		fmt.Println()
+	} else {
+		var chosen []*stackRun
+		for _, i := range waves[0] {
+			chosen = append(chosen, runs[i])
+		}
+		fmt.Printf("%s%sStacks to apply:%s\n", ui.ColorInfo, ui.TextBold, ui.ColorReset)
+		stacksui.Write(os.Stdout, stackRows(chosen))
+	}
+	confirmed, err := confirm(fmt.Sprintf("Apply the plans of these %d stacks? [yes/No]: ", count))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		fmt.Printf("%sApply aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+		return nil
+	}
+
+	for w, wave := range waves {
+		if len(waves) > 1 && ctx.Err() == nil {
+			fmt.Printf("\n%s%sWave %d of %d%s\n", ui.ColorInfo, ui.TextBold, w+1, len(waves), ui.ColorReset)
+		}
+		for _, i := range wave {
+			if ctx.Err() != nil {
+				return nil
+			}
+			run := runs[i]
+			if reason := blockingReason(runs, graph, selected, i); reason != "" {
+				run.status, run.err = stacksui.StatusSkipped, fmt.Errorf("not applied because %s", reason)
+				fmt.Printf("\n%sSkipping %s: %s.%s\n", ui.ColorWarning, run.Name, reason, ui.ColorReset)
+				continue
+			}
+
+			fmt.Printf("\n%s%s%s (workspace %s)%s\n", ui.ColorInfo, ui.TextBold, run.Name, run.workspace, ui.ColorReset)
+			if err := run.applyStack(ctx, allowProtected); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// blockingReason returns why stack i cannot be applied, or "" if it can: a
+// stack it depends on failed to plan, or was selected but did not end up
+// applied, because it failed, was skipped or the user declined it. Stacks
+// left unselected with their changes do not block it.
+func blockingReason(runs []*stackRun, graph *stack.Graph, selected []bool, i int) string {
+	for _, j := range graph.DependsOn(i) {
+		switch dependency := runs[j]; dependency.status {
+		case stacksui.StatusApplied, stacksui.StatusNoChanges:
+		case stacksui.StatusFailed:
+			return dependency.Name + " failed to plan"
+		case stacksui.StatusApplyFailed:
+			return dependency.Name + " failed to apply"
+		case stacksui.StatusSkipped:
+			return dependency.Name + " was skipped"
+		default:
+			if selected[j] {
+				return dependency.Name + " was not applied"
+			}
+		}
+	}
+	return ""
+}
+
+// waveRows returns the rows of the stacks in each wave, with the stacks they depend on.
+func waveRows(runs []*stackRun, graph *stack.Graph, waves [][]int) [][]stacksui.Stack {
+	rows := make([][]stacksui.Stack, len(waves))
+	for w, wave := range waves {
+		for _, i := range wave {
+			row := runs[i].row()
+			for _, j := range graph.DependsOn(i) {
+				row.DependsOn = append(row.DependsOn, runs[j].Name)
+			}
+			rows[w] = append(rows[w], row)
+		}
+	}
+	return rows
+}
+
+// applyStack applies the plan of the stack, recording the outcome. It only
+// returns the errors that should end the session, such as failing to read input.
+func (r *stackRun) applyStack(ctx context.Context, allowProtected bool) error {
+	proceed, err := r.app.confirmPolicy(ctx, r.planFile, allowProtected)
+	if err == nil && proceed {
+		proceed, err = r.app.confirmRisk(ctx, r.planFile)
+	}
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		fmt.Printf("%sNot applying %s.%s\n", ui.ColorWarning, r.Name, ui.ColorReset)
+		return nil
+	}
+
+	r.status = stacksui.StatusApplying
+	if err := r.apply.ApplyPlan(ctx, r.planFile); err != nil {
+		r.status, r.err = stacksui.StatusApplyFailed, err
+		displayError(err)
+		return nil
+	}
+	r.status = stacksui.StatusApplied
+	return nil
+}
+
+// finishStacks prints the final status of every stack, records the session in
+// the history and fails if any stack failed to plan or apply, or was skipped.
+func (a *App) finishStacks(runs []*stackRun) error {
+	fmt.Println()
+	stacksui.Write(os.Stdout, stackRows(runs))
+	a.recordStacks(runs)
+
+	for _, run := range runs {
+		if run.status == stacksui.StatusFailed || run.status == stacksui.StatusApplyFailed || run.status == stacksui.StatusSkipped {
+			if !utils.IsTerminal(os.Stdout) && run.err != nil {
+				fmt.Fprintf(os.Stderr, "\n%s%s:%s\n", ui.ColorError, run.Name, ui.ColorReset)
+				displayError(run.err)
+			}
+		}
+	}
+	for _, run := range runs {
+		if run.status == stacksui.StatusFailed || run.status == stacksui.StatusApplyFailed || run.status == stacksui.StatusSkipped {
+			return apperrors.NewExitError(1, nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/stack"
+	"tfapp/internal/testutil"
+	stacksui "tfapp/internal/ui/stacks"
+)
+
+// writeStacks creates a root module with an empty main.tf in each directory,
+// relative to the working directory, and returns their resolved paths.
+func writeStacks(t *testing.T, dirs ...string) []string {
+	t.Helper()
+
+	var resolved []string
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.tf"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		path, err := filepath.Abs(dir)
+		if err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resolved = append(resolved, path)
+	}
+	return resolved
+}
+
+func TestStacksPlansEveryRootModule(t *testing.T) {
+	chdirTemp(t)
+	dirs := writeStacks(t, "envs/dev", "envs/prod", "global/iam")
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	fake.OnIn(dirs[1], "plan", testutil.Response{Stderr: "Error: Invalid provider configuration\n", ExitCode: 1})
+
+	out, err := runCommand(t, "stacks", "-parallelism", "2", ".", "--", "-var=size=large")
+	var exitErr *apperrors.ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Fatalf("stacks returned %v, want exit code 1:\n%s", err, out)
+	}
+
+	for _, want := range []string{"Planning 3 stacks", "envs/dev", "global/iam", "3 stacks: 2 with changes, 1 failed to plan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	planned := make(map[string]bool)
+	dirsByCall := fake.Dirs()
+	for i, call := range fake.Calls() {
+		if len(call) > 1 && call[0] == "plan" && call[1] == "-out" {
+			planned[dirsByCall[i]] = true
+			if !strings.Contains(strings.Join(call, " "), "-var=size=large") {
+				t.Errorf("plan in %s did not get the terraform arguments: %q", dirsByCall[i], call)
+			}
+		}
+	}
+	for _, dir := range dirs {
+		if !planned[dir] {
+			t.Errorf("no plan ran in %s", dir)
+		}
+	}
+}
+
+func TestStacksWithoutRootModules(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+
+	out, err := runCommand(t, "stacks")
+	if err != nil {
+		t.Fatalf("stacks returned error: %v", err)
+	}
+	if !strings.Contains(out, "No root modules found") {
+		t.Errorf("output does not say no stacks were found:\n%s", out)
+	}
+	if fake.CalledWith("plan") {
+		t.Error("terraform plan ran without any stack")
+	}
+}
+
+func TestApplyStacksInDependencyOrder(t *testing.T) {
+	chdirTemp(t)
+	dirs := writeStacks(t, "app", "db", "network", "tools")
+	manifest := "stacks:\n  app:\n    depends_on: [db]\n  db:\n    depends_on: [network]\n"
+	if err := os.WriteFile(config.DefaultStackManifest, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+	fake.OnIn(dirs[1], "apply", testutil.Response{Stderr: "Error: creating database\n", ExitCode: 1})
+
+	app := NewApp(config.DefaultConfig())
+	found, err := stack.Discover(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := app.loadStackGraph(".", found)
+	if err != nil {
+		t.Fatalf("loadStackGraph returned error: %v", err)
+	}
+	runs := make([]*stackRun, len(found))
+	for i, s := range found {
+		runs[i] = app.newStackRun(s)
+	}
+	planStacks(context.Background(), runs, 2, nil, nil)
+
+	testutil.Stdin(t, "yes\n")
+	out := testutil.CaptureStdout(t, func() {
+		err = app.applyStacks(context.Background(), runs, graph, []bool{true, true, true, true}, false)
+	})
+	if err != nil {
+		t.Fatalf("applyStacks returned error: %v", err)
+	}
+
+	for _, want := range []string{"Wave 1:", "Wave 3:", "after network", "Wave 3 of 3", "Skipping app: db failed to apply"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	var applied []string
+	callDirs := fake.Dirs()
+	for i, call := range fake.Calls() {
+		if call[0] == "apply" {
+			applied = append(applied, filepath.Base(callDirs[i]))
+		}
+	}
+	if want := []string{"network", "tools", "db"}; !reflect.DeepEqual(applied, want) {
+		t.Errorf("applied %q, want %q", applied, want)
+	}
+
+	want := []stacksui.Status{stacksui.StatusSkipped, stacksui.StatusApplyFailed, stacksui.StatusApplied, stacksui.StatusApplied}
+	for i, run := range runs {
+		if run.status != want[i] {
+			t.Errorf("%s has status %v, want %v", run.Name, run.status, want[i])
+		}
+	}
+}
+
+func TestApplyStacksSkipsDependentsOfUnappliedStacks(t *testing.T) {
+	chdirTemp(t)
+	dirs := writeStacks(t, "a", "b", "c", "d")
+	manifest := "stacks:\n  a:\n    depends_on: [b]\n  c:\n    depends_on: [d]\n"
+	if err := os.WriteFile(config.DefaultStackManifest, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+	fake.OnIn(dirs[3], "plan", testutil.Response{Stderr: "Error: Invalid provider configuration\n", ExitCode: 1})
+
+	cfg := config.DefaultConfig()
+	cfg.Policy.ProtectedTypes = []string{"aws_db_instance"}
+	cfg.Policy.OnViolation = config.OnViolationRefuse
+	app := NewApp(cfg)
+	found, err := stack.Discover(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, err := app.loadStackGraph(".", found)
+	if err != nil {
+		t.Fatalf("loadStackGraph returned error: %v", err)
+	}
+	runs := make([]*stackRun, len(found))
+	for i, s := range found {
+		runs[i] = app.newStackRun(s)
+	}
+	planStacks(context.Background(), runs, 2, nil, nil)
+
+	// d failed to plan and cannot be selected; the policy refuses to apply b
+	testutil.Stdin(t, "yes\n")
+	out := testutil.CaptureStdout(t, func() {
+		err = app.applyStacks(context.Background(), runs, graph, []bool{true, true, true, false}, false)
+	})
+	if err != nil {
+		t.Fatalf("applyStacks returned error: %v", err)
+	}
+
+	for _, want := range []string{"Skipping a: b was not applied", "Skipping c: d failed to plan"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	for _, call := range fake.Calls() {
+		if call[0] == "apply" {
+			t.Errorf("a stack was applied: %q", call)
+		}
+	}
+	want := []stacksui.Status{stacksui.StatusSkipped, stacksui.StatusChanges, stacksui.StatusSkipped, stacksui.StatusFailed}
+	for i, run := range runs {
+		if run.status != want[i] {
+			t.Errorf("%s has status %v, want %v", run.Name, run.status, want[i])
+		}
+	}
+}
+
+func TestStacksRejectsManifestCycle(t *testing.T) {
+	chdirTemp(t)
+	writeStacks(t, "a", "b")
+	manifest := "stacks:\n  a:\n    depends_on: [b]\n  b:\n    depends_on: [a]\n"
+	if err := os.WriteFile(config.DefaultStackManifest, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := testutil.InstallFakeTerraform(t)
+
+	_, err := runCommand(t, "stacks")
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("stacks returned %v, want a cycle error", err)
+	}
+	if fake.CalledWith("plan") {
+		t.Error("terraform plan ran despite the invalid manifest")
+	}
+}
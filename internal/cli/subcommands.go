@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/ui"
+)
+
+// subcommand is a tfapp command with its own flags and help.
+type subcommand struct {
+	Name    string
+	Usage   string // Arguments accepted after the command name
+	Summary string
+	Run     func(a *App, ctx context.Context, fs *flag.FlagSet, args []string) error // fs is the command's empty flag set
+
+	WithoutTerraform bool // The command can run when terraform is not installed
+}
+
+// subcommands lists the commands available as the first tfapp argument.
+var subcommands = []subcommand{
+	{
+		Name:    "plan",
+		Usage:   "[-save NAME] [-init | -init-upgrade] [-workspace NAME] [-- terraform-arguments]",
+		Summary: "Create a plan and print its summary, optionally saving it",
+		Run:     (*App).runPlanCommand,
+	},
+	{
+		Name:    "apply",
+		Usage:   "[-allow-protected] NAME | DIR | PLAN-FILE",
+		Summary: "Apply a saved plan if the state has not changed since it was made",
+		Run:     (*App).runApplyCommand,
+	},
+	{
+		Name:             "show",
+		Usage:            "[-summary] [NAME | DIR]",
+		Summary:          "List saved plans, or open a saved plan in the plan viewer",
+		Run:              (*App).runShowCommand,
+		WithoutTerraform: true,
+	},
+	{
+		Name:    "destroy",
+		Usage:   "[-init | -init-upgrade] [-workspace NAME] [-allow-protected] [-- terraform-arguments]",
+		Summary: "Plan the destruction of all resources and apply it after confirmation",
+		Run:     (*App).runDestroyCommand,
+	},
+	{
+		Name:    "stacks",
+		Usage:   "[-parallelism N] [-allow-protected] [DIR] [-- terraform-arguments]",
+		Summary: "Plan every root module under a directory and apply a selection of them",
+		Run:     (*App).runStacksCommand,
+	},
+	{
+		Name:    "state",
+		Usage:   "SUBCOMMAND [terraform-arguments]",
+		Summary: "Run a terraform state command, confirming before the state is modified",
+		Run:     (*App).runStateCommand,
+	},
+	{
+		Name:    "import",
+		Usage:   "[terraform-arguments] ADDRESS ID",
+		Summary: "Import an existing object into the state with terraform import",
+		Run:     (*App).runImportCommand,
+	},
+	{
+		Name:    "validate",
+		Usage:   "[terraform-arguments]",
+		Summary: "Check the configuration with terraform validate",
+		Run:     (*App).runValidateCommand,
+	},
+	{
+		Name:    "workspace",
+		Usage:   "[list | select NAME | new NAME | delete NAME | show]",
+		Summary: "Select, create or delete a workspace from a menu, or run a terraform workspace command",
+		Run:     (*App).runWorkspaceCommand,
+	},
+	{
+		Name:             "doctor",
+		Usage:            "",
+		Summary:          "Check the terraform installation, working directory and tfapp configuration",
+		Run:              (*App).runDoctorCommand,
+		WithoutTerraform: true,
+	},
+	{
+		Name:             "history",
+		Usage:            "[-command COMMAND] [-outcome OUTCOME] [-workspace NAME] [-here] [-since TIME] [-search TEXT] [-n N] [-json] [show ID]",
+		Summary:          "List past runs, or show one of them with its plan summary",
+		Run:              (*App).runHistoryCommand,
+		WithoutTerraform: true,
+	},
+	{
+		Name:             "config",
+		Usage:            "show | path",
+		Summary:          "Print the tfapp configuration or the path of its file",
+		Run:              (*App).runConfigCommand,
+		WithoutTerraform: true,
+	},
+}
+
+// findSubcommand returns the subcommand with the given name, or nil.
+func findSubcommand(name string) *subcommand {
+	for i := range subcommands {
+		if subcommands[i].Name == name {
+			return &subcommands[i]
+		}
+	}
+	return nil
+}
+
+// runSubcommand runs the named subcommand with its arguments.
+func (a *App) runSubcommand(ctx context.Context, name string, args []string) error {
+	cmd := findSubcommand(name)
+	if cmd == nil {
+		return apperrors.NewValidationError("command", fmt.Sprintf("unknown command %q", name), apperrors.ErrInvalidInput)
+	}
+
+	err := cmd.Run(a, ctx, newFlagSet(cmd), args)
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+	return err
+}
+
+// newFlagSet creates the flag set of a subcommand, with help showing its usage and flags.
+func newFlagSet(cmd *subcommand) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {
+		fmt.Printf("%s%stfapp %s%s - %s\n\n", ui.ColorInfo, ui.TextBold, cmd.Name, ui.ColorReset, cmd.Summary)
+		fmt.Println("USAGE:")
+		fmt.Printf("  tfapp %s %s\n", cmd.Name, cmd.Usage)
+
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Println("\nFLAGS:")
+			fs.SetOutput(os.Stdout)
+			fs.PrintDefaults()
+			fs.SetOutput(io.Discard)
+		}
+		fmt.Println()
+	}
+	return fs
+}
+
+// parseSubcommandFlags parses the arguments of a subcommand.
+// It returns flag.ErrHelp after printing the usage when help was requested.
+func parseSubcommandFlags(fs *flag.FlagSet, args []string) error {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fs.Usage()
+		return err
+	}
+	if err != nil {
+		return apperrors.NewValidationError(fs.Name(), err.Error(), apperrors.ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/checkbox"
+	"tfapp/internal/utils"
+)
+
+// handleTargetApply processes targeted resource application.
+// Changed resources are offered as a module → resource → instance hierarchy,
+// and the selection is reduced to the coarsest equivalent set of -target flags.
+func (a *App) handleTargetApply(ctx context.Context, planFile string, flags *Flags) error {
+	selection, err := a.selectTargets(ctx, planFile, "Select resources to apply", false)
+	if err != nil || selection == nil {
+		return err
+	}
+	selected := selection.selected
+
+	if len(selected) == 0 {
+		utils.ClearTerminal()
+		fmt.Printf("%sNo resources selected for targeted apply.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	utils.ClearTerminal()
+
+	description := fmt.Sprintf("%d selected resources", len(selected))
+	return a.planTargets(ctx, description, terraform.CoarseTargets(selection.tree, selected), selection.deps.Implied(selected), flags)
+}
+
+// planTargets plans again with a -target flag for each of the given addresses
+// and shows the menu for the new plan. implied lists the dependencies terraform
+// will include in the plan.
+func (a *App) planTargets(ctx context.Context, description string, targets, implied []string, flags *Flags) error {
+	targetFlags := make([]string, 0, len(targets))
+	for _, target := range targets {
+		targetFlags = append(targetFlags, "-target="+target)
+	}
+
+	fmt.Printf("%sTargeting %s: %s%s\n",
+		ui.ColorInfo, description, utils.ShellJoin(targetFlags), ui.ColorReset)
+	if len(implied) > 0 {
+		fmt.Printf("%sTerraform will also include %d dependencies: %s%s\n",
+			ui.ColorInfo, len(implied), strings.Join(implied, ", "), ui.ColorReset)
+	}
+
+	return a.replan(ctx, targetFlags, flags)
+}
+
+// handleExcludeApply applies everything except the resources the user deselects.
+// It passes -exclude when terraform supports it, and otherwise targets the
+// complement of the excluded resources with -target.
+func (a *App) handleExcludeApply(ctx context.Context, planFile string, flags *Flags) error {
+	selection, err := a.selectTargets(ctx, planFile, "Deselect resources to exclude from the apply", true)
+	if err != nil || selection == nil {
+		return err
+	}
+	tree, selected := selection.tree, selection.selected
+
+	excluded := make(map[string]bool)
+	for _, option := range terraform.FlattenTargetTree(tree) {
+		if option.Node.IsLeaf() && !selected[option.Node.Address] {
+			excluded[option.Node.Address] = true
+		}
+	}
+
+	if len(excluded) == 0 {
+		utils.ClearTerminal()
+		fmt.Printf("%sNo resources excluded. Use \"Apply Plan\" to apply the whole plan.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+	if len(selected) == 0 {
+		utils.ClearTerminal()
+		fmt.Printf("%sAll resources excluded, nothing to apply.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	utils.ClearTerminal()
+
+	var planFlags []string
+	if terraform.Supports(ctx, a.tfExecutor, terraform.FeatureExclude) {
+		for _, address := range terraform.CoarseTargets(tree, excluded) {
+			planFlags = append(planFlags, "-exclude="+address)
+		}
+		fmt.Printf("%sExcluding %d resources: %s%s\n",
+			ui.ColorInfo, len(excluded), utils.ShellJoin(planFlags), ui.ColorReset)
+	} else {
+		for _, target := range terraform.CoarseTargets(tree, selected) {
+			planFlags = append(planFlags, "-target="+target)
+		}
+		fmt.Printf("%sThis version has no -exclude flag; targeting the %d remaining resources instead: %s%s\n",
+			ui.ColorInfo, len(selected), utils.ShellJoin(planFlags), ui.ColorReset)
+		if implied := selection.deps.Implied(selected); len(implied) > 0 {
+			fmt.Printf("%sWarning: these excluded resources are dependencies and will still be planned: %s%s\n",
+				ui.ColorWarning, strings.Join(implied, ", "), ui.ColorReset)
+		}
+	}
+
+	return a.replan(ctx, planFlags, flags)
+}
+
+// targetSelection is the outcome of the resource selection menu.
+type targetSelection struct {
+	tree     []*terraform.TargetNode
+	deps     *terraform.Dependencies
+	selected map[string]bool // Selected leaf addresses
+}
+
+// selectTargets shows the changed resources of a plan as a checkbox hierarchy,
+// marking the dependencies terraform will include for each selection.
+// With checked set, every resource starts selected. A nil selection means there
+// was nothing to select and the user has already been told.
+func (a *App) selectTargets(ctx context.Context, planFile, title string, checked bool) (*targetSelection, error) {
+	plan, err := a.tfPlan.LoadPlan(ctx, planFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := terraform.BuildTargetTree(plan)
+	deps := terraform.BuildDependencies(plan)
+	targetOptions := terraform.FlattenTargetTree(tree)
+
+	// If no targetable resources, inform the user
+	if len(targetOptions) == 0 {
+		utils.ClearTerminal()
+		fmt.Printf("%sNo resources available for targeted apply. Drifted and moved resources are excluded from targeting.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil, nil
+	}
+
+	leaves := make(map[string]bool)
+	for _, option := range targetOptions {
+		if option.Node.IsLeaf() {
+			leaves[option.Node.Address] = true
+		}
+	}
+
+	// Convert the target tree to checkbox options
+	checkboxOptions := make([]checkbox.Option, 0, len(targetOptions))
+	for _, option := range targetOptions {
+		var requires []string
+		if option.Node.IsLeaf() {
+			for _, dependency := range deps.DependenciesOf(option.Node.Address) {
+				if leaves[dependency] {
+					requires = append(requires, dependency)
+				}
+			}
+		}
+		checkboxOptions = append(checkboxOptions, checkbox.Option{
+			Name:        option.Node.Address,
+			Description: targetDescription(option.Node),
+			Checked:     checked,
+			Depth:       option.Depth,
+			Requires:    requires,
+		})
+	}
+
+	// Show checkbox menu
+	selectedOptions, err := checkbox.ShowWithTitle(title, checkboxOptions)
+	if err != nil {
+		return nil, apperrors.NewUserInteractionError("resource selection", "Failed to show resource selection menu", err)
+	}
+	if selectedOptions == nil && checked {
+		// The user quit the menu; treat it as deselecting nothing rather than everything
+		utils.ClearTerminal()
+		fmt.Printf("%sResource selection cancelled.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, opt := range selectedOptions {
+		if leaves[opt.Name] {
+			selected[opt.Name] = true
+		}
+	}
+
+	return &targetSelection{tree: tree, deps: deps, selected: selected}, nil
+}
+
+// replan creates a new plan with the given -target or -exclude flags,
+// replacing any from the command line, and shows the menu for it.
+func (a *App) replan(ctx context.Context, planFlags []string, flags *Flags) error {
+	// Filter out any existing -target and -exclude flags
+	filteredFlags := make([]string, 0)
+	for _, flag := range flags.AdditionalFlags {
+		if !strings.HasPrefix(flag, "-target=") && !strings.HasPrefix(flag, "-exclude=") {
+			filteredFlags = append(filteredFlags, flag)
+		}
+	}
+	flags.AdditionalFlags = append(filteredFlags, planFlags...)
+
+	tmpPlanFile, err := createTempPlanFile()
+	if err != nil {
+		return fmt.Errorf("Failed to create temporary plan file: %w", err)
+	}
+	defer removeTempPlanFile(tmpPlanFile)
+
+	// Generate the plan
+	new_resources, err := a.tfPlan.CreatePlan(ctx, tmpPlanFile, flags.AdditionalFlags, true)
+	if apperrors.IsErrNoChanges(err) {
+		a.recordNoChanges()
+		reportNoChanges()
+		return nil
+	}
+	if err != nil {
+		return planFailed(err)
+	}
+	a.recordPlan(ctx, tmpPlanFile, flags.AdditionalFlags)
+
+	// Show the menu for the user to choose an action
+	return a.handleMenuSelection(ctx, tmpPlanFile, new_resources, flags)
+}
+
+// targetDescription describes a target tree node in the selection menu:
+// the action for a single change, or the number of changes for a group.
+func targetDescription(node *terraform.TargetNode) string {
+	if node.IsLeaf() {
+		return node.Action
+	}
+	count := node.TargetableLeaves()
+	if count == 1 {
+		return fmt.Sprintf("%s, 1 change", node.Kind)
+	}
+	return fmt.Sprintf("%s, %d changes", node.Kind, count)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/diagnostics"
+	"tfapp/internal/uijson"
+	"tfapp/internal/utils"
+)
+
+// validate runs `terraform validate -json` and renders the diagnostics with
+// their source. An invalid configuration exits with code 1, as terraform does.
+func (a *App) validate(ctx context.Context) error {
+	output, err := a.tfExecutor.Output(ctx, []string{"validate", "-json"})
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return fmt.Errorf("error executing terraform validate: %w", err)
+	}
+
+	result, parseErr := uijson.ParseValidate(output)
+	if parseErr != nil {
+		if exitErr != nil {
+			// Not the JSON report, terraform has already explained on stderr
+			return apperrors.NewExitError(exitErr.ExitCode(), nil)
+		}
+		return parseErr
+	}
+
+	if result.Valid {
+		fmt.Printf("%s✓ The configuration is valid.%s\n", ui.ColorSuccess, ui.ColorReset)
+		if len(result.Diagnostics) > 0 {
+			fmt.Println()
+			diagnostics.Write(os.Stdout, result.Diagnostics)
+		}
+		return nil
+	}
+
+	if err := showDiagnostics("Validation failed", result.Diagnostics); err != nil {
+		return err
+	}
+	fmt.Printf("\n%s✗ The configuration is invalid: %d errors, %d warnings.%s\n",
+		ui.ColorError, result.ErrorCount, result.WarningCount, ui.ColorReset)
+	return apperrors.NewExitError(1, nil)
+}
+
+// planFailed reports a failed plan. Error diagnostics too many to read at once
+// are browsed in a list when running in a terminal; other failures are returned
+// for the caller to display.
+func planFailed(err error) error {
+	var diagErr *terraform.DiagnosticsError
+	if !errors.As(err, &diagErr) || len(diagErr.Diagnostics) <= diagnostics.BrowseThreshold || !utils.IsTerminal(os.Stdout) {
+		return fmt.Errorf("Planning failed: %w", err)
+	}
+
+	if err := showDiagnostics("Planning failed", diagErr.Diagnostics); err != nil {
+		return err
+	}
+	return apperrors.NewExitError(1, nil)
+}
+
+// showDiagnostics prints diagnostics, or lets the user browse them when there
+// are many and stdout is a terminal, leaving a one-line summary of each.
+func showDiagnostics(title string, diags []uijson.Diagnostic) error {
+	if len(diags) <= diagnostics.BrowseThreshold || !utils.IsTerminal(os.Stdout) {
+		diagnostics.Write(os.Stdout, diags)
+		return nil
+	}
+
+	if err := diagnostics.Browse(title, diags); err != nil {
+		return err
+	}
+	fmt.Printf("%s%s:%s\n", ui.ColorError, title, ui.ColorReset)
+	for _, d := range diags {
+		fmt.Println("  " + diagnostics.Summary(d))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/terraform"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/menu"
+	"tfapp/internal/utils"
+)
+
+// Workspace picker actions, offered after the workspaces themselves.
+// Workspace names cannot contain spaces, so they never clash with these.
+const (
+	newWorkspaceOption    = "New workspace..."
+	deleteWorkspaceOption = "Delete a workspace..."
+)
+
+// runWorkspaceCommand shows the workspace picker, or runs a terraform workspace
+// subcommand, asking for confirmation before deleting a workspace.
+func (a *App) runWorkspaceCommand(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if len(args) > 0 && isHelpArg(args[0]) {
+		fs.Usage()
+		fmt.Println("Without a subcommand, the workspaces are offered in a menu to select, create or delete one.")
+		return nil
+	}
+	if len(args) == 0 {
+		if !utils.IsTerminal(os.Stdout) {
+			return a.passThrough(ctx, []string{"workspace", "list"})
+		}
+		return a.pickWorkspace(ctx)
+	}
+
+	terraformArgs := append([]string{"workspace"}, args...)
+	if args[0] == "delete" {
+		fmt.Printf("%sThis will delete a workspace: terraform %s%s\n",
+			ui.ColorWarning, utils.ShellJoin(terraformArgs), ui.ColorReset)
+		confirmed, err := confirm("Proceed? [yes/No]: ")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Printf("%sWorkspace deletion aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+			return nil
+		}
+	}
+	return a.passThrough(ctx, terraformArgs)
+}
+
+// pickWorkspace offers the workspaces in a menu, marking the selected one,
+// along with actions to create and delete workspaces.
+func (a *App) pickWorkspace(ctx context.Context) error {
+	workspaces, current, err := terraform.Workspaces(ctx, a.tfExecutor)
+	if err != nil {
+		return err
+	}
+
+	options := make([]menu.Option, 0, len(workspaces)+2)
+	for _, workspace := range workspaces {
+		option := menu.Option{Name: workspace}
+		if workspace == current {
+			option.Description = "selected"
+		}
+		options = append(options, option)
+	}
+	options = append(options,
+		menu.Option{Name: newWorkspaceOption, Description: "Create a workspace and select it"},
+		menu.Option{Name: deleteWorkspaceOption, Description: "Delete a workspace other than the selected one"},
+	)
+
+	choice, err := menu.Select("Select Workspace", options)
+	if err != nil {
+		return apperrors.NewUserInteractionError("workspace selection", "Failed to show workspace menu", err)
+	}
+	menu.ClearSelectOutput(options)
+
+	switch choice {
+	case "":
+		return nil
+	case current:
+		fmt.Printf("%sWorkspace %s is already selected.%s\n", ui.ColorInfo, current, ui.ColorReset)
+		return nil
+	case newWorkspaceOption:
+		name, err := readLine("Name of the new workspace: ")
+		if err != nil || name == "" {
+			return err
+		}
+		if err := terraform.NewWorkspace(ctx, a.tfExecutor, name); err != nil {
+			return err
+		}
+		fmt.Printf("%s%sCreated and selected workspace %s.%s\n", ui.ColorSuccess, ui.TextBold, name, ui.ColorReset)
+		return nil
+	case deleteWorkspaceOption:
+		return a.pickWorkspaceToDelete(ctx, workspaces, current)
+	default:
+		if err := terraform.SelectWorkspace(ctx, a.tfExecutor, choice); err != nil {
+			return err
+		}
+		fmt.Printf("%s%sSelected workspace %s.%s\n", ui.ColorSuccess, ui.TextBold, choice, ui.ColorReset)
+		return nil
+	}
+}
+
+// pickWorkspaceToDelete offers the workspaces other than the selected one and
+// deletes the chosen workspace once the user has typed its name to confirm.
+func (a *App) pickWorkspaceToDelete(ctx context.Context, workspaces []string, current string) error {
+	var options []menu.Option
+	for _, workspace := range workspaces {
+		// Terraform can delete neither the selected workspace nor the default one
+		if workspace != current && workspace != terraform.DefaultWorkspace {
+			options = append(options, menu.Option{Name: workspace})
+		}
+	}
+	if len(options) == 0 {
+		fmt.Printf("%sNo workspace can be deleted: the selected and default workspaces cannot be.%s\n", ui.ColorInfo, ui.ColorReset)
+		return nil
+	}
+
+	name, err := menu.Select("Select Workspace to Delete", options)
+	if err != nil {
+		return apperrors.NewUserInteractionError("workspace selection", "Failed to show workspace menu", err)
+	}
+	menu.ClearSelectOutput(options)
+	if name == "" {
+		return nil
+	}
+
+	fmt.Printf("%s%sThis will delete workspace %s. Terraform refuses if its state still tracks resources.%s\n",
+		ui.ColorWarning, ui.TextBold, name, ui.ColorReset)
+	response, err := readLine(fmt.Sprintf("Type %s%s%s to confirm: ", ui.TextBold, name, ui.ColorReset))
+	if err != nil {
+		return err
+	}
+	if response != name {
+		fmt.Printf("%sWorkspace deletion aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+		return nil
+	}
+
+	if err := terraform.DeleteWorkspace(ctx, a.tfExecutor, name); err != nil {
+		return err
+	}
+	fmt.Printf("%s%sDeleted workspace %s.%s\n", ui.ColorSuccess, ui.TextBold, name, ui.ColorReset)
+	return nil
+}
+
+// useWorkspace runs the terraform commands of the rest of the run in the named
+// workspace, by setting TF_WORKSPACE for them: the workspace selected in the
+// working directory is left alone, even if tfapp is interrupted. With an
+// empty name, the selected workspace is kept. The plan summary shows the
+// workspace in use.
+func (a *App) useWorkspace(ctx context.Context, name string) error {
+	if name == "" {
+		return nil
+	}
+
+	workspaces, _, err := terraform.Workspaces(ctx, a.tfExecutor)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(workspaces, name) {
+		return apperrors.NewValidationError("workspace", fmt.Sprintf("workspace %q does not exist", name), apperrors.ErrInvalidInput)
+	}
+
+	executor, ok := a.tfExecutor.(*terraform.CommandExecutor)
+	if !ok {
+		return fmt.Errorf("cannot run in workspace %q with this executor", name)
+	}
+	executor.SetEnv(terraform.WorkspaceEnv, name)
+	if a.entry != nil {
+		a.entry.Workspace = name
+	}
+	return nil
+}
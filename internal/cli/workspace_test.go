@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+	"tfapp/internal/testutil"
+)
+
+// workspaceCalls returns the terraform workspace commands the fake has run.
+func workspaceCalls(fake *testutil.FakeTerraform) [][]string {
+	var calls [][]string
+	for _, call := range fake.Calls() {
+		if len(call) > 0 && call[0] == "workspace" {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func TestWorkspaceFlagSetsTFWorkspace(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("workspace list", testutil.Response{Stdout: "* default\n  staging\n"})
+	fake.OnShowPlan("create")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = NewApp(config.DefaultConfig()).Run(context.Background(), &Flags{CI: true, Output: OutputText, Workspace: "staging"})
+	})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	// The selected workspace is left alone, so nothing needs restoring
+	want := [][]string{{"workspace", "list"}}
+	if got := workspaceCalls(fake); !reflect.DeepEqual(got, want) {
+		t.Errorf("workspace calls = %q, want %q", got, want)
+	}
+
+	planned := false
+	workspaces := fake.Workspaces()
+	for i, call := range fake.Calls() {
+		if call[0] == "plan" || call[0] == "show" {
+			planned = true
+			if workspaces[i] != "staging" {
+				t.Errorf("%q ran with TF_WORKSPACE=%q, want staging", call, workspaces[i])
+			}
+		}
+	}
+	if !planned {
+		t.Errorf("terraform plan was not run: %q", fake.Calls())
+	}
+	if !strings.Contains(out, "Workspace:") || !strings.Contains(out, "staging") {
+		t.Errorf("summary does not show the workspace:\n%s", out)
+	}
+}
+
+func TestWorkspaceFlagOverridesTFWorkspace(t *testing.T) {
+	chdirTemp(t)
+	t.Setenv("TF_WORKSPACE", "prod")
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("workspace list", testutil.Response{Stdout: "  default\n* prod\n  staging\n"})
+	fake.OnShowPlan("create")
+
+	if _, err := runCommand(t, "plan", "-workspace", "staging"); err != nil {
+		t.Fatalf("plan returned error: %v", err)
+	}
+	workspaces := fake.Workspaces()
+	for i, call := range fake.Calls() {
+		if call[0] == "plan" && workspaces[i] != "staging" {
+			t.Errorf("%q ran with TF_WORKSPACE=%q, want staging", call, workspaces[i])
+		}
+	}
+}
+
+func TestWorkspaceFlagUnknownWorkspace(t *testing.T) {
+	chdirTemp(t)
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("workspace list", testutil.Response{Stdout: "* default\n"})
+
+	_, err := runCommand(t, "plan", "-workspace", "nope")
+	if err == nil || !strings.Contains(err.Error(), `workspace "nope"`) {
+		t.Fatalf("plan error = %v, want a workspace error", err)
+	}
+	if fake.CalledWith("plan", "-out") {
+		t.Error("terraform plan ran without the workspace")
+	}
+}
+
+func TestWorkspaceDeleteRequiresConfirmation(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+
+	testutil.Stdin(t, "no\n")
+	if _, err := runCommand(t, "workspace", "delete", "staging"); err != nil {
+		t.Fatalf("workspace delete returned error: %v", err)
+	}
+	if fake.CalledWith("workspace", "delete") {
+		t.Error("terraform workspace delete ran without confirmation")
+	}
+
+	testutil.Stdin(t, "yes\n")
+	if _, err := runCommand(t, "workspace", "delete", "staging"); err != nil {
+		t.Fatalf("workspace delete returned error: %v", err)
+	}
+	if !fake.CalledWith("workspace", "delete", "staging") {
+		t.Error("terraform workspace delete was not called after confirmation")
+	}
+}
+
+func TestWorkspaceWithoutTerminalLists(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+
+	if _, err := runCommand(t, "workspace"); err != nil {
+		t.Fatalf("workspace returned error: %v", err)
+	}
+	if !fake.CalledWith("workspace", "list") {
+		t.Errorf("terraform workspace list was not called: %q", fake.Calls())
+	}
+}
@@ -12,8 +12,38 @@ import (
 
 // Config represents the application configuration.
 type Config struct {
-	Colors ColorConfig `yaml:"colors"`
-	UI     UIConfig    `yaml:"ui"`
+	Terraform TerraformConfig `yaml:"terraform"`
+	Colors    ColorConfig     `yaml:"colors"`
+	UI        UIConfig        `yaml:"ui"`
+	Policy    PolicyConfig    `yaml:"policy"`
+	Cost      CostConfig      `yaml:"cost"`
+	Risk      RiskConfig      `yaml:"risk"`
+	History   HistoryConfig   `yaml:"history"`
+	Stacks    StacksConfig    `yaml:"stacks"`
+}
+
+// TerraformConfig holds the settings of the binary tfapp runs.
+type TerraformConfig struct {
+	// Binary to run: a path, or a name looked up in PATH such as "tofu" for OpenTofu
+	// (default: terraform, or tofu when terraform is not installed).
+	// The TFAPP_BINARY environment variable takes precedence.
+	Binary string `yaml:"binary"`
+
+	// Variable files passed to every plan as -var-file flags, e.g. "common.tfvars".
+	// Relative paths in a project configuration file are relative to its directory.
+	VarFiles []string `yaml:"var_files"`
+
+	// Arguments added to every plan, before those given on the command line
+	Args []string `yaml:"args"`
+}
+
+// PlanArgs returns the arguments added to every plan: the variable files, then the arguments.
+func (c *TerraformConfig) PlanArgs() []string {
+	var args []string
+	for _, file := range c.VarFiles {
+		args = append(args, "-var-file="+file)
+	}
+	return append(args, c.Args...)
 }
 
 // UIConfig holds the UI configuration values.
@@ -27,6 +57,104 @@ type UIConfig struct {
 	CursorChar string `yaml:"cursor_char"`
 }
 
+// CostConfig holds the settings of the cost estimate.
+type CostConfig struct {
+	// Pricing catalog file (default: pricing.yaml next to this file).
+	// Costs are only estimated when the catalog exists.
+	Catalog string `yaml:"catalog"`
+
+	// Hours used to turn hourly prices into monthly ones (default: 730)
+	HoursPerMonth float64 `yaml:"hours_per_month"`
+}
+
+// RiskConfig holds the weights used to score how dangerous each change is.
+type RiskConfig struct {
+	// Points added to a change's score per risk factor: replace, delete, update,
+	// stateful (deleting or replacing a stateful type), cannot_update
+	// (replace_because_cannot_update) and replace_path (per attribute forcing
+	// the replacement). Factors left out keep their default weight.
+	Weights map[string]int `yaml:"weights"`
+
+	// Resource types holding data that a delete or replace would lose (default: common databases, buckets, disks and keys)
+	StatefulTypes []string `yaml:"stateful_types"`
+
+	// Score from which a change is listed as high risk (default: 50)
+	HighRisk int `yaml:"high_risk"`
+
+	// Score from which applying needs an extra typed confirmation (default: 80, negative disables)
+	ConfirmAbove int `yaml:"confirm_above"`
+}
+
+// HistoryConfig holds the settings of the run history.
+type HistoryConfig struct {
+	// Stop recording runs in the history (default: false)
+	Disabled bool `yaml:"disabled"`
+
+	// History file (default: history.jsonl next to this file)
+	Path string `yaml:"path"`
+}
+
+// StacksConfig holds the settings of 'tfapp stacks', which plans many root modules at once.
+type StacksConfig struct {
+	// Number of stacks planned at the same time (default: 4)
+	Parallelism int `yaml:"parallelism"`
+
+	// Manifest declaring the dependencies between stacks, relative to the directory
+	// of the stacks (default: tfapp-stacks.yaml). Stacks are applied in dependency
+	// order when the manifest exists.
+	Manifest string `yaml:"manifest"`
+}
+
+// DefaultParallelism is the number of stacks planned at the same time when not configured.
+const DefaultParallelism = 4
+
+// DefaultStackManifest is the name of the stack manifest when not configured.
+const DefaultStackManifest = "tfapp-stacks.yaml"
+
+// PolicyConfig holds the resources that must not be destroyed or replaced.
+type PolicyConfig struct {
+	// Address globs of protected resources, e.g. "aws_db_instance.*" or "module.prod.*".
+	// "*" matches any characters, including dots.
+	Protected []string `yaml:"protected"`
+
+	// Resource types that are protected wherever they appear, e.g. "aws_db_instance"
+	ProtectedTypes []string `yaml:"protected_types"`
+
+	// What "Apply Plan" does when a protected resource would be destroyed or replaced:
+	// "confirm" (default) asks for a second typed confirmation, "refuse" does not apply
+	OnViolation string `yaml:"on_violation"`
+
+	// Rules checked against every resource the plan creates or updates
+	Rules []RuleConfig `yaml:"rules"`
+}
+
+// RuleConfig is a policy rule written in the policy expression language.
+type RuleConfig struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// "error" (default) gates the apply like a protected resource, "warning" is only reported
+	Severity string `yaml:"severity"`
+
+	// Optional expression selecting the resources the rule applies to, e.g. type == "aws_security_group"
+	When string `yaml:"when"`
+
+	// Expression that must be true for every selected resource, e.g. after.tags.owner != null
+	Condition string `yaml:"condition"`
+}
+
+// Values of RuleConfig.Severity.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Values of PolicyConfig.OnViolation.
+const (
+	OnViolationConfirm = "confirm"
+	OnViolationRefuse  = "refuse"
+)
+
 // ColorConfig holds the color configuration values.
 type ColorConfig struct {
 	Info      string `yaml:"info"`      // Informational messages (cyan/blue)
@@ -40,6 +168,11 @@ type ColorConfig struct {
 // DefaultConfig returns the default configuration.
 func DefaultConfig() *Config {
 	return &Config{
+		Terraform: TerraformConfig{
+			Binary:   "",
+			VarFiles: []string{},
+			Args:     []string{},
+		},
 		Colors: ColorConfig{
 			Info:      "#3366cc", // Cyan/Blue (was #36c)
 			Success:   "#22aa22", // Green (was #2a2)
@@ -52,6 +185,55 @@ func DefaultConfig() *Config {
 			SpinnerType: "MiniDot", // Default spinner type
 			CursorChar:  ">",       // Default cursor character
 		},
+		Policy: PolicyConfig{
+			Protected:      []string{},
+			ProtectedTypes: []string{},
+			OnViolation:    OnViolationConfirm,
+			Rules:          []RuleConfig{},
+		},
+		Cost: CostConfig{
+			Catalog:       "",
+			HoursPerMonth: 730,
+		},
+		Risk: RiskConfig{
+			Weights: map[string]int{
+				"replace":       40,
+				"delete":        30,
+				"update":        5,
+				"stateful":      40,
+				"cannot_update": 20,
+				"replace_path":  10,
+			},
+			StatefulTypes: []string{
+				"aws_db_instance",
+				"aws_rds_cluster",
+				"aws_dynamodb_table",
+				"aws_s3_bucket",
+				"aws_ebs_volume",
+				"aws_efs_file_system",
+				"aws_elasticache_cluster",
+				"aws_elasticache_replication_group",
+				"aws_opensearch_domain",
+				"aws_kms_key",
+				"google_sql_database_instance",
+				"google_storage_bucket",
+				"google_compute_disk",
+				"azurerm_storage_account",
+				"azurerm_mssql_database",
+				"azurerm_postgresql_flexible_server",
+				"azurerm_managed_disk",
+			},
+			HighRisk:     50,
+			ConfirmAbove: 80,
+		},
+		History: HistoryConfig{
+			Disabled: false,
+			Path:     "",
+		},
+		Stacks: StacksConfig{
+			Parallelism: DefaultParallelism,
+			Manifest:    "",
+		},
 	}
 }
 
@@ -66,13 +248,78 @@ func ConfigFilePath() (string, error) {
 	return filepath.Join(configDir, "config.yaml"), nil
 }
 
-// LoadConfig loads the configuration from the config file.
-// If the file doesn't exist, it creates a default configuration.
+// CatalogPath returns the path of the pricing catalog, and whether it was
+// configured explicitly rather than being the default.
+func (c *Config) CatalogPath() (string, bool, error) {
+	if c.Cost.Catalog != "" {
+		path, err := expandHome(c.Cost.Catalog)
+		return path, true, err
+	}
+
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", false, err
+	}
+	return filepath.Join(filepath.Dir(configPath), "pricing.yaml"), false, nil
+}
+
+// HistoryPath returns the path of the history file.
+func (c *Config) HistoryPath() (string, error) {
+	if c.History.Path != "" {
+		return expandHome(c.History.Path)
+	}
+
+	configPath, err := ConfigFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), "history.jsonl"), nil
+}
+
+// StackManifestPath returns the path of the manifest of the stacks under dir,
+// and whether it was configured explicitly rather than being the default.
+func (c *Config) StackManifestPath(dir string) (string, bool, error) {
+	if c.Stacks.Manifest == "" {
+		return filepath.Join(dir, DefaultStackManifest), false, nil
+	}
+
+	path, err := expandHome(c.Stacks.Manifest)
+	if err != nil {
+		return "", true, err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	return path, true, nil
+}
+
+// expandHome replaces a leading ~/ in path with the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, path[2:]), nil
+}
+
+// LoadConfig loads the configuration: the defaults, overridden by the user
+// configuration file, then by the project configuration file found from the
+// working directory, then by the environment.
+// If the user file doesn't exist, it creates a default configuration.
 // Returns the config, a flag indicating if the config was created, and any error.
 func LoadConfig() (*Config, bool, error) {
+	config, _, created, err := LoadConfigWithSources()
+	return config, created, err
+}
+
+// LoadConfigWithSources is LoadConfig, also returning the source of each value.
+func LoadConfigWithSources() (*Config, Sources, bool, error) {
 	filename, err := ConfigFilePath()
 	if err != nil {
-		return nil, false, err
+		return nil, nil, false, err
 	}
 
 	// Check if the file exists
@@ -80,24 +327,21 @@ func LoadConfig() (*Config, bool, error) {
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		// File doesn't exist, create the default one
 		if err := createDefaultConfig(filename); err != nil {
-			return nil, false, fmt.Errorf("failed to create default config: %w", err)
+			return nil, nil, false, fmt.Errorf("failed to create default config: %w", err)
 		}
 		configCreated = true
 	}
 
-	// Read the config file
-	data, err := os.ReadFile(filename)
+	projectFile, err := FindProjectConfig(".")
 	if err != nil {
-		return nil, configCreated, fmt.Errorf("failed to read config file: %w", err)
+		return nil, nil, configCreated, err
 	}
 
-	// Parse the YAML
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, configCreated, fmt.Errorf("failed to parse config file: %w", err)
+	config, sources, err := loadLayers(filename, projectFile)
+	if err != nil {
+		return nil, nil, configCreated, err
 	}
-
-	return &config, configCreated, nil
+	return config, sources, configCreated, nil
 }
 
 // createDefaultConfig creates a default configuration file.
@@ -120,6 +364,16 @@ func createDefaultConfig(filename string) error {
 	// Add comments for documentation
 	yamlString := string(data)
 
+	// Add binary documentation
+	yamlString = strings.Replace(yamlString,
+		"terraform:",
+		`terraform:
+  # Binary to run, e.g. tofu for OpenTofu or a path. When empty, terraform is
+  # run, or tofu if terraform is not installed. TFAPP_BINARY overrides it.
+  # var_files and args are added to every plan. A .tfapp.yaml file in the
+  # project, or one of its parents, overrides the settings of this file.`,
+		1)
+
 	// Add spinner documentation
 	yamlString = strings.Replace(yamlString,
 		"ui:",
@@ -129,6 +383,51 @@ func createDefaultConfig(filename string) error {
   # See: https://pkg.go.dev/github.com/charmbracelet/bubbles@v0.20.0/spinner`,
 		1)
 
+	// Add policy documentation
+	yamlString = strings.Replace(yamlString,
+		"policy:",
+		`policy:
+  # Resources that must not be destroyed or replaced without an explicit override.
+  # protected takes address globs such as "aws_db_instance.*" or "module.prod.*",
+  # protected_types takes resource types such as "aws_db_instance".
+  # on_violation is "confirm" (ask for a second typed confirmation) or "refuse".`,
+		1)
+
+	// Add cost documentation
+	yamlString = strings.Replace(yamlString,
+		"cost:",
+		`cost:
+  # Pricing catalog used to estimate monthly cost changes. When empty,
+  # pricing.yaml next to this file is used if it exists.`,
+		1)
+
+	// Add risk documentation
+	yamlString = strings.Replace(yamlString,
+		"risk:",
+		`risk:
+  # Changes are scored by adding up the weights of their risk factors.
+  # stateful_types overrides the built-in list of types holding data.
+  # Scores from high_risk are listed first in the summary, and applying
+  # changes scored from confirm_above needs an extra typed confirmation.`,
+		1)
+
+	// Add history documentation
+	yamlString = strings.Replace(yamlString,
+		"history:",
+		`history:
+  # Every run is recorded in path (default: history.jsonl next to this file).
+  # List past runs with 'tfapp history'.`,
+		1)
+
+	// Add stacks documentation
+	yamlString = strings.Replace(yamlString,
+		"stacks:",
+		`stacks:
+  # 'tfapp stacks' plans up to parallelism root modules at the same time.
+  # The dependencies between stacks are read from manifest, relative to the
+  # directory of the stacks (default: tfapp-stacks.yaml).`,
+		1)
+
 	// Write to file
 	if err := os.WriteFile(filename, []byte(yamlString), 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
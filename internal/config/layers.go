@@ -0,0 +1,292 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFile is the name of the project configuration file, found in
+// the working directory or one of its parents.
+const ProjectConfigFile = ".tfapp.yaml"
+
+// EnvPrefix starts the names of the environment variables overriding single
+// values, e.g. TFAPP_COLORS_INFO for colors.info.
+const EnvPrefix = "TFAPP_"
+
+// SourceDefault is the source of the values no layer sets.
+const SourceDefault = "default"
+
+// userOnlySettings are the settings a project configuration file cannot set:
+// a cloned repository could otherwise choose the binary tfapp runs or the file
+// it writes its history to.
+var userOnlySettings = []string{"terraform.binary", "history"}
+
+// envNames holds the environment variables not named after their path.
+var envNames = map[string]string{
+	"terraform.binary": "TFAPP_BINARY", // Also read by terraform.FindBinary
+}
+
+// Sources maps the path of each value set by a layer, e.g. "colors.info", to
+// the layer that set it last: a file path or an environment variable.
+type Sources map[string]string
+
+// Of returns the source of the value at path.
+func (s Sources) Of(path string) string {
+	if source, ok := s[path]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// FindProjectConfig returns the path of the project configuration file in dir
+// or its nearest parent holding one, or "" if there is none.
+func FindProjectConfig(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve directory: %w", err)
+	}
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+// loadLayers builds the configuration from its layers, each overriding the
+// values it sets: the defaults, the user configuration file, the project
+// configuration file if projectFile is set, and the environment.
+func loadLayers(userFile, projectFile string) (*Config, Sources, error) {
+	config := DefaultConfig()
+	sources := make(Sources)
+
+	if err := applyFile(config, sources, userFile, nil); err != nil {
+		return nil, nil, err
+	}
+	if projectFile != "" {
+		if err := applyFile(config, sources, projectFile, userOnlySettings); err != nil {
+			return nil, nil, err
+		}
+		resolveProjectPaths(config, sources, projectFile)
+	}
+	if err := applyEnv(config, sources); err != nil {
+		return nil, nil, err
+	}
+	return config, sources, nil
+}
+
+// applyFile sets the values of a configuration file and records their source.
+// It fails if the file sets one of the forbidden settings or a value within it.
+func applyFile(config *Config, sources Sources, path string, forbidden []string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if node.Kind == 0 {
+		return nil // Empty file
+	}
+
+	fileSources := make(Sources)
+	recordSources(fileSources, &node, "", path)
+	for setting := range fileSources {
+		for _, name := range forbidden {
+			if setting == name || strings.HasPrefix(setting, name+".") {
+				return fmt.Errorf("%s cannot set %s, which is only read from the user configuration", path, setting)
+			}
+		}
+	}
+
+	if err := node.Decode(config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	for setting, source := range fileSources {
+		sources[setting] = source
+	}
+	return nil
+}
+
+// recordSources records source for the path of every value in a YAML node.
+// Mappings are walked into, so that a file setting colors.info does not
+// claim the other colors; sequences are values of their own.
+func recordSources(sources Sources, node *yaml.Node, path, source string) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			recordSources(sources, child, path, source)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			recordSources(sources, node.Content[i+1], joinPath(path, node.Content[i].Value), source)
+		}
+	default:
+		if path != "" {
+			sources[path] = source
+		}
+	}
+}
+
+// resolveProjectPaths makes the relative paths set by the project file
+// relative to its directory rather than to the working directory.
+func resolveProjectPaths(config *Config, sources Sources, projectFile string) {
+	dir := filepath.Dir(projectFile)
+	resolve := func(path string) string {
+		if path == "" || filepath.IsAbs(path) || strings.HasPrefix(path, "~/") {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	if sources["terraform.var_files"] == projectFile {
+		for i, file := range config.Terraform.VarFiles {
+			config.Terraform.VarFiles[i] = resolve(file)
+		}
+	}
+	if sources["cost.catalog"] == projectFile {
+		config.Cost.Catalog = resolve(config.Cost.Catalog)
+	}
+}
+
+// applyEnv sets the values overridden by environment variables. Every string,
+// number and boolean setting has a variable named after its path, e.g.
+// TFAPP_COLORS_INFO; lists of strings are read as comma-separated values.
+func applyEnv(config *Config, sources Sources) error {
+	return walkValues(reflect.ValueOf(config).Elem(), "", func(path string, value reflect.Value) error {
+		name := EnvName(path)
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := setValue(value, raw); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %w", raw, name, err)
+		}
+		sources[path] = "env " + name
+		return nil
+	})
+}
+
+// EnvName returns the environment variable overriding the value at path.
+func EnvName(path string) string {
+	if name, ok := envNames[path]; ok {
+		return name
+	}
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+// walkValues calls fn with the path and value of every setting of a struct
+// that an environment variable can override.
+func walkValues(value reflect.Value, path string, fn func(path string, value reflect.Value) error) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+
+		switch fieldValue := value.Field(i); fieldValue.Kind() {
+		case reflect.Struct:
+			if err := walkValues(fieldValue, fieldPath, fn); err != nil {
+				return err
+			}
+		case reflect.String, reflect.Int, reflect.Bool, reflect.Float64:
+			if err := fn(fieldPath, fieldValue); err != nil {
+				return err
+			}
+		case reflect.Slice:
+			if fieldValue.Type().Elem().Kind() == reflect.String {
+				if err := fn(fieldPath, fieldValue); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// setValue parses raw into a setting.
+func setValue(value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		value.SetFloat(f)
+	case reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// joinPath appends a key to a dotted path.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Annotate returns the configuration as YAML with the source of each value as
+// a comment after it.
+func Annotate(config *Config, sources Sources) ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(config); err != nil {
+		return nil, fmt.Errorf("error encoding configuration: %w", err)
+	}
+	annotate(&node, "", sources)
+	return yaml.Marshal(&node)
+}
+
+// annotate sets the source of every value of a mapping node as its comment.
+func annotate(node *yaml.Node, path string, sources Sources) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		valuePath := joinPath(path, key.Value)
+		if value.Kind == yaml.MappingNode && len(value.Content) > 0 {
+			annotate(value, valuePath, sources)
+			continue
+		}
+		if value.Kind == yaml.SequenceNode && len(value.Content) > 0 {
+			// The items start on the next line, so the comment goes after the key
+			key.LineComment = sources.Of(valuePath)
+			continue
+		}
+		value.LineComment = sources.Of(valuePath)
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to path, creating its directory.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "envs", "prod")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if path, err := FindProjectConfig(nested); err != nil || path != "" {
+		t.Errorf("FindProjectConfig without a file = %q, %v", path, err)
+	}
+
+	projectFile := filepath.Join(root, ProjectConfigFile)
+	writeFile(t, projectFile, "colors:\n  info: '#000000'\n")
+	if path, err := FindProjectConfig(nested); err != nil || path != projectFile {
+		t.Errorf("FindProjectConfig = %q, %v, want %q", path, err, projectFile)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "user.yaml")
+	writeFile(t, userFile, "colors:\n  info: '#111111'\n  warning: '#222222'\nterraform:\n  args: [-lock=false]\n")
+	projectFile := filepath.Join(dir, "project", ProjectConfigFile)
+	writeFile(t, projectFile, "colors:\n  info: '#333333'\nterraform:\n  var_files: [common.tfvars, /abs.tfvars]\n")
+	t.Setenv("TFAPP_COLORS_WARNING", "#444444")
+	t.Setenv("TFAPP_POLICY_PROTECTED_TYPES", "aws_db_instance, aws_s3_bucket")
+
+	config, sources, err := loadLayers(userFile, projectFile)
+	if err != nil {
+		t.Fatalf("loadLayers returned error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		path, value, source string
+	}{
+		{"colors.info", config.Colors.Info, projectFile},
+		{"colors.warning", config.Colors.Warning, "env TFAPP_COLORS_WARNING"},
+		{"colors.error", config.Colors.Error, SourceDefault},
+	} {
+		if got := sources.Of(tc.path); got != tc.source {
+			t.Errorf("source of %s = %q, want %q", tc.path, got, tc.source)
+		}
+	}
+	if config.Colors.Info != "#333333" || config.Colors.Warning != "#444444" {
+		t.Errorf("colors = %+v", config.Colors)
+	}
+	if config.Colors.Error != DefaultConfig().Colors.Error {
+		t.Errorf("default error color lost: %q", config.Colors.Error)
+	}
+
+	wantVarFiles := []string{filepath.Join(dir, "project", "common.tfvars"), "/abs.tfvars"}
+	if !reflect.DeepEqual(config.Terraform.VarFiles, wantVarFiles) {
+		t.Errorf("var files = %q, want %q", config.Terraform.VarFiles, wantVarFiles)
+	}
+	wantArgs := []string{"-var-file=" + wantVarFiles[0], "-var-file=/abs.tfvars", "-lock=false"}
+	if got := config.Terraform.PlanArgs(); !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("plan args = %q, want %q", got, wantArgs)
+	}
+	wantTypes := []string{"aws_db_instance", "aws_s3_bucket"}
+	if !reflect.DeepEqual(config.Policy.ProtectedTypes, wantTypes) {
+		t.Errorf("protected types = %q, want %q", config.Policy.ProtectedTypes, wantTypes)
+	}
+}
+
+func TestLoadLayersInvalidEnv(t *testing.T) {
+	userFile := filepath.Join(t.TempDir(), "user.yaml")
+	writeFile(t, userFile, "")
+	t.Setenv("TFAPP_HISTORY_DISABLED", "sometimes")
+
+	_, _, err := loadLayers(userFile, "")
+	if err == nil || !strings.Contains(err.Error(), "TFAPP_HISTORY_DISABLED") {
+		t.Errorf("loadLayers error = %v, want one naming TFAPP_HISTORY_DISABLED", err)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	config := DefaultConfig()
+	config.Colors.Info = "#333333"
+	config.Policy.ProtectedTypes = []string{"aws_db_instance"}
+	sources := Sources{
+		"colors.info":            "/repo/.tfapp.yaml",
+		"policy.protected_types": "env TFAPP_POLICY_PROTECTED_TYPES",
+	}
+
+	data, err := Annotate(config, sources)
+	if err != nil {
+		t.Fatalf("Annotate returned error: %v", err)
+	}
+	output := string(data)
+	for _, want := range []string{
+		"info: '#333333' # /repo/.tfapp.yaml",
+		"protected_types: # env TFAPP_POLICY_PROTECTED_TYPES",
+		"args: [] # default",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("annotated config missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestProjectCannotSetUserOnlySettings(t *testing.T) {
+	dir := t.TempDir()
+	userFile := filepath.Join(dir, "user.yaml")
+	writeFile(t, userFile, "terraform:\n  binary: tofu\n")
+	projectFile := filepath.Join(dir, ProjectConfigFile)
+
+	for _, content := range []string{
+		"terraform:\n  binary: ./bin/terraform\n",
+		"history:\n  path: history.jsonl\n",
+	} {
+		writeFile(t, projectFile, content)
+		if _, _, err := loadLayers(userFile, projectFile); err == nil || !strings.Contains(err.Error(), "only read from the user configuration") {
+			t.Errorf("loadLayers with project file %q returned %v", content, err)
+		}
+	}
+
+	// The binary comes from the user file or TFAPP_BINARY, never TFAPP_TERRAFORM_BINARY
+	writeFile(t, projectFile, "")
+	t.Setenv("TFAPP_TERRAFORM_BINARY", "ignored")
+	config, sources, err := loadLayers(userFile, projectFile)
+	if err != nil || config.Terraform.Binary != "tofu" || sources.Of("terraform.binary") != userFile {
+		t.Errorf("binary = %q from %q, %v; want tofu from the user file", config.Terraform.Binary, sources.Of("terraform.binary"), err)
+	}
+	t.Setenv("TFAPP_BINARY", "terraform-1.9")
+	config, sources, err = loadLayers(userFile, projectFile)
+	if err != nil || config.Terraform.Binary != "terraform-1.9" || sources.Of("terraform.binary") != "env TFAPP_BINARY" {
+		t.Errorf("binary = %q from %q, %v; want terraform-1.9 from TFAPP_BINARY", config.Terraform.Binary, sources.Of("terraform.binary"), err)
+	}
+}
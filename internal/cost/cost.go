@@ -0,0 +1,160 @@
+// Package cost estimates how a plan changes the monthly cost of the
+// infrastructure, using a pricing catalog maintained by the user.
+package cost
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"tfapp/internal/planjson"
+)
+
+// DefaultHoursPerMonth converts hourly prices to monthly ones.
+const DefaultHoursPerMonth = 730
+
+// Catalog maps resource types to hourly prices.
+type Catalog struct {
+	Currency  string                     `yaml:"currency"`
+	Resources map[string]ResourcePricing `yaml:"resources"`
+
+	hoursPerMonth float64
+}
+
+// ResourcePricing is the hourly price of a resource type. With Attribute set,
+// the price depends on the attribute's value (e.g. instance_type) and Hourly is
+// the price of values missing from Prices; otherwise Hourly is a flat price.
+type ResourcePricing struct {
+	Attribute string             `yaml:"attribute"`
+	Prices    map[string]float64 `yaml:"prices"`
+	Hourly    float64            `yaml:"hourly"`
+}
+
+// LoadCatalog reads a pricing catalog file.
+func LoadCatalog(path string, hoursPerMonth float64) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading pricing catalog: %w", err)
+	}
+
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("error parsing pricing catalog %s: %w", path, err)
+	}
+	if catalog.Currency == "" {
+		catalog.Currency = "USD"
+	}
+	if hoursPerMonth <= 0 {
+		hoursPerMonth = DefaultHoursPerMonth
+	}
+	catalog.hoursPerMonth = hoursPerMonth
+	return &catalog, nil
+}
+
+// ResourceCost is the estimated monthly cost change of one resource.
+type ResourceCost struct {
+	Address string  `json:"address"`
+	Action  string  `json:"action"`
+	Before  float64 `json:"monthly_before"`
+	After   float64 `json:"monthly_after"`
+	Known   bool    `json:"known"`            // False when the priced attribute is unknown until apply or missing from the catalog
+	Detail  string  `json:"detail,omitempty"` // The priced attribute's change, e.g. "t3.micro → t3.small"
+}
+
+// Delta returns the monthly cost change.
+func (r ResourceCost) Delta() float64 {
+	return r.After - r.Before
+}
+
+// Estimate is the monthly cost change of a plan.
+type Estimate struct {
+	Currency  string         `json:"currency"`
+	Resources []ResourceCost `json:"resources"`
+	Total     float64        `json:"monthly_delta"` // Sum of the known deltas
+	Unknown   int            `json:"unknown"`       // Number of resources whose price is not known yet
+}
+
+// Estimate prices the changes of a plan. Resources whose type is not in the
+// catalog, and updates that do not change the price, are left out.
+// A nil catalog estimates nothing.
+func (c *Catalog) Estimate(plan *planjson.Plan) *Estimate {
+	if c == nil {
+		return nil
+	}
+
+	estimate := &Estimate{Currency: c.Currency, Resources: []ResourceCost{}}
+	for _, change := range plan.ResourceChanges {
+		pricing, ok := c.Resources[change.Type]
+		if !ok || change.Mode == "data" || change.Change.Actions.IsNoOp() {
+			continue
+		}
+
+		actions := change.Change.Actions
+		resource := ResourceCost{Address: change.Address, Action: actions.Summary(), Known: true}
+
+		var beforeValue, afterValue string
+		if actions.Contains("delete") || actions.Contains("update") {
+			var known bool
+			resource.Before, beforeValue, known = c.monthlyPrice(pricing, change.Change.Before)
+			resource.Known = resource.Known && known
+		}
+		if actions.Contains("create") || actions.Contains("update") {
+			var known bool
+			resource.After, afterValue, known = c.monthlyPrice(pricing, change.Change.After)
+			resource.Known = resource.Known && known
+		}
+
+		if beforeValue != "" && afterValue != "" && beforeValue != afterValue {
+			resource.Detail = beforeValue + " → " + afterValue
+		}
+		if resource.Known && resource.Delta() == 0 && actions.Contains("update") {
+			continue
+		}
+
+		if resource.Known {
+			estimate.Total += resource.Delta()
+		} else {
+			estimate.Unknown++
+		}
+		estimate.Resources = append(estimate.Resources, resource)
+	}
+
+	sort.SliceStable(estimate.Resources, func(i, j int) bool {
+		return estimate.Resources[i].Address < estimate.Resources[j].Address
+	})
+	return estimate
+}
+
+// monthlyPrice returns the monthly price of a resource with the given attributes,
+// the value of the priced attribute, and whether the price is known.
+func (c *Catalog) monthlyPrice(pricing ResourcePricing, attributes interface{}) (float64, string, bool) {
+	if pricing.Attribute == "" {
+		return pricing.Hourly * c.hoursPerMonth, "", true
+	}
+
+	values, _ := attributes.(map[string]interface{})
+	value, ok := values[pricing.Attribute].(string)
+	if !ok {
+		// Attributes only known after apply are absent from the plan
+		return 0, "", false
+	}
+	if price, ok := pricing.Prices[value]; ok {
+		return price * c.hoursPerMonth, value, true
+	}
+	return pricing.Hourly * c.hoursPerMonth, value, pricing.Hourly > 0
+}
+
+// FormatMoney formats a monthly amount with its sign, e.g. "+$12.41" or "-8.00 EUR".
+func FormatMoney(amount float64, currency string) string {
+	sign := "+"
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if currency == "USD" {
+		return fmt.Sprintf("%s$%.2f", sign, amount)
+	}
+	return fmt.Sprintf("%s%.2f %s", sign, amount, currency)
+}
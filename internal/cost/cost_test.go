@@ -0,0 +1,166 @@
+package cost
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+const testCatalog = `
+resources:
+  aws_instance:
+    attribute: instance_type
+    prices:
+      t3.micro: 0.0104
+      t3.small: 0.0208
+  aws_db_instance:
+    attribute: instance_class
+    prices:
+      db.t3.small: 0.034
+  aws_iam_role:
+    hourly: 0
+  aws_security_group:
+    hourly: 0.01
+`
+
+func loadCatalog(t *testing.T, content string) *Catalog {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := LoadCatalog(path, 0)
+	if err != nil {
+		t.Fatalf("LoadCatalog: %v", err)
+	}
+	return catalog
+}
+
+func loadPlan(t *testing.T, fixture string) *planjson.Plan {
+	t.Helper()
+
+	plan, err := planjson.Parse(testutil.PlanFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("parsing %s: %v", fixture, err)
+	}
+	return plan
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 0.005
+}
+
+func TestEstimateCreate(t *testing.T) {
+	estimate := loadCatalog(t, testCatalog).Estimate(loadPlan(t, "create"))
+
+	if estimate.Currency != "USD" {
+		t.Errorf("Currency = %q, want USD", estimate.Currency)
+	}
+	if len(estimate.Resources) != 2 {
+		t.Fatalf("got %d resources, want 2: %+v", len(estimate.Resources), estimate.Resources)
+	}
+
+	instance := estimate.Resources[0]
+	if instance.Address != "aws_instance.web" || !instance.Known || !approx(instance.After, 7.592) || instance.Before != 0 {
+		t.Errorf("unexpected instance cost: %+v", instance)
+	}
+	group := estimate.Resources[1]
+	if group.Address != "aws_security_group.web" || !approx(group.Delta(), 7.3) {
+		t.Errorf("unexpected security group cost: %+v", group)
+	}
+	if !approx(estimate.Total, 14.892) || estimate.Unknown != 0 {
+		t.Errorf("Total = %.3f, Unknown = %d", estimate.Total, estimate.Unknown)
+	}
+}
+
+func TestEstimateReplace(t *testing.T) {
+	estimate := loadCatalog(t, testCatalog).Estimate(loadPlan(t, "replace"))
+
+	got := make(map[string]ResourceCost)
+	for _, resource := range estimate.Resources {
+		got[resource.Address] = resource
+	}
+
+	// db.t3.medium is missing from the catalog and has no fallback price
+	if db := got["aws_db_instance.main"]; db.Known {
+		t.Errorf("expected an unknown price for the database: %+v", db)
+	}
+	// Replacing an instance with the same type does not change the cost
+	if cache := got["aws_instance.cache"]; !cache.Known || !approx(cache.Delta(), 0) || !approx(cache.Before, 15.184) {
+		t.Errorf("unexpected cache instance cost: %+v", cache)
+	}
+	if role, ok := got["aws_iam_role.legacy"]; !ok || role.Delta() != 0 {
+		t.Errorf("expected a free role deletion: %+v", role)
+	}
+	if _, ok := got["aws_s3_bucket.logs"]; ok {
+		t.Error("resource types missing from the catalog should not be priced")
+	}
+	if estimate.Unknown != 1 {
+		t.Errorf("Unknown = %d, want 1", estimate.Unknown)
+	}
+}
+
+func TestEstimateUpdateDetail(t *testing.T) {
+	plan := &planjson.Plan{ResourceChanges: []planjson.ResourceChange{{
+		Address: "aws_instance.app",
+		Mode:    "managed",
+		Type:    "aws_instance",
+		Change: planjson.Change{
+			Actions: planjson.Actions{"update"},
+			Before:  map[string]interface{}{"instance_type": "t3.micro"},
+			After:   map[string]interface{}{"instance_type": "t3.small"},
+		},
+	}}}
+
+	estimate := loadCatalog(t, testCatalog).Estimate(plan)
+	if len(estimate.Resources) != 1 {
+		t.Fatalf("got %d resources, want 1", len(estimate.Resources))
+	}
+	resource := estimate.Resources[0]
+	if resource.Detail != "t3.micro → t3.small" || !approx(resource.Delta(), 7.592) {
+		t.Errorf("unexpected update cost: %+v", resource)
+	}
+}
+
+func TestEstimateNilCatalog(t *testing.T) {
+	var catalog *Catalog
+	if estimate := catalog.Estimate(loadPlan(t, "create")); estimate != nil {
+		t.Errorf("expected no estimate, got %+v", estimate)
+	}
+}
+
+func TestLoadCatalogErrors(t *testing.T) {
+	if _, err := LoadCatalog(filepath.Join(t.TempDir(), "missing.yaml"), 0); err == nil {
+		t.Error("expected an error for a missing catalog")
+	}
+
+	path := filepath.Join(t.TempDir(), "pricing.yaml")
+	if err := os.WriteFile(path, []byte("resources: [unclosed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCatalog(path, 0); err == nil {
+		t.Error("expected an error for an invalid catalog")
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{12.41, "USD", "+$12.41"},
+		{-8, "EUR", "-8.00 EUR"},
+		{0, "USD", "+$0.00"},
+	}
+	for _, tt := range tests {
+		if got := FormatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatMoney(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
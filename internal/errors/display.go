@@ -43,9 +43,3 @@ func DisplayError(err error) {
 		fmt.Fprintf(os.Stderr, "%sError:%s %s\n", ui.ColorError, ui.ColorReset, errMsg)
 	}
 }
-
-// ExitWithError displays an error and exits with non-zero status code.
-func ExitWithError(err error, code int) {
-	DisplayError(err)
-	os.Exit(code)
-}
@@ -16,6 +16,18 @@ var (
 
 	// ErrConfigurationInvalid is returned when application configuration is invalid.
 	ErrConfigurationInvalid = errors.New("Configuration is invalid")
+
+	// ErrNoChanges is returned when a plan contains no changes to apply.
+	ErrNoChanges = errors.New("No changes detected in plan")
+
+	// ErrStalePlan is returned when the state has changed since a saved plan was made.
+	ErrStalePlan = errors.New("Saved plan is stale")
+
+	// ErrProtectedResources is returned when a plan destroys or replaces protected resources.
+	ErrProtectedResources = errors.New("Plan destroys protected resources")
+
+	// ErrPolicyFailed is returned when a plan fails a policy rule.
+	ErrPolicyFailed = errors.New("Plan fails policy checks")
 )
 
 // ValidationError represents an error that occurs during validation.
@@ -105,6 +117,62 @@ func NewConfigurationError(component, message string, err error) error {
 	}
 }
 
+// ExitError requests a specific process exit code.
+// Err may be nil when the exit code reports a status rather than a failure,
+// as with the detailed exit codes used in CI mode.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error returns the error message.
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// NewExitError creates a new ExitError.
+func NewExitError(code int, err error) error {
+	return &ExitError{
+		Code: code,
+		Err:  err,
+	}
+}
+
+// ApplyError is the failure of an apply that errored on some resources,
+// possibly after changing others.
+type ApplyError struct {
+	Succeeded []string // Addresses of the resources whose changes were applied
+	Failed    []string // Addresses of the resources whose changes errored
+	Err       error
+}
+
+// Error returns the error message.
+func (e *ApplyError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+// NewApplyError creates a new ApplyError.
+func NewApplyError(succeeded, failed []string, err error) error {
+	return &ApplyError{
+		Succeeded: succeeded,
+		Failed:    failed,
+		Err:       err,
+	}
+}
+
 // IsValidationError returns true if the error is a ValidationError.
 func IsValidationError(err error) bool {
 	var valErr *ValidationError
@@ -123,6 +191,12 @@ func IsConfigurationError(err error) bool {
 	return errors.As(err, &confErr)
 }
 
+// IsApplyError returns true if the error is an ApplyError.
+func IsApplyError(err error) bool {
+	var applyErr *ApplyError
+	return errors.As(err, &applyErr)
+}
+
 // IsErrUserAborted returns true if the error is or wraps ErrUserAborted.
 func IsErrUserAborted(err error) bool {
 	return errors.Is(err, ErrUserAborted)
@@ -137,3 +211,23 @@ func IsErrInvalidInput(err error) bool {
 func IsErrConfigurationInvalid(err error) bool {
 	return errors.Is(err, ErrConfigurationInvalid)
 }
+
+// IsErrNoChanges returns true if the error is or wraps ErrNoChanges.
+func IsErrNoChanges(err error) bool {
+	return errors.Is(err, ErrNoChanges)
+}
+
+// IsErrStalePlan returns true if the error is or wraps ErrStalePlan.
+func IsErrStalePlan(err error) bool {
+	return errors.Is(err, ErrStalePlan)
+}
+
+// IsErrProtectedResources returns true if the error is or wraps ErrProtectedResources.
+func IsErrProtectedResources(err error) bool {
+	return errors.Is(err, ErrProtectedResources)
+}
+
+// IsErrPolicyFailed returns true if the error is or wraps ErrPolicyFailed.
+func IsErrPolicyFailed(err error) bool {
+	return errors.Is(err, ErrPolicyFailed)
+}
@@ -0,0 +1,242 @@
+// Package history keeps an append-only log of tfapp runs: what was run where,
+// what the plan would change and how the run ended.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Outcomes of a run.
+const (
+	OutcomeApplied     = "applied"      // Terraform applied the plan
+	OutcomeApplyFailed = "apply_failed" // Terraform failed to apply some or all of the plan
+	OutcomePlanned     = "planned"      // A plan was made but not applied
+	OutcomeNoChanges   = "no_changes"   // The plan had nothing to apply
+	OutcomeAborted     = "aborted"      // The user aborted the run
+	OutcomeFailed      = "failed"       // The run failed before anything was applied
+	OutcomeSucceeded   = "succeeded"    // A run that neither plans nor applies succeeded
+)
+
+// Counts is the number of changes per action of the plan of a run.
+type Counts struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+	Replace int `json:"replace"`
+}
+
+// String formats the counts like terraform, e.g. "+2 ~1 -0".
+func (c Counts) String() string {
+	return fmt.Sprintf("+%d ~%d -%d", c.Add, c.Change, c.Destroy)
+}
+
+// Entry records one tfapp run.
+type Entry struct {
+	ID               int       `json:"-"` // Position in the history, from 1; assigned when reading
+	Time             time.Time `json:"time"`
+	User             string    `json:"user"`
+	WorkingDir       string    `json:"working_dir"`
+	Workspace        string    `json:"workspace"`
+	TfappVersion     string    `json:"tfapp_version"`
+	TerraformVersion string    `json:"terraform_version,omitempty"` // Known once a plan was made
+	Command          string    `json:"command"`                     // tfapp command, "menu" for the interactive menu
+	Args             []string  `json:"args"`
+	Counts           *Counts   `json:"counts,omitempty"`  // Changes of the last plan of the run
+	Targets          []string  `json:"targets,omitempty"` // -target addresses of the last plan
+	Failed           []string  `json:"failed,omitempty"`  // Resources that failed to apply
+	Outcome          string    `json:"outcome"`
+	Error            string    `json:"error,omitempty"`
+	Seconds          float64   `json:"duration_seconds"`
+	Summary          string    `json:"summary,omitempty"` // Rendered summary of the last plan
+}
+
+// Duration returns how long the run took.
+func (e *Entry) Duration() time.Duration {
+	return time.Duration(e.Seconds * float64(time.Second))
+}
+
+// Store is a history file with one JSON entry per line. Entries are only ever appended.
+type Store struct {
+	path string
+}
+
+// NewStore creates a store for the history file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the path of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append adds an entry at the end of the history, with the values of its
+// -var arguments redacted.
+func (s *Store) Append(entry Entry) error {
+	entry.Args = RedactArgs(entry.Args)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding history entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("error creating history directory: %w", err)
+	}
+
+	// Entries may contain plan details, so keep the file private to the user
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("error opening history file: %w", err)
+	}
+	// A single write keeps concurrent runs from interleaving their entries
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("error writing history entry: %w", err)
+	}
+	return f.Close()
+}
+
+// Redacted replaces the values of -var arguments in the history.
+const Redacted = "(redacted)"
+
+// RedactArgs returns a copy of args with the values of -var arguments, which
+// often carry secrets, replaced: -var=db_password=hunter2 becomes
+// -var=db_password=(redacted). The names of the variables are kept.
+func RedactArgs(args []string) []string {
+	if args == nil {
+		return nil
+	}
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if i > 0 && (args[i-1] == "-var" || args[i-1] == "--var") {
+			arg = redactAssignment(arg)
+		} else if flag, value, ok := strings.Cut(arg, "="); ok && (flag == "-var" || flag == "--var") {
+			arg = flag + "=" + redactAssignment(value)
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
+
+// redactAssignment redacts the value of a NAME=VALUE assignment.
+func redactAssignment(assignment string) string {
+	name, _, ok := strings.Cut(assignment, "=")
+	if !ok {
+		return Redacted
+	}
+	return name + "=" + Redacted
+}
+
+// List returns the entries matching the filter, newest first.
+// Lines that cannot be parsed, such as one cut short by a crash, are skipped.
+func (s *Store) List(filter Filter) ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history file: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // Entries embed plan summaries
+	for id := 1; scanner.Scan(); id++ {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entry.ID = id
+		if filter.Match(&entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %w", err)
+	}
+
+	slices.Reverse(entries)
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// Get returns the entry with the given ID.
+func (s *Store) Get(id int) (*Entry, error) {
+	entries, err := s.List(Filter{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no history entry %d", id)
+}
+
+// Filter selects history entries. Empty fields match every entry.
+type Filter struct {
+	Command    string
+	Outcome    string
+	Workspace  string
+	WorkingDir string    // Runs in this directory
+	Since      time.Time // Runs started at or after this time
+	Text       string    // Case-insensitive text searched in the arguments, targets, failed resources and error
+	Limit      int       // Maximum number of entries, the newest; 0 for all
+}
+
+// Match reports whether an entry passes the filter.
+func (f Filter) Match(entry *Entry) bool {
+	switch {
+	case f.Command != "" && entry.Command != f.Command:
+		return false
+	case f.Outcome != "" && entry.Outcome != f.Outcome:
+		return false
+	case f.Workspace != "" && entry.Workspace != f.Workspace:
+		return false
+	case f.WorkingDir != "" && filepath.Clean(entry.WorkingDir) != filepath.Clean(f.WorkingDir):
+		return false
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	}
+
+	if f.Text == "" {
+		return true
+	}
+	text := strings.ToLower(f.Text)
+	for _, fields := range [][]string{entry.Args, entry.Targets, entry.Failed, {entry.Error}} {
+		for _, field := range fields {
+			if strings.Contains(strings.ToLower(field), text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseSince parses the start of a time window: a duration back from now such
+// as 12h or 7d, or a date such as 2026-10-01.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		if _, err := fmt.Sscanf(days, "%d", &n); err == nil && n >= 0 && fmt.Sprint(n) == days {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration such as 12h or 7d, or a date such as 2006-01-02", value)
+}
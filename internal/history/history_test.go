@@ -0,0 +1,195 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newStore(t *testing.T, entries ...Entry) *Store {
+	t.Helper()
+
+	store := NewStore(filepath.Join(t.TempDir(), "tfapp", "history.jsonl"))
+	for _, entry := range entries {
+		if err := store.Append(entry); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+	return store
+}
+
+func ids(entries []Entry) []int {
+	ids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func TestListNewestFirst(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	store := newStore(t,
+		Entry{Time: start, Command: "menu", Outcome: OutcomeApplied, Workspace: "default", Counts: &Counts{Add: 2}},
+		Entry{Time: start.Add(time.Hour), Command: "plan", Outcome: OutcomePlanned, Workspace: "prod"},
+		Entry{Time: start.Add(2 * time.Hour), Command: "menu", Outcome: OutcomeAborted, Workspace: "default"},
+	)
+
+	entries, err := store.List(Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !slices.Equal(ids(entries), []int{3, 2, 1}) {
+		t.Fatalf("List IDs = %v, want [3 2 1]", ids(entries))
+	}
+	if entries[2].Counts == nil || entries[2].Counts.Add != 2 || !entries[2].Time.Equal(start) {
+		t.Errorf("first entry read back as %+v", entries[2])
+	}
+
+	entries, err = store.List(Filter{Limit: 2})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !slices.Equal(ids(entries), []int{3, 2}) {
+		t.Errorf("List with limit IDs = %v, want [3 2]", ids(entries))
+	}
+
+	info, err := os.Stat(store.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("history file mode = %v, want 0600", mode)
+	}
+}
+
+func TestListSkipsBadLines(t *testing.T) {
+	store := newStore(t, Entry{Command: "plan"})
+	f, err := os.OpenFile(store.Path(), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"command": "apply", "outc` + "\n")
+	f.Close()
+	if err := store.Append(Entry{Command: "destroy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := store.List(Filter{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	// The bad line keeps its ID so that IDs stay stable
+	if !slices.Equal(ids(entries), []int{3, 1}) {
+		t.Errorf("List IDs = %v, want [3 1]", ids(entries))
+	}
+}
+
+func TestListWithoutFile(t *testing.T) {
+	entries, err := NewStore(filepath.Join(t.TempDir(), "history.jsonl")).List(Filter{})
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List = %v, %v; want no entries", entries, err)
+	}
+}
+
+func TestGet(t *testing.T) {
+	store := newStore(t, Entry{Command: "plan"}, Entry{Command: "apply"})
+
+	entry, err := store.Get(2)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if entry.ID != 2 || entry.Command != "apply" {
+		t.Errorf("Get(2) = %+v", entry)
+	}
+	if _, err := store.Get(3); err == nil {
+		t.Error("Get(3) returned no error")
+	}
+}
+
+func TestAppendRedactsVariables(t *testing.T) {
+	args := []string{"-var=db_password=hunter2", "-var", "token=abc", "-var-file=prod.tfvars", "-target=aws_db_instance.main"}
+	store := newStore(t, Entry{Command: "plan", Args: args})
+
+	entry, err := store.Get(1)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	want := []string{"-var=db_password=(redacted)", "-var", "token=(redacted)", "-var-file=prod.tfvars", "-target=aws_db_instance.main"}
+	if !slices.Equal(entry.Args, want) {
+		t.Errorf("args = %q, want %q", entry.Args, want)
+	}
+	if args[0] != "-var=db_password=hunter2" {
+		t.Error("Append changed the arguments of the entry given")
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	entry := &Entry{
+		Time:       time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		Command:    "menu",
+		Outcome:    OutcomeApplyFailed,
+		Workspace:  "prod",
+		WorkingDir: "/work/network",
+		Args:       []string{"-var=env=prod"},
+		Targets:    []string{"aws_vpc.main"},
+		Failed:     []string{"aws_subnet.private[0]"},
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"command", Filter{Command: "menu"}, true},
+		{"other command", Filter{Command: "plan"}, false},
+		{"outcome", Filter{Outcome: OutcomeApplyFailed}, true},
+		{"other outcome", Filter{Outcome: OutcomeApplied}, false},
+		{"workspace", Filter{Workspace: "staging"}, false},
+		{"directory", Filter{WorkingDir: "/work/network/"}, true},
+		{"other directory", Filter{WorkingDir: "/work"}, false},
+		{"since", Filter{Since: entry.Time}, true},
+		{"later", Filter{Since: entry.Time.Add(time.Second)}, false},
+		{"argument", Filter{Text: "ENV=PROD"}, true},
+		{"target", Filter{Text: "aws_vpc"}, true},
+		{"failed resource", Filter{Text: "private"}, true},
+		{"no text", Filter{Text: "database"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(entry); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"7d", now.AddDate(0, 0, -7)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSince(tt.value, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	for _, value := range []string{"", "d", "-3d", "1.5d", "yesterday", "2026-13-01"} {
+		if _, err := ParseSince(value, now); err == nil {
+			t.Errorf("ParseSince(%q) returned no error", value)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 // Package models contains the domain models for the application.
 package models
 
+import (
+	"tfapp/internal/planjson"
+	"tfapp/internal/uijson"
+)
+
 // Resource represents a Terraform resource from a plan.
 type Resource struct {
 	Name   string
@@ -13,12 +18,25 @@ type Executor interface {
 	// RunCommand executes a terraform command with the given arguments.
 	// If redirectOutput is true, the command's output will be redirected to stdout/stderr.
 	RunCommand(ctx interface{}, args []string, spinnerMsg string, redirectOutput bool) error
+	// Output executes a terraform command and returns its standard output.
+	Output(ctx interface{}, args []string) ([]byte, error)
+	// RunInteractive executes a terraform command attached to the terminal.
+	RunInteractive(ctx interface{}, args []string) error
+	// Stream executes a terraform command with -json and sends the events of its output to events.
+	Stream(ctx interface{}, args []string, events chan<- uijson.Event) error
 }
 
 // PlanService defines operations related to Terraform plans.
 type PlanService interface {
 	// CreatePlan generates a Terraform plan and returns affected resources.
+	// It returns errors.ErrNoChanges when the plan contains nothing to apply.
 	CreatePlan(ctx interface{}, planFilePath string, args []string, targeted bool) ([]Resource, error)
+	// LoadPlan returns the parsed JSON representation of a saved plan file.
+	LoadPlan(ctx interface{}, planFilePath string) (*planjson.Plan, error)
+	// PlanJSON returns the raw JSON representation of a saved plan file.
+	PlanJSON(ctx interface{}, planFilePath string) ([]byte, error)
+	// DisplayPlanSummary prints the summary of a saved plan file and returns affected resources.
+	DisplayPlanSummary(ctx interface{}, planFilePath string) ([]Resource, error)
 	// ShowPlan displays the full details of a saved plan file.
 	ShowPlan(ctx interface{}, planFilePath string) error
 }
@@ -29,6 +47,8 @@ type ApplyService interface {
 	Apply(ctx interface{}, planFilePath string) error
 	// ApplyTargets applies the plan only to the selected resources.
 	ApplyTargets(ctx interface{}, targets []string) error
+	// Destroy applies a destroy plan once the user has typed the confirmation text.
+	Destroy(ctx interface{}, planFilePath string, confirmation string) error
 	// Init runs the Terraform init command.
 	Init(ctx interface{}, upgrade bool) error
 }
@@ -0,0 +1,333 @@
+// Package planjson models the JSON plan representation produced by `terraform show -json`.
+// See https://developer.hashicorp.com/terraform/internals/json-format for the format reference.
+package planjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Plan is the top-level JSON plan representation.
+type Plan struct {
+	FormatVersion      string              `json:"format_version"`
+	TerraformVersion   string              `json:"terraform_version,omitempty"`
+	Variables          map[string]Variable `json:"variables,omitempty"`
+	PlannedValues      StateValues         `json:"planned_values"`
+	ResourceDrift      []ResourceChange    `json:"resource_drift,omitempty"`
+	ResourceChanges    []ResourceChange    `json:"resource_changes,omitempty"`
+	DeferredChanges    []DeferredChange    `json:"deferred_changes,omitempty"`
+	OutputChanges      map[string]Change   `json:"output_changes,omitempty"`
+	PriorState         *State              `json:"prior_state,omitempty"`
+	Configuration      Configuration       `json:"configuration"`
+	RelevantAttributes []RelevantAttribute `json:"relevant_attributes,omitempty"`
+	Checks             []CheckResult       `json:"checks,omitempty"`
+	Timestamp          string              `json:"timestamp,omitempty"`
+	Applyable          bool                `json:"applyable"`
+	Complete           bool                `json:"complete"`
+	Errored            bool                `json:"errored"`
+}
+
+// Variable is the value of an input variable used to create the plan.
+type Variable struct {
+	Value interface{} `json:"value"`
+}
+
+// State is a state snapshot, as found in prior_state.
+type State struct {
+	FormatVersion    string       `json:"format_version,omitempty"`
+	TerraformVersion string       `json:"terraform_version,omitempty"`
+	Values           *StateValues `json:"values,omitempty"`
+}
+
+// StateValues holds the outputs and resources of a state or of the planned values.
+type StateValues struct {
+	Outputs    map[string]Output `json:"outputs,omitempty"`
+	RootModule Module            `json:"root_module"`
+}
+
+// Output is a root module output value.
+type Output struct {
+	Sensitive bool        `json:"sensitive"`
+	Type      interface{} `json:"type,omitempty"`
+	Value     interface{} `json:"value,omitempty"`
+}
+
+// Module is a module instance within state values.
+type Module struct {
+	Address      string     `json:"address,omitempty"`
+	Resources    []Resource `json:"resources,omitempty"`
+	ChildModules []Module   `json:"child_modules,omitempty"`
+}
+
+// Resource is a resource instance within state values.
+type Resource struct {
+	Address         string                 `json:"address"`
+	Mode            string                 `json:"mode"`
+	Type            string                 `json:"type"`
+	Name            string                 `json:"name"`
+	Index           interface{}            `json:"index,omitempty"` // int for count, string for for_each
+	ProviderName    string                 `json:"provider_name"`
+	SchemaVersion   int                    `json:"schema_version"`
+	Values          map[string]interface{} `json:"values,omitempty"`
+	SensitiveValues interface{}            `json:"sensitive_values,omitempty"`
+	DependsOn       []string               `json:"depends_on,omitempty"`
+	Tainted         bool                   `json:"tainted,omitempty"`
+	DeposedKey      string                 `json:"deposed_key,omitempty"`
+}
+
+// ResourceChange describes the planned change for a single resource instance.
+type ResourceChange struct {
+	Address         string      `json:"address"`
+	PreviousAddress string      `json:"previous_address,omitempty"`
+	ModuleAddress   string      `json:"module_address,omitempty"`
+	Mode            string      `json:"mode"`
+	Type            string      `json:"type"`
+	Name            string      `json:"name"`
+	Index           interface{} `json:"index,omitempty"` // int for count, string for for_each
+	ProviderName    string      `json:"provider_name,omitempty"`
+	Deposed         string      `json:"deposed,omitempty"`
+	Change          Change      `json:"change"`
+	ActionReason    string      `json:"action_reason,omitempty"`
+}
+
+// Moved reports whether the resource instance was moved from another address.
+func (rc ResourceChange) Moved() bool {
+	return rc.PreviousAddress != "" && rc.PreviousAddress != rc.Address
+}
+
+// MovedOnly reports whether the resource instance was moved without any other change.
+func (rc ResourceChange) MovedOnly() bool {
+	return rc.Moved() && rc.Change.Actions.IsNoOp()
+}
+
+// Change is the change representation shared by resource and output changes.
+type Change struct {
+	Actions         Actions         `json:"actions"`
+	Before          interface{}     `json:"before"`
+	After           interface{}     `json:"after"`
+	AfterUnknown    interface{}     `json:"after_unknown,omitempty"`
+	BeforeSensitive interface{}     `json:"before_sensitive,omitempty"`
+	AfterSensitive  interface{}     `json:"after_sensitive,omitempty"`
+	ReplacePaths    [][]interface{} `json:"replace_paths,omitempty"` // Path steps are attribute names or list indexes
+	Importing       *Importing      `json:"importing,omitempty"`
+	GeneratedConfig string          `json:"generated_config,omitempty"`
+}
+
+// Importing describes an import that is part of the planned change.
+type Importing struct {
+	ID       string      `json:"id,omitempty"`
+	Identity interface{} `json:"identity,omitempty"`
+	Unknown  bool        `json:"unknown,omitempty"`
+}
+
+// DeferredChange is a resource change that Terraform could not plan yet.
+type DeferredChange struct {
+	Reason         string         `json:"reason"`
+	ResourceChange ResourceChange `json:"resource_change"`
+}
+
+// RelevantAttribute is a resource attribute that contributed to the planned changes.
+type RelevantAttribute struct {
+	Resource  string        `json:"resource"`
+	Attribute []interface{} `json:"attribute"`
+}
+
+// CheckResult is the status of a checkable object (resource, output, check block).
+type CheckResult struct {
+	Address   CheckAddress    `json:"address"`
+	Status    string          `json:"status"` // pass, fail, error or unknown
+	Instances []CheckInstance `json:"instances,omitempty"`
+}
+
+// CheckAddress identifies a checkable object in the configuration.
+type CheckAddress struct {
+	Kind      string `json:"kind"`
+	ToDisplay string `json:"to_display"`
+	Mode      string `json:"mode,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Module    string `json:"module,omitempty"`
+}
+
+// CheckInstance is the status of one dynamic instance of a checkable object.
+type CheckInstance struct {
+	Address  CheckInstanceAddress `json:"address"`
+	Status   string               `json:"status"`
+	Problems []CheckProblem       `json:"problems,omitempty"`
+}
+
+// CheckInstanceAddress identifies a dynamic instance of a checkable object.
+type CheckInstanceAddress struct {
+	ToDisplay   string      `json:"to_display"`
+	Module      string      `json:"module,omitempty"`
+	InstanceKey interface{} `json:"instance_key,omitempty"`
+}
+
+// CheckProblem is a failed condition reported for a check instance.
+type CheckProblem struct {
+	Message string `json:"message"`
+}
+
+// Configuration is the static configuration that produced the plan.
+type Configuration struct {
+	ProviderConfig map[string]ProviderConfig `json:"provider_config,omitempty"`
+	RootModule     ConfigModule              `json:"root_module"`
+}
+
+// ProviderConfig is a provider configuration block.
+type ProviderConfig struct {
+	Name              string                 `json:"name"`
+	FullName          string                 `json:"full_name,omitempty"`
+	Alias             string                 `json:"alias,omitempty"`
+	VersionConstraint string                 `json:"version_constraint,omitempty"`
+	ModuleAddress     string                 `json:"module_address,omitempty"`
+	Expressions       map[string]interface{} `json:"expressions,omitempty"`
+}
+
+// ConfigModule is a module in the configuration.
+type ConfigModule struct {
+	Outputs     map[string]ConfigOutput   `json:"outputs,omitempty"`
+	Resources   []ConfigResource          `json:"resources,omitempty"`
+	ModuleCalls map[string]ModuleCall     `json:"module_calls,omitempty"`
+	Variables   map[string]ConfigVariable `json:"variables,omitempty"`
+}
+
+// ConfigOutput is an output block in the configuration.
+type ConfigOutput struct {
+	Expression  Expression `json:"expression"`
+	Sensitive   bool       `json:"sensitive,omitempty"`
+	Description string     `json:"description,omitempty"`
+	DependsOn   []string   `json:"depends_on,omitempty"`
+}
+
+// ConfigResource is a resource block in the configuration.
+// Its address is relative to the module that declares it.
+type ConfigResource struct {
+	Address           string                 `json:"address"`
+	Mode              string                 `json:"mode"`
+	Type              string                 `json:"type"`
+	Name              string                 `json:"name"`
+	ProviderConfigKey string                 `json:"provider_config_key"`
+	Provisioners      []Provisioner          `json:"provisioners,omitempty"`
+	Expressions       map[string]interface{} `json:"expressions,omitempty"` // Values are expressions or lists of nested blocks
+	SchemaVersion     int                    `json:"schema_version"`
+	CountExpression   *Expression            `json:"count_expression,omitempty"`
+	ForEachExpression *Expression            `json:"for_each_expression,omitempty"`
+	DependsOn         []string               `json:"depends_on,omitempty"`
+}
+
+// Provisioner is a provisioner block in a resource configuration.
+type Provisioner struct {
+	Type        string                 `json:"type"`
+	Expressions map[string]interface{} `json:"expressions,omitempty"`
+}
+
+// ModuleCall is a module block in the configuration.
+type ModuleCall struct {
+	Source            string                 `json:"source"`
+	Expressions       map[string]interface{} `json:"expressions,omitempty"`
+	CountExpression   *Expression            `json:"count_expression,omitempty"`
+	ForEachExpression *Expression            `json:"for_each_expression,omitempty"`
+	Module            ConfigModule           `json:"module"`
+	VersionConstraint string                 `json:"version_constraint,omitempty"`
+	DependsOn         []string               `json:"depends_on,omitempty"`
+}
+
+// ConfigVariable is a variable block in the configuration.
+type ConfigVariable struct {
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty"`
+}
+
+// Expression is a single configuration expression.
+// Constant expressions set ConstantValue; others list the objects they reference.
+type Expression struct {
+	ConstantValue interface{} `json:"constant_value,omitempty"`
+	References    []string    `json:"references,omitempty"`
+}
+
+// Parse decodes the output of `terraform show -json`.
+func Parse(data []byte) (*Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("error parsing plan JSON: %w", err)
+	}
+	return &plan, nil
+}
+
+// HasChanges reports whether any resource in the plan has an action other
+// than no-op, or was moved.
+func (p *Plan) HasChanges() bool {
+	for _, change := range p.ResourceChanges {
+		if !change.Change.Actions.IsNoOp() || change.Moved() {
+			return true
+		}
+	}
+	return false
+}
+
+// Actions is the list of actions of a change, e.g. ["delete", "create"] for a replacement.
+type Actions []string
+
+// Contains reports whether the given action is part of the change.
+func (a Actions) Contains(action string) bool {
+	for _, s := range a {
+		if s == action {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNoOp reports whether the change does nothing.
+func (a Actions) IsNoOp() bool {
+	return len(a) == 0 || a[0] == "no-op"
+}
+
+// Summary collapses the actions into a single verb:
+// replace, create, destroy, update, no-op, or the joined actions otherwise (e.g. "read").
+func (a Actions) Summary() string {
+	switch {
+	case a.Contains("create") && a.Contains("delete"):
+		return "replace"
+	case a.Contains("create"):
+		return "create"
+	case a.Contains("delete"):
+		return "destroy"
+	case a.Contains("update"):
+		return "update"
+	case len(a) == 0:
+		return "no-op"
+	}
+	return strings.Join(a, "/")
+}
+
+// ActionReasonDescription returns a human-readable description of an action_reason value.
+func ActionReasonDescription(reason string) string {
+	switch reason {
+	case "replace_because_tainted":
+		return "tainted, so must be replaced"
+	case "replace_because_cannot_update":
+		return "cannot be updated in-place"
+	case "replace_by_request":
+		return "replacement requested"
+	case "delete_because_no_resource_config":
+		return "no resource configuration found"
+	case "delete_because_no_module":
+		return "containing module is gone"
+	case "delete_because_wrong_repetition":
+		return "wrong repetition mode"
+	case "delete_because_count_index":
+		return "count index out of range"
+	case "delete_because_each_key":
+		return "for_each key not found"
+	case "read_because_config_unknown":
+		return "configuration contains unknown values"
+	case "read_because_dependency_pending":
+		return "has pending dependent resources"
+	default:
+		return reason
+	}
+}
@@ -0,0 +1,62 @@
+package planjson
+
+import (
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestActionsSummary(t *testing.T) {
+	tests := []struct {
+		actions Actions
+		want    string
+	}{
+		{Actions{"create"}, "create"},
+		{Actions{"delete"}, "destroy"},
+		{Actions{"update"}, "update"},
+		{Actions{"delete", "create"}, "replace"},
+		{Actions{"create", "delete"}, "replace"},
+		{Actions{"no-op"}, "no-op"},
+		{Actions{}, "no-op"},
+		{Actions{"read"}, "read"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.actions.Summary(); got != tt.want {
+			t.Errorf("Actions(%v).Summary() = %q, want %q", tt.actions, got, tt.want)
+		}
+	}
+}
+
+func TestParseFixtures(t *testing.T) {
+	for _, name := range testutil.PlanFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			plan, err := Parse(testutil.PlanFixture(t, name))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if plan.FormatVersion == "" {
+				t.Error("FormatVersion is empty")
+			}
+			if got, want := plan.HasChanges(), name != "no_changes"; got != want {
+				t.Errorf("HasChanges() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseConfiguration(t *testing.T) {
+	plan, err := Parse(testutil.PlanFixture(t, "modules"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(plan.Configuration.RootModule.ModuleCalls) == 0 {
+		t.Error("expected module calls in configuration")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("{")); err == nil {
+		t.Error("Parse() of invalid JSON returned no error")
+	}
+}
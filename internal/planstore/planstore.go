@@ -0,0 +1,222 @@
+// Package planstore persists plan artifacts: the binary plan file together
+// with its JSON representation, the rendered summary and metadata describing
+// the state the plan was made against.
+package planstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// Root is the directory tfapp keeps its files in, relative to the Terraform
+// working directory.
+const Root = ".tfapp"
+
+// DefaultDir is the artifact directory, relative to the Terraform working directory.
+const DefaultDir = Root + "/plans"
+
+// File names within an artifact directory.
+const (
+	planFileName     = "plan.tfplan"
+	jsonFileName     = "plan.json"
+	summaryFileName  = "summary.txt"
+	metadataFileName = "metadata.json"
+)
+
+// validName matches artifact names that are safe to use as directory names.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Metadata describes how and against which state a plan was made.
+type Metadata struct {
+	Name             string    `json:"name"`
+	CreatedAt        time.Time `json:"created_at"`
+	WorkingDir       string    `json:"working_dir"`
+	Workspace        string    `json:"workspace,omitempty"` // Empty in artifacts saved before it was recorded
+	Args             []string  `json:"args"`
+	TerraformVersion string    `json:"terraform_version,omitempty"`
+	StateLineage     string    `json:"state_lineage,omitempty"` // Empty when there was no state yet
+	StateSerial      int64     `json:"state_serial"`
+	Changes          int       `json:"changes"`
+}
+
+// Artifact is a saved plan on disk.
+type Artifact struct {
+	Dir      string
+	Metadata Metadata
+}
+
+// PlanFile returns the path of the binary plan file.
+func (a *Artifact) PlanFile() string {
+	return filepath.Join(a.Dir, planFileName)
+}
+
+// JSONFile returns the path of the plan's JSON representation.
+func (a *Artifact) JSONFile() string {
+	return filepath.Join(a.Dir, jsonFileName)
+}
+
+// Summary returns the rendered plan summary saved with the plan.
+func (a *Artifact) Summary() ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(a.Dir, summaryFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading saved plan summary: %w", err)
+	}
+	return data, nil
+}
+
+// Store manages the artifacts in a directory.
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store for artifacts under dir.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// ValidateName checks that name can be used for an artifact.
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid plan name %q: use letters, digits, '.', '_' and '-'", name)
+	}
+	return nil
+}
+
+// Save stores a plan under metadata.Name, replacing any artifact with the same name.
+// The plan file is copied, so the original can be removed afterwards.
+func (s *Store) Save(metadata Metadata, planFile string, planJSON, summary []byte) (*Artifact, error) {
+	if err := ValidateName(metadata.Name); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating plan directory: %w", err)
+	}
+
+	// Write into a staging directory first so a failed save never leaves a partial artifact
+	staging, err := os.MkdirTemp(s.dir, "."+metadata.Name+"-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating plan directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	plan, err := os.ReadFile(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan file: %w", err)
+	}
+	metadataJSON, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error encoding plan metadata: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{planFileName, plan},
+		{jsonFileName, planJSON},
+		{summaryFileName, summary},
+		{metadataFileName, append(metadataJSON, '\n')},
+	}
+	for _, file := range files {
+		// Plans may contain sensitive values, so keep them private to the user
+		if err := os.WriteFile(filepath.Join(staging, file.name), file.data, 0600); err != nil {
+			return nil, fmt.Errorf("error writing saved plan: %w", err)
+		}
+	}
+
+	dir := filepath.Join(s.dir, metadata.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("error replacing saved plan: %w", err)
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return nil, fmt.Errorf("error saving plan: %w", err)
+	}
+
+	return &Artifact{Dir: dir, Metadata: metadata}, nil
+}
+
+// IgnoreInGit writes a .gitignore ignoring everything into dir, creating dir
+// if needed, so that saved plans and the secrets they hold are never committed.
+// An existing .gitignore is left alone.
+func IgnoreInGit(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(path, []byte("# Created by tfapp: saved plans can hold secrets\n*\n"), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	return nil
+}
+
+// List returns the saved artifacts, newest first.
+func (s *Store) List() ([]*Artifact, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing saved plans: %w", err)
+	}
+
+	var artifacts []*Artifact
+	for _, entry := range entries {
+		if !entry.IsDir() || ValidateName(entry.Name()) != nil {
+			continue
+		}
+		artifact, err := Load(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		artifacts = append(artifacts, artifact)
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Metadata.CreatedAt.After(artifacts[j].Metadata.CreatedAt)
+	})
+	return artifacts, nil
+}
+
+// Open returns the artifact with the given name, or the artifact directory at the given path.
+func (s *Store) Open(nameOrPath string) (*Artifact, error) {
+	if ValidateName(nameOrPath) == nil {
+		dir := filepath.Join(s.dir, nameOrPath)
+		if _, err := os.Stat(filepath.Join(dir, metadataFileName)); err == nil {
+			return Load(dir)
+		}
+	}
+
+	if IsArtifact(nameOrPath) {
+		return Load(nameOrPath)
+	}
+	return nil, fmt.Errorf("no saved plan named %q", nameOrPath)
+}
+
+// IsArtifact reports whether dir is an artifact directory.
+func IsArtifact(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, metadataFileName))
+	return err == nil
+}
+
+// Load reads the artifact in dir.
+func Load(dir string) (*Artifact, error) {
+	data, err := os.ReadFile(filepath.Join(dir, metadataFileName))
+	if err != nil {
+		return nil, fmt.Errorf("error reading saved plan metadata: %w", err)
+	}
+
+	var metadata Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("error parsing saved plan metadata: %w", err)
+	}
+
+	return &Artifact{Dir: dir, Metadata: metadata}, nil
+}
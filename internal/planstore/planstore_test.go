@@ -0,0 +1,110 @@
+package planstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func saveTestPlan(t *testing.T, store *Store, name string, createdAt time.Time) *Artifact {
+	t.Helper()
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	if err := os.WriteFile(planFile, []byte("binary plan "+name), 0644); err != nil {
+		t.Fatalf("writing plan file: %v", err)
+	}
+
+	artifact, err := store.Save(Metadata{Name: name, CreatedAt: createdAt, StateLineage: "abc", StateSerial: 3}, planFile, []byte(`{"format_version":"1.2"}`), []byte("Plan: 1 to add\n"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+	return artifact
+}
+
+func TestSaveAndOpen(t *testing.T) {
+	store := NewStore(t.TempDir())
+	saved := saveTestPlan(t, store, "release-1", time.Now())
+
+	artifact, err := store.Open("release-1")
+	if err != nil {
+		t.Fatalf("Open returned error: %v", err)
+	}
+	if artifact.Metadata.StateLineage != "abc" || artifact.Metadata.StateSerial != 3 {
+		t.Errorf("metadata = %+v, want the saved state version", artifact.Metadata)
+	}
+
+	plan, err := os.ReadFile(artifact.PlanFile())
+	if err != nil || string(plan) != "binary plan release-1" {
+		t.Errorf("plan file = %q (%v), want the copied plan", plan, err)
+	}
+	if summary, err := artifact.Summary(); err != nil || string(summary) != "Plan: 1 to add\n" {
+		t.Errorf("summary = %q (%v)", summary, err)
+	}
+
+	// An artifact can also be opened by its directory
+	if _, err := store.Open(saved.Dir); err != nil {
+		t.Errorf("Open(%q) returned error: %v", saved.Dir, err)
+	}
+	if _, err := store.Open("missing"); err == nil {
+		t.Error("Open of a missing plan returned no error")
+	}
+}
+
+func TestListNewestFirst(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Now()
+	saveTestPlan(t, store, "old", now.Add(-time.Hour))
+	saveTestPlan(t, store, "new", now)
+	saveTestPlan(t, store, "old", now.Add(-2*time.Hour)) // Replaces the first "old"
+
+	artifacts, err := store.List()
+	if err != nil {
+		t.Fatalf("List returned error: %v", err)
+	}
+	if len(artifacts) != 2 || artifacts[0].Metadata.Name != "new" || artifacts[1].Metadata.Name != "old" {
+		t.Errorf("List() returned %d artifacts in the wrong order", len(artifacts))
+	}
+}
+
+func TestListMissingDirectory(t *testing.T) {
+	artifacts, err := NewStore(filepath.Join(t.TempDir(), "missing")).List()
+	if err != nil || artifacts != nil {
+		t.Errorf("List() = %v, %v, want no artifacts and no error", artifacts, err)
+	}
+}
+
+func TestIgnoreInGit(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), Root)
+	if err := IgnoreInGit(dir); err != nil {
+		t.Fatalf("IgnoreInGit returned error: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil || string(data[len(data)-2:]) != "*\n" {
+		t.Fatalf(".gitignore = %q, %v; want one ignoring everything", data, err)
+	}
+
+	// An existing .gitignore is kept
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("plans/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := IgnoreInGit(dir); err != nil {
+		t.Fatalf("IgnoreInGit returned error: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".gitignore")); string(data) != "plans/\n" {
+		t.Errorf("existing .gitignore replaced by %q", data)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"release-1", "v1.2_rc"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) returned error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "../escape", "a/b", ".hidden"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) accepted an unsafe name", name)
+		}
+	}
+}
@@ -0,0 +1,759 @@
+package policy
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements the small expression language of policy rules.
+//
+// Expressions are made of:
+//   - literals: numbers, "strings", true, false, null and [lists]
+//   - names: after, before, address, type, name, module, mode and actions,
+//     plus it inside any() and all()
+//   - field access and indexing: after.tags.owner, after.ingress[0], after.tags["cost-center"]
+//   - operators, by increasing precedence: ||, &&, == != < <= > >= in, and unary !
+//   - functions: length, contains, startswith, endswith, matches, lower, any and all
+//
+// Accessing a missing field or index yields null rather than an error, and null
+// counts as false in conditions, so after.tags.owner != null checks that a tag is set.
+
+// ruleNames are the names available to every expression.
+var ruleNames = map[string]bool{
+	"after":   true,
+	"before":  true,
+	"address": true,
+	"type":    true,
+	"name":    true,
+	"module":  true,
+	"mode":    true,
+	"actions": true,
+}
+
+// functionArity is the number of arguments of each function.
+var functionArity = map[string]int{
+	"length":     1,
+	"contains":   2,
+	"startswith": 2,
+	"endswith":   2,
+	"matches":    2,
+	"lower":      1,
+	"any":        2,
+	"all":        2,
+}
+
+// expr is a compiled expression.
+type expr interface {
+	eval(env map[string]interface{}) (interface{}, error)
+}
+
+// Expression is a compiled policy expression along with its source.
+type Expression struct {
+	source string
+	root   expr
+}
+
+// Compile parses an expression.
+func Compile(source string) (*Expression, error) {
+	p := &parser{source: source}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.text)
+	}
+	return &Expression{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expression) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression with the given names.
+func (e *Expression) Eval(env map[string]interface{}) (interface{}, error) {
+	return e.root.eval(env)
+}
+
+// EvalBool evaluates the expression as a condition; null counts as false.
+func (e *Expression) EvalBool(env map[string]interface{}) (bool, error) {
+	value, err := e.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return truth(value)
+}
+
+// Tokens
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators lists the operator tokens, longest first so that "<=" wins over "<".
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")", "[", "]", ",", "."}
+
+// Parser
+
+type parser struct {
+	source string
+	tokens []token
+	next   int
+	scopes int // Number of enclosing any() or all() predicates, where it is defined
+}
+
+func (p *parser) errorf(tok token, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at offset %d in %q", fmt.Sprintf(format, args...), tok.pos, p.source)
+}
+
+func (p *parser) tokenize() error {
+	s := p.source
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(s) && (unicode.IsLetter(rune(s[i])) || unicode.IsDigit(rune(s[i])) || s[i] == '_') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokenIdent, s[start:i], start})
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(s) && unicode.IsDigit(rune(s[i+1]))):
+			start := i
+			i++
+			for i < len(s) && (unicode.IsDigit(rune(s[i])) || s[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, token{tokenNumber, s[start:i], start})
+		case c == '"':
+			start := i
+			i++
+			for i < len(s) && s[i] != '"' {
+				if s[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(s) {
+				return fmt.Errorf("unterminated string at offset %d in %q", start, s)
+			}
+			i++
+			p.tokens = append(p.tokens, token{tokenString, s[start:i], start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					p.tokens = append(p.tokens, token{tokenOperator, op, i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("unexpected character %q at offset %d in %q", c, i, s)
+			}
+		}
+	}
+	p.tokens = append(p.tokens, token{tokenEOF, "end of expression", len(s)})
+	return nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokenEOF {
+		p.next++
+	}
+	return tok
+}
+
+// accept consumes the next token if it is the given operator or keyword.
+func (p *parser) accept(text string) bool {
+	tok := p.peek()
+	if (tok.kind == tokenOperator || tok.kind == tokenIdent) && tok.text == text {
+		p.next++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		return p.errorf(tok, "expected %q but found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.parseUnary()
+			if err != nil {
+				return nil, err
+			}
+			return &comparisonExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (expr, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			tok := p.advance()
+			if tok.kind != tokenIdent {
+				return nil, p.errorf(tok, "expected a field name after '.'")
+			}
+			x = &indexExpr{target: x, key: &literalExpr{value: tok.text}}
+		case p.accept("["):
+			key, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &indexExpr{target: x, key: key}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokenNumber:
+		value, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return &literalExpr{value: value}, nil
+
+	case tokenString:
+		value, err := strconv.Unquote(tok.text)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid string %s", tok.text)
+		}
+		return &literalExpr{value: value}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalExpr{value: true}, nil
+		case "false":
+			return &literalExpr{value: false}, nil
+		case "null":
+			return &literalExpr{value: nil}, nil
+		}
+		if p.accept("(") {
+			return p.parseCall(tok)
+		}
+		if !ruleNames[tok.text] && !(tok.text == "it" && p.scopes > 0) {
+			return nil, p.errorf(tok, "unknown name %q", tok.text)
+		}
+		return &nameExpr{name: tok.text}, nil
+
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			x, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			list := &listExpr{}
+			for !p.accept("]") {
+				if len(list.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		}
+	}
+	return nil, p.errorf(tok, "unexpected %q", tok.text)
+}
+
+func (p *parser) parseCall(name token) (expr, error) {
+	arity, ok := functionArity[name.text]
+	if !ok {
+		return nil, p.errorf(name, "unknown function %q", name.text)
+	}
+
+	call := &callExpr{name: name.text}
+	for !p.accept(")") {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		// The predicate of any() and all() is evaluated for each element, bound to it
+		quantifier := (name.text == "any" || name.text == "all") && len(call.args) == 1
+		if quantifier {
+			p.scopes++
+		}
+		arg, err := p.parseOr()
+		if quantifier {
+			p.scopes--
+		}
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+
+	if len(call.args) != arity {
+		return nil, p.errorf(name, "%s() takes %d arguments, got %d", name.text, arity, len(call.args))
+	}
+	if call.name == "matches" {
+		if pattern, ok := call.args[1].(*literalExpr); ok {
+			s, ok := pattern.value.(string)
+			if !ok {
+				return nil, p.errorf(name, "matches() takes a string pattern")
+			}
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return nil, p.errorf(name, "invalid pattern: %v", err)
+			}
+			call.pattern = re
+		}
+	}
+	return call, nil
+}
+
+// Evaluation
+
+type literalExpr struct {
+	value interface{}
+}
+
+func (e *literalExpr) eval(map[string]interface{}) (interface{}, error) {
+	return e.value, nil
+}
+
+type nameExpr struct {
+	name string
+}
+
+func (e *nameExpr) eval(env map[string]interface{}) (interface{}, error) {
+	return env[e.name], nil
+}
+
+type listExpr struct {
+	items []expr
+}
+
+func (e *listExpr) eval(env map[string]interface{}) (interface{}, error) {
+	list := make([]interface{}, 0, len(e.items))
+	for _, item := range e.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+type indexExpr struct {
+	target expr
+	key    expr
+}
+
+func (e *indexExpr) eval(env map[string]interface{}) (interface{}, error) {
+	target, err := e.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	key, err := e.key.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := target.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		k, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot index an object with %s", typeName(key))
+		}
+		return t[k], nil
+	case []interface{}:
+		i, ok := key.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot index a list with %s", typeName(key))
+		}
+		if i < 0 || int(i) >= len(t) || i != float64(int(i)) {
+			return nil, nil
+		}
+		return t[int(i)], nil
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(target))
+}
+
+type notExpr struct {
+	operand expr
+}
+
+func (e *notExpr) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := e.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	b, err := truth(value)
+	return !b, err
+}
+
+type logicalExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *logicalExpr) eval(env map[string]interface{}) (interface{}, error) {
+	value, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	left, err := truth(value)
+	if err != nil {
+		return nil, err
+	}
+	if (e.op == "&&" && !left) || (e.op == "||" && left) {
+		return left, nil
+	}
+
+	value, err = e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truth(value)
+}
+
+type comparisonExpr struct {
+	op          string
+	left, right expr
+}
+
+func (e *comparisonExpr) eval(env map[string]interface{}) (interface{}, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "==":
+		return reflect.DeepEqual(left, right), nil
+	case "!=":
+		return !reflect.DeepEqual(left, right), nil
+	case "in":
+		return contains(right, left)
+	}
+
+	// Ordering comparisons with null are false, so unknown values never match
+	if left == nil || right == nil {
+		return false, nil
+	}
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare number with %s", typeName(right))
+		}
+		cmp = compareOrdered(l, r)
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot compare string with %s", typeName(right))
+		}
+		cmp = compareOrdered(l, r)
+	default:
+		return nil, fmt.Errorf("cannot compare %s", typeName(left))
+	}
+
+	switch e.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type callExpr struct {
+	name    string
+	args    []expr
+	pattern *regexp.Regexp // Compiled pattern of matches() when it is a literal
+}
+
+func (e *callExpr) eval(env map[string]interface{}) (interface{}, error) {
+	if e.name == "any" || e.name == "all" {
+		return e.evalQuantifier(env)
+	}
+
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = value
+	}
+
+	switch e.name {
+	case "length":
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("length() of %s", typeName(args[0]))
+
+	case "contains":
+		return contains(args[0], args[1])
+
+	case "lower":
+		if args[0] == nil {
+			return nil, nil
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("lower() of %s", typeName(args[0]))
+		}
+		return strings.ToLower(s), nil
+	}
+
+	// The remaining functions take two strings; null never matches
+	if args[0] == nil {
+		return false, nil
+	}
+	s, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() of %s", e.name, typeName(args[0]))
+	}
+	arg, ok := args[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("%s() takes a string as second argument", e.name)
+	}
+
+	switch e.name {
+	case "startswith":
+		return strings.HasPrefix(s, arg), nil
+	case "endswith":
+		return strings.HasSuffix(s, arg), nil
+	default: // matches
+		re := e.pattern
+		if re == nil {
+			var err error
+			if re, err = regexp.Compile(arg); err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+		}
+		return re.MatchString(s), nil
+	}
+}
+
+// evalQuantifier evaluates any(list, predicate) or all(list, predicate).
+func (e *callExpr) evalQuantifier(env map[string]interface{}) (interface{}, error) {
+	value, err := e.args[0].eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []interface{}
+	switch v := value.(type) {
+	case nil:
+	case []interface{}:
+		items = v
+	default:
+		return nil, fmt.Errorf("%s() of %s", e.name, typeName(value))
+	}
+
+	scope := make(map[string]interface{}, len(env)+1)
+	for k, v := range env {
+		scope[k] = v
+	}
+
+	want := e.name == "any"
+	for _, item := range items {
+		scope["it"] = item
+		result, err := e.args[1].eval(scope)
+		if err != nil {
+			return nil, err
+		}
+		b, err := truth(result)
+		if err != nil {
+			return nil, err
+		}
+		if b == want {
+			return want, nil
+		}
+	}
+	return !want, nil
+}
+
+// contains reports whether collection contains item: an element of a list,
+// a key of an object or a substring of a string.
+func contains(collection, item interface{}) (bool, error) {
+	switch c := collection.(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, element := range c {
+			if reflect.DeepEqual(element, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := item.(string)
+		if !ok {
+			return false, nil
+		}
+		_, found := c[key]
+		return found, nil
+	case string:
+		s, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot look for %s in a string", typeName(item))
+		}
+		return strings.Contains(c, s), nil
+	}
+	return false, fmt.Errorf("cannot look for a value in %s", typeName(collection))
+}
+
+// truth converts a value to a condition; null counts as false.
+func truth(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	}
+	return false, fmt.Errorf("expected a boolean, got %s", typeName(value))
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// typeName names the type of a value in error messages.
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "a list"
+	case map[string]interface{}:
+		return "an object"
+	}
+	return fmt.Sprintf("%T", value)
+}
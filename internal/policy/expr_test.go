@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testEnv(t *testing.T) map[string]interface{} {
+	t.Helper()
+
+	var after interface{}
+	err := json.Unmarshal([]byte(`{
+		"instance_type": "t3.micro",
+		"tags": {"Name": "web", "cost-center": "42"},
+		"ingress": [
+			{"from_port": 443, "to_port": 443, "cidr_blocks": ["0.0.0.0/0"]},
+			{"from_port": 22, "to_port": 22, "cidr_blocks": ["10.0.0.0/8"]}
+		]
+	}`), &after)
+	if err != nil {
+		t.Fatalf("decoding test values: %v", err)
+	}
+	return map[string]interface{}{
+		"after":   after,
+		"address": "module.app.aws_instance.web",
+		"type":    "aws_instance",
+		"actions": []interface{}{"create"},
+	}
+}
+
+func TestExpressionEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`type == "aws_instance"`, true},
+		{`type != "aws_instance"`, false},
+		{`after.tags.Name == "web"`, true},
+		{`after.tags.owner != null`, false},
+		{`after.tags["cost-center"] == "42"`, true},
+		{`after.ingress[1].from_port == 22`, true},
+		{`after.ingress[5].from_port == 22`, false},
+		{`after.missing.deeply.nested == null`, true},
+		{`"create" in actions`, true},
+		{`"owner" in after.tags`, false},
+		{`contains(after.tags, "Name")`, true},
+		{`length(after.ingress) >= 2`, true},
+		{`startswith(address, "module.app.")`, true},
+		{`endswith(type, "_instance") && !startswith(type, "google_")`, true},
+		{`matches(after.instance_type, "^t[23]\\.")`, true},
+		{`lower("ABC") == "abc"`, true},
+		{`after.instance_type in ["t3.micro", "t3.small"]`, true},
+		{`any(after.ingress, it.from_port <= 22 && it.to_port >= 22)`, true},
+		{`any(after.ingress, it.from_port <= 22 && it.to_port >= 22 && contains(it.cidr_blocks, "0.0.0.0/0"))`, false},
+		{`all(after.ingress, length(it.cidr_blocks) == 1)`, true},
+		{`all(after.nothing, false)`, true},
+		{`after.unknown > 3`, false},
+		{`(false || true) && !(1 > 2)`, true},
+		{`-1 < 0`, true},
+	}
+
+	env := testEnv(t)
+	for _, tt := range tests {
+		expr, err := Compile(tt.source)
+		if err != nil {
+			t.Errorf("Compile(%s) returned error: %v", tt.source, err)
+			continue
+		}
+		got, err := expr.EvalBool(env)
+		if err != nil {
+			t.Errorf("%s: evaluation error: %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestExpressionEvalErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr string
+	}{
+		{`after.instance_type > 3`, "cannot compare string with a number"},
+		{`after.tags && true`, "expected a boolean, got an object"},
+		{`length(42)`, "length() of a number"},
+		{`any(after.tags, true)`, "any() of an object"},
+	}
+
+	env := testEnv(t)
+	for _, tt := range tests {
+		expr, err := Compile(tt.source)
+		if err != nil {
+			t.Errorf("Compile(%s) returned error: %v", tt.source, err)
+			continue
+		}
+		if _, err := expr.EvalBool(env); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want %q", tt.source, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr string
+	}{
+		{`after.tags.owner ==`, "unexpected \"end of expression\""},
+		{`owner != null`, "unknown name \"owner\""},
+		{`it.from_port == 22`, "unknown name \"it\""},
+		{`size(after.tags) > 0`, "unknown function \"size\""},
+		{`contains(after.tags)`, "contains() takes 2 arguments, got 1"},
+		{`matches(type, "(")`, "invalid pattern"},
+		{`"unterminated`, "unterminated string"},
+		{`type = "x"`, "unexpected character '='"},
+		{`type == "a" "b"`, "unexpected \"\\\"b\\\"\""},
+	}
+
+	for _, tt := range tests {
+		_, err := Compile(tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Compile(%s) error = %v, want %q", tt.source, err, tt.wantErr)
+		}
+	}
+}
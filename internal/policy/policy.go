@@ -0,0 +1,60 @@
+// Package policy checks plans against the policy configured by the user:
+// resources that must not be destroyed and rules every change must satisfy.
+package policy
+
+import (
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+)
+
+// Policy combines the protected resources and the rules of the configuration.
+// A nil Policy allows everything.
+type Policy struct {
+	protection *Protection
+	rules      *RuleSet
+}
+
+// New creates the policy described by the configuration.
+// It returns an error if a rule does not compile.
+func New(cfg config.PolicyConfig) (*Policy, error) {
+	rules, err := NewRuleSet(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{protection: NewProtection(cfg), rules: rules}, nil
+}
+
+// Report is the outcome of checking a plan against the policy.
+type Report struct {
+	Violations []Violation `json:"protected_violations,omitempty"`
+	Failures   []Failure   `json:"policy_failures,omitempty"`
+	Rules      int         `json:"-"` // Number of rules checked
+}
+
+// Check checks a plan against the policy.
+func (p *Policy) Check(plan *planjson.Plan) *Report {
+	if p == nil {
+		return &Report{}
+	}
+	return &Report{
+		Violations: p.protection.Check(plan),
+		Failures:   p.rules.Check(plan),
+		Rules:      p.rules.Len(),
+	}
+}
+
+// Errors returns the failures of rules with error severity.
+func (r *Report) Errors() []Failure {
+	var errors []Failure
+	for _, failure := range r.Failures {
+		if failure.Severity == config.SeverityError {
+			errors = append(errors, failure)
+		}
+	}
+	return errors
+}
+
+// Blocking reports whether the plan needs an override to be applied.
+func (r *Report) Blocking() bool {
+	return len(r.Violations) > 0 || len(r.Errors()) > 0
+}
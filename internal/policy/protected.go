@@ -0,0 +1,102 @@
+package policy
+
+import (
+	"regexp"
+	"strings"
+
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+)
+
+// Violation is a protected resource that a plan would destroy or replace.
+type Violation struct {
+	Address string `json:"address"`
+	Action  string `json:"action"` // "delete" or "replace"
+	Rule    string `json:"rule"`   // The glob or resource type protecting the resource
+}
+
+// Protection holds the protected resource rules from the configuration.
+// A nil Protection protects nothing.
+type Protection struct {
+	globs []protectedGlob
+	types []string
+}
+
+// protectedGlob is an address glob along with its compiled form.
+type protectedGlob struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// NewProtection creates a Protection from the policy configuration.
+func NewProtection(cfg config.PolicyConfig) *Protection {
+	p := &Protection{types: cfg.ProtectedTypes}
+	for _, pattern := range cfg.Protected {
+		p.globs = append(p.globs, protectedGlob{pattern: pattern, re: compileGlob(pattern)})
+	}
+	return p
+}
+
+// IsEmpty reports whether no resource is protected.
+func (p *Protection) IsEmpty() bool {
+	return p == nil || (len(p.globs) == 0 && len(p.types) == 0)
+}
+
+// Check returns the protected resources the plan destroys or replaces, in plan order.
+func (p *Protection) Check(plan *planjson.Plan) []Violation {
+	if p.IsEmpty() {
+		return nil
+	}
+
+	var violations []Violation
+	for _, change := range plan.ResourceChanges {
+		if change.Mode == "data" || !change.Change.Actions.Contains("delete") {
+			continue
+		}
+		rule, ok := p.match(change)
+		if !ok {
+			continue
+		}
+		action := "delete"
+		if change.Change.Actions.Contains("create") {
+			action = "replace"
+		}
+		violations = append(violations, Violation{Address: change.Address, Action: action, Rule: rule})
+	}
+	return violations
+}
+
+// match returns the first rule protecting the resource.
+func (p *Protection) match(change planjson.ResourceChange) (string, bool) {
+	for _, resourceType := range p.types {
+		if change.Type == resourceType {
+			return resourceType, true
+		}
+	}
+	for _, glob := range p.globs {
+		if glob.re.MatchString(change.Address) {
+			return glob.pattern, true
+		}
+	}
+	return "", false
+}
+
+// compileGlob converts an address glob to a regular expression matching whole addresses.
+// "*" matches any sequence of characters and "?" any single character; everything
+// else, including the brackets and quotes of instance keys, matches literally.
+func compileGlob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
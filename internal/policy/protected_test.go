@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func loadPlan(t *testing.T, fixture string) *planjson.Plan {
+	t.Helper()
+
+	plan, err := planjson.Parse(testutil.PlanFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("parsing %s: %v", fixture, err)
+	}
+	return plan
+}
+
+func TestProtectionCheck(t *testing.T) {
+	plan := loadPlan(t, "replace")
+
+	tests := []struct {
+		name   string
+		policy config.PolicyConfig
+		want   []Violation
+	}{
+		{
+			name:   "no rules",
+			policy: config.PolicyConfig{},
+			want:   nil,
+		},
+		{
+			name:   "address glob",
+			policy: config.PolicyConfig{Protected: []string{"aws_iam_role.*"}},
+			want:   []Violation{{Address: "aws_iam_role.legacy", Action: "delete", Rule: "aws_iam_role.*"}},
+		},
+		{
+			name:   "resource type",
+			policy: config.PolicyConfig{ProtectedTypes: []string{"aws_db_instance"}},
+			want:   []Violation{{Address: "aws_db_instance.main", Action: "replace", Rule: "aws_db_instance"}},
+		},
+		{
+			name:   "updates are allowed",
+			policy: config.PolicyConfig{Protected: []string{"aws_s3_bucket.logs"}},
+			want:   nil,
+		},
+		{
+			name:   "glob matching everything",
+			policy: config.PolicyConfig{Protected: []string{"*"}},
+			want: []Violation{
+				{Address: "aws_db_instance.main", Action: "replace", Rule: "*"},
+				{Address: "aws_iam_role.legacy", Action: "delete", Rule: "*"},
+				{Address: "aws_instance.cache", Action: "replace", Rule: "*"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewProtection(tt.policy).Check(plan); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNilProtection(t *testing.T) {
+	var p *Protection
+	if !p.IsEmpty() || p.Check(loadPlan(t, "replace")) != nil {
+		t.Error("a nil Protection should protect nothing")
+	}
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		address string
+		want    bool
+	}{
+		{"module.prod.*", "module.prod.aws_instance.web", true},
+		{"module.prod.*", "module.production.aws_instance.web", false},
+		{"aws_db_instance.*", "aws_db_instance.main[0]", true},
+		{"aws_db_instance.*", "module.app.aws_db_instance.main", false},
+		{`module.app["eu"].*`, `module.app["eu"].aws_instance.web`, true},
+		{"aws_instance.web[?]", "aws_instance.web[1]", true},
+		{"aws_instance.web", "aws_instance.web2", false},
+	}
+	for _, tt := range tests {
+		if got := compileGlob(tt.pattern).MatchString(tt.address); got != tt.want {
+			t.Errorf("glob %q matching %q = %v, want %v", tt.pattern, tt.address, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"fmt"
+
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+)
+
+// Rule is a compiled policy rule.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    string
+	When        *Expression // nil when the rule applies to every resource
+	Condition   *Expression
+}
+
+// Failure is a resource that does not satisfy a rule.
+type Failure struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description,omitempty"`
+	Severity    string `json:"severity"`
+	Address     string `json:"address"`
+	Error       string `json:"error,omitempty"` // Set when the rule could not be evaluated
+}
+
+// Message describes the failure for display.
+func (f Failure) Message() string {
+	switch {
+	case f.Error != "":
+		return "could not be evaluated: " + f.Error
+	case f.Description != "":
+		return f.Description
+	}
+	return "condition is false"
+}
+
+// RuleSet is the list of rules from the configuration.
+type RuleSet struct {
+	rules []*Rule
+}
+
+// NewRuleSet compiles the configured rules.
+func NewRuleSet(configs []config.RuleConfig) (*RuleSet, error) {
+	rs := &RuleSet{}
+	for i, cfg := range configs {
+		name := cfg.Name
+		if name == "" {
+			name = fmt.Sprintf("rule %d", i+1)
+		}
+
+		severity := cfg.Severity
+		if severity == "" {
+			severity = config.SeverityError
+		}
+		if severity != config.SeverityError && severity != config.SeverityWarning {
+			return nil, fmt.Errorf("%s: unknown severity %q (expected %q or %q)", name, severity, config.SeverityError, config.SeverityWarning)
+		}
+
+		if cfg.Condition == "" {
+			return nil, fmt.Errorf("%s: condition is required", name)
+		}
+		condition, err := Compile(cfg.Condition)
+		if err != nil {
+			return nil, fmt.Errorf("%s: condition: %w", name, err)
+		}
+
+		rule := &Rule{Name: name, Description: cfg.Description, Severity: severity, Condition: condition}
+		if cfg.When != "" {
+			if rule.When, err = Compile(cfg.When); err != nil {
+				return nil, fmt.Errorf("%s: when: %w", name, err)
+			}
+		}
+		rs.rules = append(rs.rules, rule)
+	}
+	return rs, nil
+}
+
+// Len returns the number of rules.
+func (rs *RuleSet) Len() int {
+	if rs == nil {
+		return 0
+	}
+	return len(rs.rules)
+}
+
+// Check evaluates every rule against the resources the plan creates or updates.
+// A rule that cannot be evaluated for a resource fails for it.
+func (rs *RuleSet) Check(plan *planjson.Plan) []Failure {
+	if rs.Len() == 0 {
+		return nil
+	}
+
+	var failures []Failure
+	for _, change := range plan.ResourceChanges {
+		if change.Mode == "data" || change.Change.Actions.IsNoOp() || change.Change.After == nil {
+			continue
+		}
+
+		env := ruleEnv(change)
+		for _, rule := range rs.rules {
+			failure := Failure{Rule: rule.Name, Description: rule.Description, Severity: rule.Severity, Address: change.Address}
+
+			if rule.When != nil {
+				applies, err := rule.When.EvalBool(env)
+				if err != nil {
+					failure.Error = err.Error()
+					failures = append(failures, failure)
+					continue
+				}
+				if !applies {
+					continue
+				}
+			}
+
+			ok, err := rule.Condition.EvalBool(env)
+			if err != nil {
+				failure.Error = err.Error()
+			}
+			if !ok {
+				failures = append(failures, failure)
+			}
+		}
+	}
+	return failures
+}
+
+// ruleEnv returns the names available to rule expressions for a resource change.
+func ruleEnv(change planjson.ResourceChange) map[string]interface{} {
+	actions := make([]interface{}, len(change.Change.Actions))
+	for i, action := range change.Change.Actions {
+		actions[i] = action
+	}
+	return map[string]interface{}{
+		"after":   change.Change.After,
+		"before":  change.Change.Before,
+		"address": change.Address,
+		"type":    change.Type,
+		"name":    change.Name,
+		"module":  change.ModuleAddress,
+		"mode":    change.Mode,
+		"actions": actions,
+	}
+}
@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/config"
+)
+
+func TestRuleSetCheck(t *testing.T) {
+	rules, err := NewRuleSet([]config.RuleConfig{
+		{
+			Name:        "no-public-ssh",
+			Description: "SSH must not be open to the internet",
+			When:        `type == "aws_security_group"`,
+			Condition:   `!any(after.ingress, it.from_port <= 22 && it.to_port >= 22 && contains(it.cidr_blocks, "0.0.0.0/0"))`,
+		},
+		{
+			Name:      "https-only",
+			When:      `type == "aws_security_group"`,
+			Condition: `all(after.ingress, it.from_port == 443)`,
+		},
+		{
+			Name:      "owner-tag",
+			Severity:  config.SeverityWarning,
+			Condition: `after.tags.owner != null`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRuleSet returned error: %v", err)
+	}
+
+	want := []Failure{
+		{Rule: "owner-tag", Severity: "warning", Address: "aws_instance.web"},
+		{Rule: "https-only", Severity: "error", Address: "aws_security_group.web"},
+		{Rule: "owner-tag", Severity: "warning", Address: "aws_security_group.web"},
+	}
+	if got := rules.Check(loadPlan(t, "create")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRuleSetEvaluationErrorFails(t *testing.T) {
+	rules, err := NewRuleSet([]config.RuleConfig{{Name: "bad", When: `type == "aws_instance"`, Condition: `after.tags > 1`}})
+	if err != nil {
+		t.Fatalf("NewRuleSet returned error: %v", err)
+	}
+
+	failures := rules.Check(loadPlan(t, "create"))
+	if len(failures) != 1 || failures[0].Error == "" {
+		t.Fatalf("Check() = %+v, want one failure with an evaluation error", failures)
+	}
+	if got := failures[0].Message(); got != "could not be evaluated: cannot compare an object" {
+		t.Errorf("Message() = %q", got)
+	}
+}
+
+func TestNewRuleSetErrors(t *testing.T) {
+	tests := []struct {
+		rule    config.RuleConfig
+		wantErr string
+	}{
+		{config.RuleConfig{Name: "empty"}, "empty: condition is required"},
+		{config.RuleConfig{Name: "sev", Severity: "fatal", Condition: "true"}, `sev: unknown severity "fatal"`},
+		{config.RuleConfig{Condition: "bogus"}, `rule 1: condition: unknown name "bogus"`},
+		{config.RuleConfig{Name: "when", When: "(", Condition: "true"}, "when: when:"},
+	}
+	for _, tt := range tests {
+		_, err := NewRuleSet([]config.RuleConfig{tt.rule})
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("NewRuleSet(%+v) error = %v, want %q", tt.rule, err, tt.wantErr)
+		}
+	}
+}
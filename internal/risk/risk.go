@@ -0,0 +1,192 @@
+// Package risk scores how dangerous each change of a plan is, so that the
+// riskiest changes are reviewed first and confirmed separately.
+package risk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+)
+
+// Risk factors, the keys of config.RiskConfig.Weights.
+const (
+	FactorReplace      = "replace"
+	FactorDelete       = "delete"
+	FactorUpdate       = "update"
+	FactorStateful     = "stateful"
+	FactorCannotUpdate = "cannot_update"
+	FactorReplacePath  = "replace_path"
+)
+
+var factors = []string{FactorReplace, FactorDelete, FactorUpdate, FactorStateful, FactorCannotUpdate, FactorReplacePath}
+
+// Score is the risk score of one resource change, with the factors that make it up.
+type Score struct {
+	Address string   `json:"address"`
+	Action  string   `json:"action"`
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons"`
+}
+
+// Scorer computes risk scores from configured weights.
+type Scorer struct {
+	weights      map[string]int
+	stateful     map[string]bool
+	highRisk     int
+	confirmAbove int
+}
+
+// NewScorer creates a scorer from the risk configuration. Weights, stateful
+// types and thresholds that are not configured take their default values.
+func NewScorer(cfg config.RiskConfig) (*Scorer, error) {
+	defaults := config.DefaultConfig().Risk
+
+	weights := make(map[string]int, len(factors))
+	for _, factor := range factors {
+		weights[factor] = defaults.Weights[factor]
+	}
+	for factor, weight := range cfg.Weights {
+		if _, ok := weights[factor]; !ok {
+			return nil, fmt.Errorf("unknown risk factor %q, expected one of %s", factor, strings.Join(factors, ", "))
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("weight of risk factor %q must not be negative", factor)
+		}
+		weights[factor] = weight
+	}
+
+	statefulTypes := cfg.StatefulTypes
+	if statefulTypes == nil {
+		statefulTypes = defaults.StatefulTypes
+	}
+	stateful := make(map[string]bool, len(statefulTypes))
+	for _, resourceType := range statefulTypes {
+		stateful[resourceType] = true
+	}
+
+	scorer := &Scorer{
+		weights:      weights,
+		stateful:     stateful,
+		highRisk:     cfg.HighRisk,
+		confirmAbove: cfg.ConfirmAbove,
+	}
+	if scorer.highRisk == 0 {
+		scorer.highRisk = defaults.HighRisk
+	}
+	if scorer.confirmAbove == 0 {
+		scorer.confirmAbove = defaults.ConfirmAbove
+	}
+	return scorer, nil
+}
+
+// Assessment is the risk of every scored change of a plan.
+type Assessment struct {
+	Scores       []Score `json:"scores"` // Changes with a positive score, riskiest first
+	HighRisk     int     `json:"high_risk"`
+	ConfirmAbove int     `json:"confirm_above"`
+}
+
+// Assess scores the changes of a plan. A nil scorer assesses nothing.
+func (s *Scorer) Assess(plan *planjson.Plan) *Assessment {
+	if s == nil {
+		return nil
+	}
+
+	assessment := &Assessment{Scores: []Score{}, HighRisk: s.highRisk, ConfirmAbove: s.confirmAbove}
+	for _, change := range plan.ResourceChanges {
+		if change.Mode == "data" || change.Change.Actions.IsNoOp() {
+			continue
+		}
+		if score := s.score(change); score.Score > 0 {
+			assessment.Scores = append(assessment.Scores, score)
+		}
+	}
+
+	sort.SliceStable(assessment.Scores, func(i, j int) bool {
+		a, b := assessment.Scores[i], assessment.Scores[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Address < b.Address
+	})
+	return assessment
+}
+
+// score adds up the weights of the risk factors of a change.
+func (s *Scorer) score(change planjson.ResourceChange) Score {
+	actions := change.Change.Actions
+	score := Score{Address: change.Address, Action: actions.Summary()}
+	add := func(factor, reason string) {
+		if weight := s.weights[factor]; weight > 0 {
+			score.Score += weight
+			score.Reasons = append(score.Reasons, reason)
+		}
+	}
+
+	switch score.Action {
+	case "replace":
+		add(FactorReplace, "replaced")
+	case "destroy":
+		add(FactorDelete, "destroyed")
+	case "update":
+		add(FactorUpdate, "updated in-place")
+	}
+	if actions.Contains("delete") && s.stateful[change.Type] {
+		add(FactorStateful, "stateful type "+change.Type)
+	}
+	if change.ActionReason == "replace_because_cannot_update" {
+		add(FactorCannotUpdate, planjson.ActionReasonDescription(change.ActionReason))
+	}
+	for _, path := range change.Change.ReplacePaths {
+		add(FactorReplacePath, "replacement forced by "+formatPath(path))
+	}
+	return score
+}
+
+// formatPath formats a replace path, e.g. ["ebs_block_device", 0, "size"] as ebs_block_device[0].size.
+func formatPath(path []interface{}) string {
+	var sb strings.Builder
+	for _, step := range path {
+		switch step := step.(type) {
+		case string:
+			if sb.Len() > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(step)
+		default:
+			// JSON numbers are decoded as float64
+			fmt.Fprintf(&sb, "[%v]", step)
+		}
+	}
+	return sb.String()
+}
+
+// High returns the changes scored at or above the high risk threshold.
+func (a *Assessment) High() []Score {
+	if a == nil {
+		return nil
+	}
+	return a.atLeast(a.HighRisk)
+}
+
+// NeedsConfirmation returns the changes whose score requires an extra confirmation.
+func (a *Assessment) NeedsConfirmation() []Score {
+	if a == nil || a.ConfirmAbove < 0 {
+		return nil
+	}
+	return a.atLeast(a.ConfirmAbove)
+}
+
+// IsHigh reports whether a score reaches the high risk threshold.
+func (a *Assessment) IsHigh(score Score) bool {
+	return a != nil && score.Score >= a.HighRisk
+}
+
+// atLeast returns the leading scores reaching the threshold; scores are sorted riskiest first.
+func (a *Assessment) atLeast(threshold int) []Score {
+	n := sort.Search(len(a.Scores), func(i int) bool { return a.Scores[i].Score < threshold })
+	return a.Scores[:n]
+}
@@ -0,0 +1,110 @@
+package risk
+
+import (
+	"reflect"
+	"testing"
+
+	"tfapp/internal/config"
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func loadPlan(t *testing.T, fixture string) *planjson.Plan {
+	t.Helper()
+
+	plan, err := planjson.Parse(testutil.PlanFixture(t, fixture))
+	if err != nil {
+		t.Fatalf("parsing %s: %v", fixture, err)
+	}
+	return plan
+}
+
+func newScorer(t *testing.T, cfg config.RiskConfig) *Scorer {
+	t.Helper()
+
+	scorer, err := NewScorer(cfg)
+	if err != nil {
+		t.Fatalf("NewScorer: %v", err)
+	}
+	return scorer
+}
+
+func TestAssessDefaults(t *testing.T) {
+	assessment := newScorer(t, config.RiskConfig{}).Assess(loadPlan(t, "replace"))
+
+	want := []Score{
+		{
+			Address: "aws_db_instance.main",
+			Action:  "replace",
+			Score:   110,
+			Reasons: []string{"replaced", "stateful type aws_db_instance", "cannot be updated in-place", "replacement forced by engine_version"},
+		},
+		{Address: "aws_instance.cache", Action: "replace", Score: 40, Reasons: []string{"replaced"}},
+		{Address: "aws_iam_role.legacy", Action: "destroy", Score: 30, Reasons: []string{"destroyed"}},
+		{Address: "aws_s3_bucket.logs", Action: "update", Score: 5, Reasons: []string{"updated in-place"}},
+	}
+	if !reflect.DeepEqual(assessment.Scores, want) {
+		t.Errorf("Scores =\n%+v\nwant\n%+v", assessment.Scores, want)
+	}
+
+	if high := assessment.High(); len(high) != 1 || high[0].Address != "aws_db_instance.main" {
+		t.Errorf("High() = %+v, want only the database", high)
+	}
+	if confirm := assessment.NeedsConfirmation(); len(confirm) != 1 {
+		t.Errorf("NeedsConfirmation() = %+v, want only the database", confirm)
+	}
+}
+
+func TestAssessConfiguredWeights(t *testing.T) {
+	scorer := newScorer(t, config.RiskConfig{
+		Weights:       map[string]int{"replace": 0, "delete": 60, "update": 0},
+		StatefulTypes: []string{"aws_iam_role"},
+		HighRisk:      90,
+		ConfirmAbove:  -1,
+	})
+	assessment := scorer.Assess(loadPlan(t, "replace"))
+
+	got := make(map[string]int)
+	for _, score := range assessment.Scores {
+		got[score.Address] = score.Score
+	}
+	want := map[string]int{
+		"aws_db_instance.main": 30, // No longer stateful, but still forced by an attribute
+		"aws_iam_role.legacy":  100,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("scores = %v, want %v", got, want)
+	}
+	if high := assessment.High(); len(high) != 1 || high[0].Address != "aws_iam_role.legacy" {
+		t.Errorf("High() = %+v, want only the role", high)
+	}
+	if confirm := assessment.NeedsConfirmation(); confirm != nil {
+		t.Errorf("NeedsConfirmation() = %+v, want nil when disabled", confirm)
+	}
+}
+
+func TestNewScorerErrors(t *testing.T) {
+	for _, weights := range []map[string]int{
+		{"explode": 10},
+		{"delete": -5},
+	} {
+		if _, err := NewScorer(config.RiskConfig{Weights: weights}); err == nil {
+			t.Errorf("NewScorer(%v) succeeded, want an error", weights)
+		}
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	path := []interface{}{"ebs_block_device", float64(0), "volume_size"}
+	if got := formatPath(path); got != "ebs_block_device[0].volume_size" {
+		t.Errorf("formatPath() = %q", got)
+	}
+}
+
+func TestNilAssessment(t *testing.T) {
+	var scorer *Scorer
+	assessment := scorer.Assess(loadPlan(t, "replace"))
+	if assessment != nil || assessment.High() != nil || assessment.NeedsConfirmation() != nil {
+		t.Error("a nil scorer should assess nothing")
+	}
+}
@@ -0,0 +1,226 @@
+package stack
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest declares the dependencies between the stacks under a directory.
+// Stacks are named by their path relative to that directory, e.g.:
+//
+//	stacks:
+//	  envs/prod/app:
+//	    depends_on: [envs/prod/network, global/iam]
+type Manifest struct {
+	Stacks map[string]ManifestStack `yaml:"stacks"`
+}
+
+// ManifestStack is the declaration of one stack in a manifest.
+type ManifestStack struct {
+	DependsOn []string `yaml:"depends_on"` // Stacks applied before this one
+}
+
+// LoadManifest reads a stack manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading stack manifest: %w", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("error parsing stack manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// Graph is the dependency graph of a set of stacks.
+type Graph struct {
+	stacks    []Stack
+	dependsOn [][]int // Indices of the stacks each stack depends on
+}
+
+// NewGraph builds the dependency graph of the stacks from a manifest, which
+// may be nil when the stacks have no dependencies. Every stack named in the
+// manifest must be one of the stacks, and the dependencies must not form a cycle.
+func NewGraph(stacks []Stack, manifest *Manifest) (*Graph, error) {
+	index := make(map[string]int, len(stacks))
+	for i, stack := range stacks {
+		index[stack.Name] = i
+	}
+	lookup := func(name string) (int, error) {
+		i, ok := index[path.Clean(strings.TrimSuffix(name, "/"))]
+		if !ok {
+			return 0, fmt.Errorf("stack manifest names %q, which is not a stack", name)
+		}
+		return i, nil
+	}
+
+	g := &Graph{stacks: stacks, dependsOn: make([][]int, len(stacks))}
+	if manifest == nil {
+		return g, nil
+	}
+
+	// Sorted so that errors do not depend on the map order
+	names := make([]string, 0, len(manifest.Stacks))
+	for name := range manifest.Stacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, dependency := range manifest.Stacks[name].DependsOn {
+			j, err := lookup(dependency)
+			if err != nil {
+				return nil, err
+			}
+			if j == i {
+				return nil, fmt.Errorf("stack %s depends on itself", stacks[i].Name)
+			}
+			g.dependsOn[i] = append(g.dependsOn[i], j)
+		}
+		sort.Ints(g.dependsOn[i])
+	}
+
+	if cycle := g.cycle(); cycle != nil {
+		return nil, fmt.Errorf("stack dependencies form a cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return g, nil
+}
+
+// cycle returns the names of the stacks on a dependency cycle, starting and
+// ending with the same stack, or nil if there is none.
+func (g *Graph) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(g.stacks))
+	var trail []int // The stacks being visited, each depending on the one before
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		trail = append(trail, i)
+		for _, j := range g.dependsOn[i] {
+			switch state[j] {
+			case visiting:
+				// j is on the trail: the cycle runs from it to i and back
+				var names []string
+				for k := len(trail) - 1; k >= 0; k-- {
+					names = append(names, g.stacks[trail[k]].Name)
+					if trail[k] == j {
+						break
+					}
+				}
+				for l, r := 0, len(names)-1; l < r; l, r = l+1, r-1 {
+					names[l], names[r] = names[r], names[l]
+				}
+				return append(names, g.stacks[j].Name)
+			case unvisited:
+				if cycle := visit(j); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		trail = trail[:len(trail)-1]
+		state[i] = visited
+		return nil
+	}
+
+	for i := range g.stacks {
+		if state[i] == unvisited {
+			if cycle := visit(i); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// HasDependencies reports whether any stack depends on another.
+func (g *Graph) HasDependencies() bool {
+	for _, dependencies := range g.dependsOn {
+		if len(dependencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// DependsOn returns the indices of the stacks stack i directly depends on.
+func (g *Graph) DependsOn(i int) []int {
+	return g.dependsOn[i]
+}
+
+// Dependents returns the indices of the stacks that depend on stack i,
+// directly or through other stacks, in order.
+func (g *Graph) Dependents(i int) []int {
+	dependent := make([]bool, len(g.stacks))
+	var mark func(i int)
+	mark = func(i int) {
+		for j, dependencies := range g.dependsOn {
+			if !dependent[j] && slices.Contains(dependencies, i) {
+				dependent[j] = true
+				mark(j)
+			}
+		}
+	}
+	mark(i)
+
+	var dependents []int
+	for j, ok := range dependent {
+		if ok {
+			dependents = append(dependents, j)
+		}
+	}
+	return dependents
+}
+
+// Waves orders the stacks for which include returns true into waves: each
+// stack comes in a later wave than every stack it depends on, directly or
+// through other stacks, and as early as that allows. Stacks within a wave are
+// in order.
+func (g *Graph) Waves(include func(i int) bool) [][]int {
+	// The depth of a stack is the length of its longest chain of dependencies
+	depth := make([]int, len(g.stacks))
+	done := make([]bool, len(g.stacks))
+	var measure func(i int) int
+	measure = func(i int) int {
+		if !done[i] {
+			for _, j := range g.dependsOn[i] {
+				depth[i] = max(depth[i], measure(j)+1)
+			}
+			done[i] = true
+		}
+		return depth[i]
+	}
+
+	byDepth := make(map[int][]int)
+	for i := range g.stacks {
+		if include(i) {
+			byDepth[measure(i)] = append(byDepth[measure(i)], i)
+		}
+	}
+	depths := make([]int, 0, len(byDepth))
+	for d := range byDepth {
+		depths = append(depths, d)
+	}
+	sort.Ints(depths)
+
+	waves := make([][]int, 0, len(depths))
+	for _, d := range depths {
+		waves = append(waves, byDepth[d])
+	}
+	return waves
+}
@@ -0,0 +1,112 @@
+package stack
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testStacks returns stacks with the given names.
+func testStacks(names ...string) []Stack {
+	stacks := make([]Stack, len(names))
+	for i, name := range names {
+		stacks[i] = Stack{Name: name, Dir: "/" + name}
+	}
+	return stacks
+}
+
+func TestLoadManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tfapp-stacks.yaml")
+	content := "stacks:\n  app:\n    depends_on: [network, iam]\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest returned error: %v", err)
+	}
+	if got, want := manifest.Stacks["app"].DependsOn, []string{"network", "iam"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("app depends on %q, want %q", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("stacks: [unclosed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadManifest(path); err == nil {
+		t.Error("expected an error for an invalid manifest")
+	}
+}
+
+func TestGraphWaves(t *testing.T) {
+	stacks := testStacks("app", "db", "iam", "network", "tools")
+	manifest := &Manifest{Stacks: map[string]ManifestStack{
+		"app": {DependsOn: []string{"db", "network/"}},
+		"db":  {DependsOn: []string{"network"}},
+	}}
+	g, err := NewGraph(stacks, manifest)
+	if err != nil {
+		t.Fatalf("NewGraph returned error: %v", err)
+	}
+	if !g.HasDependencies() {
+		t.Error("HasDependencies() = false")
+	}
+
+	all := func(int) bool { return true }
+	if got, want := g.Waves(all), [][]int{{2, 3, 4}, {1}, {0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+
+	// Stacks left out do not leave empty waves, and still order the others
+	withoutDB := func(i int) bool { return i != 1 }
+	if got, want := g.Waves(withoutDB), [][]int{{2, 3, 4}, {0}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() without db = %v, want %v", got, want)
+	}
+
+	if got, want := g.Dependents(3), []int{0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dependents(network) = %v, want %v", got, want)
+	}
+	if got := g.Dependents(0); got != nil {
+		t.Errorf("Dependents(app) = %v, want none", got)
+	}
+}
+
+func TestGraphWithoutManifest(t *testing.T) {
+	g, err := NewGraph(testStacks("a", "b"), nil)
+	if err != nil {
+		t.Fatalf("NewGraph returned error: %v", err)
+	}
+	if g.HasDependencies() {
+		t.Error("HasDependencies() = true without a manifest")
+	}
+	if got, want := g.Waves(func(int) bool { return true }), [][]int{{0, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Waves() = %v, want %v", got, want)
+	}
+}
+
+func TestGraphErrors(t *testing.T) {
+	stacks := testStacks("a", "b", "c")
+	for _, tc := range []struct {
+		name     string
+		manifest map[string]ManifestStack
+		want     string
+	}{
+		{"unknown stack", map[string]ManifestStack{"d": {}}, `names "d"`},
+		{"unknown dependency", map[string]ManifestStack{"a": {DependsOn: []string{"x"}}}, `names "x"`},
+		{"self dependency", map[string]ManifestStack{"a": {DependsOn: []string{"a"}}}, "a depends on itself"},
+		{"cycle", map[string]ManifestStack{
+			"a": {DependsOn: []string{"b"}},
+			"b": {DependsOn: []string{"c"}},
+			"c": {DependsOn: []string{"a"}},
+		}, "cycle: a -> b -> c -> a"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewGraph(stacks, &Manifest{Stacks: tc.manifest})
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("NewGraph error = %v, want one containing %q", err, tc.want)
+			}
+		})
+	}
+}
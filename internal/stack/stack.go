@@ -0,0 +1,111 @@
+// Package stack finds the Terraform root modules under a directory, the stacks
+// tfapp plans and applies together, runs work on them concurrently and orders
+// them by the dependencies declared in a stack manifest.
+package stack
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Stack is a Terraform root module.
+type Stack struct {
+	Name string // Path relative to the directory the stacks were discovered in, e.g. envs/prod
+	Dir  string // Absolute path
+}
+
+// Discover returns the root modules under root, sorted by name. A root module
+// is a directory holding .tf or .tf.json files. Hidden directories, directories
+// named modules and the directories within a root module are skipped, since
+// they hold Terraform's data or child modules rather than stacks.
+func Discover(root string) ([]Stack, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving stack directory: %w", err)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("error reading stack directory: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	var stacks []Stack
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "modules") {
+			return filepath.SkipDir
+		}
+
+		isRoot, err := hasConfiguration(path)
+		if err != nil || !isRoot {
+			return err
+		}
+		name, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		stacks = append(stacks, Stack{Name: filepath.ToSlash(name), Dir: path})
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error discovering stacks: %w", err)
+	}
+
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+	return stacks, nil
+}
+
+// hasConfiguration reports whether a directory holds Terraform configuration files.
+func hasConfiguration(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && (strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ForEach calls fn with the index of each stack, running at most parallelism
+// calls at the same time, and returns once they have all returned. Stacks not
+// started by the time ctx is cancelled are skipped.
+func ForEach(ctx context.Context, stacks []Stack, parallelism int, fn func(ctx context.Context, i int)) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	slots := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i := range stacks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			fn(ctx, i)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,118 @@
+package stack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeFiles creates the given files, with empty content, under dir.
+func writeFiles(t *testing.T, dir string, files ...string) {
+	t.Helper()
+
+	for _, file := range files {
+		path := filepath.Join(dir, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root,
+		"envs/prod/main.tf",
+		"envs/prod/vpc/main.tf", // A local module of envs/prod
+		"envs/prod/.terraform/modules/vpc/main.tf",
+		"envs/dev/main.tf.json",
+		"envs/README.md",
+		"modules/network/main.tf",
+		"global/iam/main.tf",
+		".github/workflows/main.tf",
+	)
+
+	stacks, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+
+	var names []string
+	for _, stack := range stacks {
+		names = append(names, stack.Name)
+		if want := filepath.Join(root, filepath.FromSlash(stack.Name)); stack.Dir != want {
+			t.Errorf("stack %s has directory %s, want %s", stack.Name, stack.Dir, want)
+		}
+	}
+	if want := []string{"envs/dev", "envs/prod", "global/iam"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Discover() = %q, want %q", names, want)
+	}
+}
+
+func TestDiscoverRootModule(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, "main.tf", "envs/prod/main.tf")
+
+	stacks, err := Discover(root)
+	if err != nil {
+		t.Fatalf("Discover returned error: %v", err)
+	}
+	if len(stacks) != 1 || stacks[0].Name != "." {
+		t.Errorf("Discover() = %+v, want the root module only", stacks)
+	}
+}
+
+func TestDiscoverMissingDirectory(t *testing.T) {
+	if _, err := Discover(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Discover of a missing directory returned no error")
+	}
+}
+
+func TestForEachLimitsParallelism(t *testing.T) {
+	stacks := make([]Stack, 10)
+
+	var running, peak atomic.Int32
+	var mu sync.Mutex
+	seen := make(map[int]bool)
+	ForEach(context.Background(), stacks, 3, func(ctx context.Context, i int) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+
+		mu.Lock()
+		seen[i] = true
+		mu.Unlock()
+	})
+
+	if len(seen) != len(stacks) {
+		t.Errorf("ForEach ran %d stacks, want %d", len(seen), len(stacks))
+	}
+	if p := peak.Load(); p > 3 || p < 2 {
+		t.Errorf("ForEach ran %d stacks at the same time, want at most 3", p)
+	}
+}
+
+func TestForEachStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	ForEach(ctx, make([]Stack, 5), 1, func(ctx context.Context, i int) {
+		calls.Add(1)
+		cancel()
+	})
+	if n := calls.Load(); n != 1 {
+		t.Errorf("ForEach ran %d stacks after being cancelled, want 1", n)
+	}
+}
@@ -2,19 +2,32 @@ package terraform
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
+	apperrors "tfapp/internal/errors"
 	"tfapp/internal/models"
 	"tfapp/internal/ui"
+	"tfapp/internal/ui/dashboard"
+	"tfapp/internal/uijson"
+	"tfapp/internal/utils"
 )
 
 // ApplyManager handles Terraform apply operations.
 type ApplyManager struct {
 	executor models.Executor
+	plans    models.PlanService // Loads the plans being applied, to list their resources on the dashboard
+	report   *ApplyReport       // Outcome of the resources of the running apply
+
+	applyCallbacks []ApplyCallback
 }
 
+// ApplyCallback is a function type that gets called once terraform apply has
+// run, with its error, which is an *apperrors.ApplyError if some resources failed
+type ApplyCallback func(err error)
+
 // NewApplyManager creates a new Terraform apply manager.
 func NewApplyManager(executor models.Executor) *ApplyManager {
 	// Register progress callback with the executor if it's a CommandExecutor
@@ -25,21 +38,41 @@ func NewApplyManager(executor models.Executor) *ApplyManager {
 	// Try to register progress callback if the executor supports it
 	if cmdExecutor, ok := executor.(*CommandExecutor); ok {
 		cmdExecutor.RegisterProgressCallback(applyManager.displayProgress)
+		cmdExecutor.RegisterEventCallback(applyManager.recordEvent)
 	}
 
 	return applyManager
 }
 
+// SetPlanService sets the service loading the plans being applied, so that the
+// apply dashboard lists their resources before terraform reaches them.
+func (a *ApplyManager) SetPlanService(plans models.PlanService) {
+	a.plans = plans
+}
+
 // displayProgress outputs progress updates to the user
 func (a *ApplyManager) displayProgress(status string) {
 	fmt.Printf("%s%s%s\n", ui.ColorHighlight, status, ui.ColorReset)
 }
 
+// RegisterApplyCallback registers a callback function to learn the result of
+// every terraform apply, which does not run when the user declines it
+func (a *ApplyManager) RegisterApplyCallback(callback ApplyCallback) {
+	a.applyCallbacks = append(a.applyCallbacks, callback)
+}
+
+// recordEvent records the outcome of a resource from an event of the running apply.
+func (a *ApplyManager) recordEvent(event uijson.Event) {
+	if a.report != nil {
+		a.report.Record(event)
+	}
+}
+
 // Apply executes `terraform apply` with the given plan file.
 // It prompts for confirmation before proceeding.
 func (a *ApplyManager) Apply(ctx interface{}, planFilePath string) error {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Proceed with applying this plan? [yes/No]: ")
+	fmt.Printf("Proceed with applying this plan to workspace %s%s%s? [yes/No]: ", ui.TextBold, WorkspaceOf(a.executor), ui.ColorReset)
 	response, err := reader.ReadString('\n')
 	if err != nil {
 		return fmt.Errorf("error reading input: %w", err)
@@ -47,20 +80,26 @@ func (a *ApplyManager) Apply(ctx interface{}, planFilePath string) error {
 
 	response = strings.ToLower(strings.TrimSpace(response))
 	if response == "yes" {
-		fmt.Printf("%sThis may take several minutes. Progress updates will be displayed.%s\n", ui.ColorInfo, ui.ColorReset)
-
-		if err := a.executor.RunCommand(ctx, []string{"apply", planFilePath}, "Applying terraform plan", false); err != nil {
-			return fmt.Errorf("error executing terraform apply: %w", err)
-		}
-		fmt.Printf("%s%sTerraform apply completed successfully!%s\n",
-			ui.ColorSuccess, ui.TextBold, ui.ColorReset)
-		return nil
+		return a.ApplyPlan(ctx, planFilePath)
 	}
 
 	fmt.Printf("%sApply aborted.%s\n", ui.ColorWarning, ui.ColorReset)
 	return nil
 }
 
+// ApplyPlan executes `terraform apply` with the given plan file without asking,
+// for callers that have had the apply confirmed already.
+func (a *ApplyManager) ApplyPlan(ctx interface{}, planFilePath string) error {
+	fmt.Printf("%sThis may take several minutes. Progress updates will be displayed.%s\n", ui.ColorInfo, ui.ColorReset)
+
+	if err := a.runApply(ctx, []string{"apply", planFilePath}, planFilePath, "Applying terraform plan"); err != nil {
+		return fmt.Errorf("error executing terraform apply: %w", err)
+	}
+	fmt.Printf("%s%sTerraform apply completed successfully!%s\n",
+		ui.ColorSuccess, ui.TextBold, ui.ColorReset)
+	return nil
+}
+
 // ApplyTargets applies the plan only to the selected resources.
 // It takes a list of resource targets to apply.
 func (a *ApplyManager) ApplyTargets(ctx interface{}, targets []string) error {
@@ -74,7 +113,7 @@ func (a *ApplyManager) ApplyTargets(ctx interface{}, targets []string) error {
 	}
 
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("Apply to %d selected resources? [yes/No]: ", len(targets))
+	fmt.Printf("Apply to %d selected resources in workspace %s%s%s? [yes/No]: ", len(targets), ui.TextBold, WorkspaceOf(a.executor), ui.ColorReset)
 	response, err := reader.ReadString('\n')
 	if err != nil {
 		return fmt.Errorf("error reading input: %w", err)
@@ -85,7 +124,7 @@ func (a *ApplyManager) ApplyTargets(ctx interface{}, targets []string) error {
 		fmt.Printf("%sStarting targeted terraform apply operation...%s\n", ui.ColorInfo, ui.ColorReset)
 		fmt.Printf("%sThis may take several minutes. Progress updates will be displayed.%s\n", ui.ColorInfo, ui.ColorReset)
 
-		if err := a.executor.RunCommand(ctx, args, "Applying terraform to selected resources", false); err != nil {
+		if err := a.runApply(ctx, args, "", "Applying terraform to selected resources"); err != nil {
 			return fmt.Errorf("error executing targeted terraform apply: %w", err)
 		}
 		fmt.Printf("%s%sTargeted terraform apply completed successfully!%s\n",
@@ -97,6 +136,105 @@ func (a *ApplyManager) ApplyTargets(ctx interface{}, targets []string) error {
 	return nil
 }
 
+// Destroy executes `terraform apply` with a destroy plan file. Because a destroy
+// cannot be undone, the user must type confirmation exactly instead of "yes".
+func (a *ApplyManager) Destroy(ctx interface{}, planFilePath string, confirmation string) error {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Printf("%s%sThis will permanently destroy the resources in this plan.%s\n", ui.ColorError, ui.TextBold, ui.ColorReset)
+	fmt.Printf("Type %s%s%s to confirm: ", ui.TextBold, confirmation, ui.ColorReset)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("error reading input: %w", err)
+	}
+
+	if strings.TrimSpace(response) != confirmation {
+		fmt.Printf("%sDestroy aborted.%s\n", ui.ColorWarning, ui.ColorReset)
+		return nil
+	}
+
+	fmt.Printf("%sThis may take several minutes. Progress updates will be displayed.%s\n", ui.ColorInfo, ui.ColorReset)
+	if err := a.runApply(ctx, []string{"apply", planFilePath}, planFilePath, "Destroying resources"); err != nil {
+		return fmt.Errorf("error executing terraform destroy: %w", err)
+	}
+	fmt.Printf("%s%sTerraform destroy completed successfully!%s\n",
+		ui.ColorSuccess, ui.TextBold, ui.ColorReset)
+	return nil
+}
+
+// runApply runs terraform apply with the given arguments. On a terminal it shows
+// the apply dashboard, driven by the machine-readable output of apply -json,
+// if the binary supports it; otherwise progress updates are printed under a spinner.
+// If some resources failed, it prints which resources were applied and which
+// failed, and returns an *apperrors.ApplyError listing them.
+func (a *ApplyManager) runApply(ctx interface{}, args []string, planFilePath, message string) error {
+	a.report = NewApplyReport()
+	report := a.report
+	defer func() { a.report = nil }()
+
+	err := a.execute(ctx, args, planFilePath, message)
+	if failed := report.Failed(); err != nil && len(failed) > 0 {
+		WriteApplyReport(os.Stdout, report)
+		err = apperrors.NewApplyError(report.Succeeded(), failed, err)
+	}
+
+	for _, callback := range a.applyCallbacks {
+		callback(err)
+	}
+	return err
+}
+
+// execute runs terraform apply in the dashboard or under a spinner.
+func (a *ApplyManager) execute(ctx interface{}, args []string, planFilePath, message string) error {
+	ctxTyped, ok := ctx.(context.Context)
+	if !ok || !utils.IsTerminal(os.Stdout) || !Supports(ctx, a.executor, FeatureApplyJSON) {
+		return a.executor.RunCommand(ctx, args, message, false)
+	}
+
+	if planFilePath == "" {
+		// Terraform cannot ask for approval with -json; the user has confirmed already
+		args = append(args, "-auto-approve")
+	}
+	return dashboard.Run(ctxTyped, message, a.plannedResources(ctx, planFilePath),
+		func(ctx context.Context, events chan<- uijson.Event) error {
+			// Record the outcome of each resource on the way to the dashboard
+			recorded := make(chan uijson.Event)
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for event := range recorded {
+					a.recordEvent(event)
+					events <- event
+				}
+			}()
+			err := a.executor.Stream(ctx, args, recorded)
+			close(recorded)
+			<-done
+			return err
+		})
+}
+
+// plannedResources returns the resource changes of a plan file for the apply
+// dashboard. Without them the dashboard lists resources as terraform reaches them.
+func (a *ApplyManager) plannedResources(ctx interface{}, planFilePath string) []dashboard.Resource {
+	if planFilePath == "" || a.plans == nil {
+		return nil
+	}
+	plan, err := a.plans.LoadPlan(ctx, planFilePath)
+	if err != nil {
+		return nil
+	}
+
+	var resources []dashboard.Resource
+	for _, change := range plan.ResourceChanges {
+		actions := change.Change.Actions
+		if change.Mode == "data" || actions.IsNoOp() || actions.Summary() == "read" {
+			continue
+		}
+		resources = append(resources, dashboard.Resource{Address: change.Address, Action: actions.Summary()})
+	}
+	return resources
+}
+
 // Init runs the Terraform init command.
 // If upgrade is true, it runs with the -upgrade flag.
 func (a *ApplyManager) Init(ctx interface{}, upgrade bool) error {
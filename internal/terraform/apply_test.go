@@ -0,0 +1,143 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/testutil"
+)
+
+func newQuietApplyManager() *ApplyManager {
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+	return NewApplyManager(executor)
+}
+
+func TestApplyConfirmed(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("apply", testutil.Response{Stdout: "Apply complete! Resources: 1 added, 0 changed, 0 destroyed.\n"})
+	testutil.Stdin(t, "yes\n")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Apply(context.Background(), "/tmp/plan.tfplan")
+	})
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+
+	// The fake terraform reports no version, so the output is not streamed
+	want := [][]string{{"version", "-json"}, {"apply", "/tmp/plan.tfplan"}}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+	if !strings.Contains(out, "Terraform apply completed successfully!") {
+		t.Errorf("output %q does not report success", out)
+	}
+}
+
+func TestApplyAborted(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	testutil.Stdin(t, "no\n")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Apply(context.Background(), "/tmp/plan.tfplan")
+	})
+	if err != nil {
+		t.Fatalf("Apply returned error: %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("terraform was called after the apply was declined: %q", calls)
+	}
+	if !strings.Contains(out, "Apply aborted.") {
+		t.Errorf("output %q does not report the abort", out)
+	}
+}
+
+func TestApplyFailure(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("apply", testutil.Response{Stderr: "Error: creating EC2 Instance\n", ExitCode: 1})
+	testutil.Stdin(t, "yes\n")
+
+	var err error
+	testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Apply(context.Background(), "/tmp/plan.tfplan")
+	})
+	if err == nil || !strings.Contains(err.Error(), "creating EC2 Instance") {
+		t.Fatalf("Apply error = %v, want terraform's error output", err)
+	}
+}
+
+func TestDestroyRequiresTypedConfirmation(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	testutil.Stdin(t, "yes\n")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Destroy(context.Background(), "/tmp/destroy.tfplan", "production")
+	})
+	if err != nil {
+		t.Fatalf("Destroy returned error: %v", err)
+	}
+	if calls := fake.Calls(); len(calls) != 0 {
+		t.Errorf("terraform was called without the typed confirmation: %q", calls)
+	}
+	if !strings.Contains(out, "Destroy aborted.") {
+		t.Errorf("output %q does not report the abort", out)
+	}
+}
+
+func TestDestroyConfirmed(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	testutil.Stdin(t, "production\n")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Destroy(context.Background(), "/tmp/destroy.tfplan", "production")
+	})
+	if err != nil {
+		t.Fatalf("Destroy returned error: %v", err)
+	}
+
+	want := [][]string{{"version", "-json"}, {"apply", "/tmp/destroy.tfplan"}}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+	if !strings.Contains(out, "Terraform destroy completed successfully!") {
+		t.Errorf("output %q does not report success", out)
+	}
+}
+
+func TestApplyPartialFailureListsFailedResources(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("Terraform", "1.9.5")
+	fake.On("apply", testutil.Response{Stdout: string(testutil.UIFixture(t, "apply_errored")), ExitCode: 1})
+	testutil.Stdin(t, "yes\n")
+
+	var err error
+	out := testutil.CaptureStdout(t, func() {
+		err = newQuietApplyManager().Apply(context.Background(), "/tmp/plan.tfplan")
+	})
+
+	var applyErr *apperrors.ApplyError
+	if !errors.As(err, &applyErr) {
+		t.Fatalf("Apply error = %v, want an *apperrors.ApplyError", err)
+	}
+	if want := []string{"aws_db_instance.main"}; !reflect.DeepEqual(applyErr.Failed, want) {
+		t.Errorf("failed = %q, want %q", applyErr.Failed, want)
+	}
+	if len(applyErr.Succeeded) != 3 {
+		t.Errorf("succeeded = %q, want the 3 other resources", applyErr.Succeeded)
+	}
+	if !IsDiagnosticsError(err) {
+		t.Errorf("Apply error %v does not carry terraform's diagnostics", err)
+	}
+	if !strings.Contains(out, "Apply report:") {
+		t.Errorf("output does not contain the apply report:\n%s", out)
+	}
+}
@@ -0,0 +1,118 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+
+	"tfapp/internal/ui"
+	"tfapp/internal/uijson"
+)
+
+// ApplyReport records the outcome of every resource change of an apply, from
+// the events of its machine-readable output.
+type ApplyReport struct {
+	outcomes map[string]*ResourceOutcome
+	order    []string // Addresses in the order terraform reached them
+}
+
+// ResourceOutcome is the outcome of the change of one resource.
+type ResourceOutcome struct {
+	Address     string
+	Action      string // Action of the last operation, e.g. create
+	Failed      bool
+	Diagnostics []uijson.Diagnostic // Error diagnostics about the resource
+}
+
+// NewApplyReport creates an empty apply report.
+func NewApplyReport() *ApplyReport {
+	return &ApplyReport{outcomes: make(map[string]*ResourceOutcome)}
+}
+
+// Record updates the report with an event of the apply output.
+// A resource fails as soon as one of its operations errors; a replace
+// succeeds only once both its operations have completed.
+func (r *ApplyReport) Record(event uijson.Event) {
+	switch event := event.(type) {
+	case *uijson.ApplyCompleteEvent:
+		r.outcome(event.Resource.Addr).Action = event.Action
+	case *uijson.ApplyErroredEvent:
+		outcome := r.outcome(event.Resource.Addr)
+		outcome.Action = event.Action
+		outcome.Failed = true
+	case *uijson.DiagnosticEvent:
+		if event.Severity == "error" && event.Address != "" {
+			outcome := r.outcome(event.Address)
+			outcome.Failed = true
+			outcome.Diagnostics = append(outcome.Diagnostics, event.Diagnostic)
+		}
+	}
+}
+
+// outcome returns the outcome of a resource, following it from now on.
+func (r *ApplyReport) outcome(address string) *ResourceOutcome {
+	if outcome, ok := r.outcomes[address]; ok {
+		return outcome
+	}
+	outcome := &ResourceOutcome{Address: address}
+	r.outcomes[address] = outcome
+	r.order = append(r.order, address)
+	return outcome
+}
+
+// Succeeded returns the addresses of the resources whose changes were applied.
+func (r *ApplyReport) Succeeded() []string {
+	return r.addresses(false)
+}
+
+// Failed returns the addresses of the resources whose changes errored.
+func (r *ApplyReport) Failed() []string {
+	return r.addresses(true)
+}
+
+// addresses returns the addresses of the failed or succeeded resources.
+func (r *ApplyReport) addresses(failed bool) []string {
+	addresses := []string{}
+	for _, address := range r.order {
+		if r.outcomes[address].Failed == failed {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
+
+// WriteApplyReport writes the resources an apply changed and the ones that failed, with the reason.
+func WriteApplyReport(w io.Writer, report *ApplyReport) {
+	succeeded, failed := report.Succeeded(), report.Failed()
+
+	fmt.Fprintf(w, "\n%s%sApply report:%s\n", ui.ColorInfo, ui.TextBold, ui.ColorReset)
+	fmt.Fprintf(w, "  %s✓ %s applied%s\n", ui.ColorSuccess, pluralResources(len(succeeded)), ui.ColorReset)
+	for _, address := range succeeded {
+		fmt.Fprintf(w, "      %s (%s)\n", address, report.outcomes[address].Action)
+	}
+
+	fmt.Fprintf(w, "  %s✗ %s failed%s\n", ui.ColorError, pluralResources(len(failed)), ui.ColorReset)
+	for _, address := range failed {
+		outcome := report.outcomes[address]
+		line := "      " + address
+		if outcome.Action != "" {
+			line += " (" + outcome.Action + ")"
+		}
+		for i, diagnostic := range outcome.Diagnostics {
+			if i == 0 {
+				line += ": " + diagnostic.Summary
+			} else {
+				line += "; " + diagnostic.Summary
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+// pluralResources returns "1 resource" or "N resources".
+func pluralResources(n int) string {
+	if n == 1 {
+		return "1 resource"
+	}
+	return fmt.Sprintf("%d resources", n)
+}
@@ -0,0 +1,46 @@
+package terraform
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/testutil"
+	"tfapp/internal/uijson"
+)
+
+func TestApplyReportRecordsOutcomes(t *testing.T) {
+	report := NewApplyReport()
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, "apply_errored")), []byte("\n")) {
+		msg, err := uijson.Decode(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event := msg.Event(); event != nil {
+			report.Record(event)
+		}
+	}
+
+	// The database was destroyed before its replacement failed to be created
+	if got, want := report.Failed(), []string{"aws_db_instance.main"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Failed() = %q, want %q", got, want)
+	}
+	if got, want := report.Succeeded(), []string{"aws_iam_role.legacy", "aws_s3_bucket.logs", "aws_instance.cache"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Succeeded() = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	WriteApplyReport(&buf, report)
+	out := buf.String()
+	for _, want := range []string{
+		"3 resources applied",
+		"aws_s3_bucket.logs (update)",
+		"1 resource failed",
+		"aws_db_instance.main (create): creating RDS DB Instance (main)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report does not contain %q:\n%s", want, out)
+		}
+	}
+}
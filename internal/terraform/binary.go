@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Product is the tool behind the binary tfapp runs.
+type Product string
+
+// Products tfapp can run.
+const (
+	ProductTerraform Product = "Terraform"
+	ProductOpenTofu  Product = "OpenTofu"
+)
+
+// BinaryEnv is the environment variable naming the binary to run. It takes
+// precedence over the configured binary.
+const BinaryEnv = "TFAPP_BINARY"
+
+// FindBinary returns the path of the binary tfapp runs: the one named by
+// TFAPP_BINARY if set, else the configured one if set, else terraform or,
+// failing that, tofu. A name without a path separator is looked up in PATH.
+func FindBinary(configured string) (string, error) {
+	if binary := os.Getenv(BinaryEnv); binary != "" {
+		path, err := exec.LookPath(binary)
+		if err != nil {
+			return "", fmt.Errorf("%s=%s: %w", BinaryEnv, binary, err)
+		}
+		return path, nil
+	}
+	if configured != "" {
+		path, err := exec.LookPath(configured)
+		if err != nil {
+			return "", fmt.Errorf("configured binary %s: %w", configured, err)
+		}
+		return path, nil
+	}
+
+	for _, name := range []string{"terraform", "tofu"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("neither terraform nor tofu was found in PATH")
+}
@@ -0,0 +1,38 @@
+package terraform
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestFindBinary(t *testing.T) {
+	testutil.InstallFakeTofu(t)
+	tofu, err := exec.LookPath("tofu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", filepath.Dir(tofu))
+
+	// Without terraform in PATH, tofu is used
+	path, err := FindBinary("")
+	if err != nil || filepath.Base(path) != "tofu" {
+		t.Errorf("FindBinary() = %q, %v, want tofu", path, err)
+	}
+
+	if _, err := FindBinary("terraform"); err == nil {
+		t.Error("FindBinary of a missing configured binary returned no error")
+	}
+
+	// The environment takes precedence over the configuration
+	t.Setenv(BinaryEnv, "tofu")
+	if path, err := FindBinary("terraform"); err != nil || filepath.Base(path) != "tofu" {
+		t.Errorf("FindBinary() with %s = %q, %v, want tofu", BinaryEnv, path, err)
+	}
+	t.Setenv(BinaryEnv, "missing")
+	if _, err := FindBinary("tofu"); err == nil {
+		t.Errorf("FindBinary() with a missing %s returned no error", BinaryEnv)
+	}
+}
@@ -0,0 +1,101 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"tfapp/internal/planjson"
+)
+
+// PlanCache holds parsed plans keyed by plan file path.
+// An entry is only reused while the file's content hash and modification time
+// are unchanged, so `terraform show -json` runs once per plan file version.
+type PlanCache struct {
+	mu      sync.Mutex
+	entries map[string]planCacheEntry
+}
+
+// planCacheEntry is a parsed plan together with the file version it was read from.
+type planCacheEntry struct {
+	hash    [sha256.Size]byte
+	modTime time.Time
+	plan    *planjson.Plan
+}
+
+// NewPlanCache creates an empty plan cache.
+func NewPlanCache() *PlanCache {
+	return &PlanCache{
+		entries: make(map[string]planCacheEntry),
+	}
+}
+
+// Get returns the cached plan for the file if the file has not changed since it was stored.
+func (c *PlanCache) Get(planFilePath string) (*planjson.Plan, bool) {
+	hash, modTime, err := fileVersion(planFilePath)
+	if err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[planFilePath]
+	if !ok || entry.hash != hash || !entry.modTime.Equal(modTime) {
+		return nil, false
+	}
+	return entry.plan, true
+}
+
+// Put stores a parsed plan for the current version of the file.
+func (c *PlanCache) Put(planFilePath string, plan *planjson.Plan) error {
+	hash, modTime, err := fileVersion(planFilePath)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[planFilePath] = planCacheEntry{
+		hash:    hash,
+		modTime: modTime,
+		plan:    plan,
+	}
+	return nil
+}
+
+// Forget drops the cached plan for the file, if any.
+func (c *PlanCache) Forget(planFilePath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, planFilePath)
+}
+
+// fileVersion returns the content hash and modification time of a file.
+func fileVersion(path string) ([sha256.Size]byte, time.Time, error) {
+	var hash [sha256.Size]byte
+
+	f, err := os.Open(path)
+	if err != nil {
+		return hash, time.Time{}, fmt.Errorf("error opening plan file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return hash, time.Time{}, fmt.Errorf("error reading plan file info: %w", err)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return hash, time.Time{}, fmt.Errorf("error hashing plan file: %w", err)
+	}
+	copy(hash[:], h.Sum(nil))
+
+	return hash, info.ModTime(), nil
+}
@@ -0,0 +1,93 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+// countCalls returns how many times terraform was invoked with the given command.
+func countCalls(fake *testutil.FakeTerraform, command string) int {
+	count := 0
+	for _, call := range fake.Calls() {
+		if len(call) > 0 && call[0] == command {
+			count++
+		}
+	}
+	return count
+}
+
+func TestLoadPlanUsesCache(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	if err := os.WriteFile(planFile, []byte("plan v1"), 0644); err != nil {
+		t.Fatalf("writing plan file: %v", err)
+	}
+
+	planManager := newQuietPlanManager()
+	ctx := context.Background()
+
+	first, err := planManager.LoadPlan(ctx, planFile)
+	if err != nil {
+		t.Fatalf("LoadPlan returned error: %v", err)
+	}
+	if _, err := planManager.DisplayPlanSummary(ctx, planFile); err != nil {
+		t.Fatalf("DisplayPlanSummary returned error: %v", err)
+	}
+	second, err := planManager.LoadPlan(ctx, planFile)
+	if err != nil {
+		t.Fatalf("LoadPlan returned error: %v", err)
+	}
+
+	if first != second {
+		t.Error("LoadPlan returned a different plan for an unchanged plan file")
+	}
+	if got := countCalls(fake, "show"); got != 1 {
+		t.Errorf("terraform show called %d times, want 1", got)
+	}
+}
+
+func TestLoadPlanReloadsChangedFile(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("create")
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	if err := os.WriteFile(planFile, []byte("plan v1"), 0644); err != nil {
+		t.Fatalf("writing plan file: %v", err)
+	}
+
+	planManager := newQuietPlanManager()
+	ctx := context.Background()
+
+	if _, err := planManager.LoadPlan(ctx, planFile); err != nil {
+		t.Fatalf("LoadPlan returned error: %v", err)
+	}
+
+	if err := os.WriteFile(planFile, []byte("plan v2"), 0644); err != nil {
+		t.Fatalf("rewriting plan file: %v", err)
+	}
+	fake.OnShowPlan("replace")
+
+	plan, err := planManager.LoadPlan(ctx, planFile)
+	if err != nil {
+		t.Fatalf("LoadPlan returned error: %v", err)
+	}
+	if got := countCalls(fake, "show"); got != 2 {
+		t.Errorf("terraform show called %d times, want 2", got)
+	}
+	if len(plan.ResourceChanges) == 0 || plan.ResourceChanges[0].Address != "aws_db_instance.main" {
+		t.Error("LoadPlan returned the stale plan after the plan file changed")
+	}
+}
+
+func TestPlanCacheMissingFile(t *testing.T) {
+	cache := NewPlanCache()
+	if _, ok := cache.Get(filepath.Join(t.TempDir(), "missing.tfplan")); ok {
+		t.Error("Get returned a plan for a missing file")
+	}
+}
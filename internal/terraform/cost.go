@@ -0,0 +1,71 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+
+	"tfapp/internal/cost"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/plan"
+)
+
+// WriteCostEstimate writes the estimated monthly cost change per resource and in total.
+// It writes nothing without an estimate or when no changed resource is priced.
+func WriteCostEstimate(w io.Writer, estimate *cost.Estimate) {
+	if estimate == nil || len(estimate.Resources) == 0 {
+		return
+	}
+
+	width := 0
+	for _, resource := range estimate.Resources {
+		width = max(width, len(resource.Address))
+	}
+
+	fmt.Fprintf(w, "%s%sEstimated monthly cost change:%s\n", ui.TextBold, ui.ColorCyan, ui.ColorReset)
+	for _, resource := range estimate.Resources {
+		amount := "unknown"
+		if resource.Known {
+			amount = cost.FormatMoney(resource.Delta(), estimate.Currency)
+		}
+		line := fmt.Sprintf("  %-*s  %12s", width, resource.Address, amount)
+		if resource.Detail != "" {
+			line += " (" + resource.Detail + ")"
+		}
+		fmt.Fprintln(w, costColor(resource.Delta(), resource.Known)+line+ui.ColorReset)
+	}
+
+	total := fmt.Sprintf("Total: %s/month", cost.FormatMoney(estimate.Total, estimate.Currency))
+	if estimate.Unknown > 0 {
+		total += fmt.Sprintf(" (%d resources could not be priced)", estimate.Unknown)
+	}
+	fmt.Fprintf(w, "%s%s%s%s\n\n", ui.TextBold, costColor(estimate.Total, true), total, ui.ColorReset)
+}
+
+// costColor returns the color of a cost change: increases are warnings, savings successes.
+func costColor(delta float64, known bool) string {
+	switch {
+	case !known:
+		return ui.ColorFaint
+	case delta > 0:
+		return ui.ColorWarning
+	case delta < 0:
+		return ui.ColorSuccess
+	}
+	return ""
+}
+
+// CostAnnotations converts a cost estimate to the plan viewer's cost column.
+func CostAnnotations(estimate *cost.Estimate) map[string][]plan.Annotation {
+	annotations := make(map[string][]plan.Annotation)
+	if estimate == nil {
+		return annotations
+	}
+	for _, resource := range estimate.Resources {
+		column := "? /mo"
+		if resource.Known {
+			column = cost.FormatMoney(resource.Delta(), estimate.Currency) + "/mo"
+		}
+		annotations[resource.Address] = append(annotations[resource.Address], plan.Annotation{Column: column})
+	}
+	return annotations
+}
@@ -0,0 +1,60 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"tfapp/internal/cost"
+)
+
+func TestWriteCostEstimate(t *testing.T) {
+	estimate := &cost.Estimate{
+		Currency: "USD",
+		Resources: []cost.ResourceCost{
+			{Address: "aws_db_instance.main", Action: "replace"},
+			{Address: "aws_instance.web", Action: "update", Before: 7.59, After: 15.18, Known: true, Detail: "t3.micro → t3.small"},
+		},
+		Total:   7.59,
+		Unknown: 1,
+	}
+
+	var out strings.Builder
+	WriteCostEstimate(&out, estimate)
+	got := out.String()
+
+	for _, want := range []string{
+		"Estimated monthly cost change:",
+		"aws_db_instance.main",
+		"unknown",
+		"+$7.59 (t3.micro → t3.small)",
+		"Total: +$7.59/month (1 resources could not be priced)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output does not contain %q:\n%s", want, got)
+		}
+	}
+
+	out.Reset()
+	WriteCostEstimate(&out, nil)
+	WriteCostEstimate(&out, &cost.Estimate{Currency: "USD"})
+	if out.Len() != 0 {
+		t.Errorf("expected no output without priced resources, got %q", out.String())
+	}
+}
+
+func TestCostAnnotations(t *testing.T) {
+	annotations := CostAnnotations(&cost.Estimate{
+		Currency: "EUR",
+		Resources: []cost.ResourceCost{
+			{Address: "aws_instance.web", After: 10, Known: true},
+			{Address: "aws_db_instance.main"},
+		},
+	})
+
+	if got := annotations["aws_instance.web"][0].Column; got != "+10.00 EUR/mo" {
+		t.Errorf("instance column = %q", got)
+	}
+	if got := annotations["aws_db_instance.main"][0].Column; got != "? /mo" {
+		t.Errorf("database column = %q", got)
+	}
+}
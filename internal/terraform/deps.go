@@ -0,0 +1,320 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	"tfapp/internal/planjson"
+)
+
+// Dependencies answers which other changes terraform pulls into a targeted
+// plan. It is built from the `configuration` block of the plan JSON, using
+// depends_on and the references of each resource's expressions, and works on
+// configuration resources: targeting one instance of a resource includes every
+// instance of the resources it depends on.
+//
+// References through locals cannot be followed because the plan JSON does not
+// describe locals; such dependencies are missed.
+type Dependencies struct {
+	dependsOn map[string][]string // Configuration resource → configuration resources it refers to directly
+	changes   map[string][]string // Configuration resource → changed instance addresses
+	resources map[string]string   // Changed instance address → configuration resource
+}
+
+// configScope is a module of the configuration along with its path and parent call.
+type configScope struct {
+	path   string // Module path without instance keys, e.g. module.app.module.db; empty for the root
+	module *planjson.ConfigModule
+	parent *configScope
+	call   *planjson.ModuleCall // Call of this module in the parent; nil for the root
+}
+
+// BuildDependencies builds the dependency graph of the plan's changed resources.
+func BuildDependencies(plan *planjson.Plan) *Dependencies {
+	d := &Dependencies{
+		dependsOn: make(map[string][]string),
+		changes:   make(map[string][]string),
+		resources: make(map[string]string),
+	}
+
+	for _, change := range plan.ResourceChanges {
+		if change.Change.Actions.IsNoOp() || change.Mode == "data" {
+			continue
+		}
+		key := configResourceKey(configModulePath(change.ModuleAddress), resourceAddress(change.Mode, change.Type, change.Name))
+		d.changes[key] = append(d.changes[key], change.Address)
+		d.resources[change.Address] = key
+	}
+
+	d.addModule(&configScope{module: &plan.Configuration.RootModule})
+	return d
+}
+
+// addModule records the direct dependencies of every resource in a module and its children.
+func (d *Dependencies) addModule(scope *configScope) {
+	// Resources in a module depend on everything the module call depends on
+	var inherited []string
+	for s := scope; s.call != nil; s = s.parent {
+		for _, ref := range s.call.DependsOn {
+			inherited = append(inherited, s.parent.resolve(ref)...)
+		}
+	}
+
+	for _, resource := range scope.module.Resources {
+		key := configResourceKey(scope.path, resource.Address)
+
+		refs := append([]string{}, resource.DependsOn...)
+		refs = append(refs, expressionReferences(resource.Expressions)...)
+		if resource.CountExpression != nil {
+			refs = append(refs, resource.CountExpression.References...)
+		}
+		if resource.ForEachExpression != nil {
+			refs = append(refs, resource.ForEachExpression.References...)
+		}
+
+		deps := append([]string{}, inherited...)
+		for _, ref := range refs {
+			deps = append(deps, scope.resolve(ref)...)
+		}
+		d.dependsOn[key] = deps
+	}
+
+	for _, name := range sortedKeys(scope.module.ModuleCalls) {
+		call := scope.module.ModuleCalls[name]
+		d.addModule(&configScope{
+			path:   joinAddress(scope.path, "module."+name),
+			module: &call.Module,
+			parent: scope,
+			call:   &call,
+		})
+	}
+}
+
+// resolve returns the configuration resources a reference in this module points to.
+func (s *configScope) resolve(ref string) []string {
+	parts := referenceParts(ref)
+	if len(parts) < 2 {
+		return nil
+	}
+
+	switch parts[0] {
+	case "var":
+		// Input variables come from the module call's argument in the parent module
+		if s.call == nil {
+			return nil
+		}
+		expression, ok := s.call.Expressions[parts[1]]
+		if !ok {
+			return nil
+		}
+		var resolved []string
+		for _, parentRef := range expressionReferences(map[string]interface{}{parts[1]: expression}) {
+			resolved = append(resolved, s.parent.resolve(parentRef)...)
+		}
+		return resolved
+
+	case "module":
+		call, ok := s.module.ModuleCalls[parts[1]]
+		if !ok {
+			return nil
+		}
+		child := &configScope{path: joinAddress(s.path, "module."+parts[1]), module: &call.Module, parent: s, call: &call}
+		if len(parts) > 2 {
+			// A module output depends on what its expression refers to
+			if output, ok := call.Module.Outputs[parts[2]]; ok {
+				var resolved []string
+				for _, outputRef := range append(output.Expression.References, output.DependsOn...) {
+					resolved = append(resolved, child.resolve(outputRef)...)
+				}
+				return resolved
+			}
+		}
+		return child.allResources()
+
+	case "data":
+		if len(parts) < 3 {
+			return nil
+		}
+		return s.resource("data." + parts[1] + "." + parts[2])
+
+	case "local", "each", "count", "path", "terraform", "self":
+		return nil
+	}
+
+	return s.resource(parts[0] + "." + parts[1])
+}
+
+// resource returns the key of the resource with the given address in this module, if it exists.
+func (s *configScope) resource(address string) []string {
+	for _, resource := range s.module.Resources {
+		if resource.Address == address {
+			return []string{configResourceKey(s.path, address)}
+		}
+	}
+	return nil
+}
+
+// allResources returns the keys of every resource in this module and its children.
+func (s *configScope) allResources() []string {
+	var keys []string
+	for _, resource := range s.module.Resources {
+		keys = append(keys, configResourceKey(s.path, resource.Address))
+	}
+	for _, name := range sortedKeys(s.module.ModuleCalls) {
+		call := s.module.ModuleCalls[name]
+		child := &configScope{path: joinAddress(s.path, "module."+name), module: &call.Module, parent: s, call: &call}
+		keys = append(keys, child.allResources()...)
+	}
+	return keys
+}
+
+// DependenciesOf returns the other changed instances that terraform includes
+// when the given instance is targeted, sorted by address.
+func (d *Dependencies) DependenciesOf(address string) []string {
+	key, ok := d.resources[address]
+	if !ok {
+		return nil
+	}
+	return d.changedInstances(d.closure(key, d.dependsOn), key)
+}
+
+// Implied returns the changed instances that are not selected but that terraform
+// includes as dependencies of the selected ones, sorted by address.
+func (d *Dependencies) Implied(selected map[string]bool) []string {
+	implied := make(map[string]bool)
+	for address := range selected {
+		for _, dependency := range d.DependenciesOf(address) {
+			if !selected[dependency] {
+				implied[dependency] = true
+			}
+		}
+	}
+	return sortedKeys(implied)
+}
+
+// closure returns every configuration resource reachable from key through edges, excluding key.
+func (d *Dependencies) closure(key string, edges map[string][]string) map[string]bool {
+	seen := map[string]bool{key: true}
+	queue := []string{key}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[current] {
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	delete(seen, key)
+	return seen
+}
+
+// changedInstances lists the changed instances of the given configuration resources.
+func (d *Dependencies) changedInstances(keys map[string]bool, exclude string) []string {
+	var addresses []string
+	for key := range keys {
+		if key == exclude {
+			continue
+		}
+		addresses = append(addresses, d.changes[key]...)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// configModulePath strips the instance keys from a module address,
+// e.g. module.app[0].module.db["x"] becomes module.app.module.db.
+func configModulePath(moduleAddress string) string {
+	path := ""
+	previous := ""
+	for _, step := range splitModuleAddress(moduleAddress) {
+		name := strings.TrimPrefix(step.call, previous)
+		path += name
+		previous = step.instance
+	}
+	return path
+}
+
+// configResourceKey identifies a resource of the configuration by module path and address.
+func configResourceKey(modulePath, address string) string {
+	return joinAddress(modulePath, address)
+}
+
+// resourceAddress returns the address of a resource within its module.
+func resourceAddress(mode, resourceType, name string) string {
+	if mode == "data" {
+		return "data." + resourceType + "." + name
+	}
+	return resourceType + "." + name
+}
+
+// joinAddress joins two address parts with a dot, skipping an empty prefix.
+func joinAddress(prefix, address string) string {
+	if prefix == "" {
+		return address
+	}
+	return prefix + "." + address
+}
+
+// referenceParts splits a reference such as aws_instance.web[0].id into
+// its dot-separated names, dropping index keys.
+func referenceParts(ref string) []string {
+	var parts []string
+	for _, part := range strings.Split(ref, ".") {
+		if i := strings.Index(part, "["); i >= 0 {
+			part = part[:i]
+		}
+		if part == "" {
+			break
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// expressionReferences collects the references of a block's expressions,
+// including those of nested blocks.
+func expressionReferences(expressions map[string]interface{}) []string {
+	var refs []string
+
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if _, ok := v["constant_value"]; ok {
+				return
+			}
+			if references, ok := v["references"].([]interface{}); ok {
+				for _, ref := range references {
+					if s, ok := ref.(string); ok {
+						refs = append(refs, s)
+					}
+				}
+				return
+			}
+			for _, nested := range v {
+				walk(nested)
+			}
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		}
+	}
+	for _, name := range sortedKeys(expressions) {
+		walk(expressions[name])
+	}
+
+	return refs
+}
+
+// sortedKeys returns the keys of a map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
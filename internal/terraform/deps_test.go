@@ -0,0 +1,44 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependenciesThroughModules(t *testing.T) {
+	deps := BuildDependencies(loadFixture(t, "modules"))
+
+	// web's subnet_id comes from var.subnet_ids, which the root module sets
+	// from module.network's private_subnet_ids output; role_arn is unused inside.
+	got := deps.DependenciesOf(`module.app[0].aws_instance.web["blue"]`)
+	want := []string{"module.network.aws_subnet.private[0]", "module.network.aws_subnet.private[1]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DependenciesOf() = %q, want %q", got, want)
+	}
+
+	if got := deps.DependenciesOf("aws_iam_role.deployer"); got != nil {
+		t.Errorf("DependenciesOf(aws_iam_role.deployer) = %q, want none", got)
+	}
+}
+
+func TestDependenciesFromReferences(t *testing.T) {
+	deps := BuildDependencies(loadFixture(t, "create"))
+
+	if got, want := deps.DependenciesOf("aws_instance.web"), []string{"aws_security_group.web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DependenciesOf() = %q, want %q", got, want)
+	}
+
+	implied := deps.Implied(map[string]bool{"aws_instance.web": true})
+	if want := []string{"aws_security_group.web"}; !reflect.DeepEqual(implied, want) {
+		t.Errorf("Implied() = %q, want %q", implied, want)
+	}
+	if implied := deps.Implied(map[string]bool{"aws_instance.web": true, "aws_security_group.web": true}); len(implied) != 0 {
+		t.Errorf("Implied() = %q, want none when every dependency is selected", implied)
+	}
+}
+
+func TestConfigModulePath(t *testing.T) {
+	if got, want := configModulePath(`module.app[0].module.db["x.y"]`), "module.app.module.db"; got != want {
+		t.Errorf("configModulePath() = %q, want %q", got, want)
+	}
+}
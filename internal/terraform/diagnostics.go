@@ -0,0 +1,51 @@
+package terraform
+
+import (
+	"errors"
+	"strings"
+
+	"tfapp/internal/uijson"
+)
+
+// DiagnosticsError is the failure of a terraform command run with -json,
+// with the error diagnostics it reported.
+type DiagnosticsError struct {
+	Command     string // Terraform subcommand, e.g. plan
+	Diagnostics []uijson.Diagnostic
+	Err         error
+}
+
+// Error returns the error message, formatting the diagnostics as terraform does.
+func (e *DiagnosticsError) Error() string {
+	var sb strings.Builder
+	for i, diagnostic := range e.Diagnostics {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("Error: " + diagnostic.Summary)
+		if diagnostic.Detail != "" {
+			sb.WriteString("\n\n" + diagnostic.Detail)
+		}
+	}
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *DiagnosticsError) Unwrap() error {
+	return e.Err
+}
+
+// NewDiagnosticsError creates a new DiagnosticsError.
+func NewDiagnosticsError(command string, diagnostics []uijson.Diagnostic, err error) error {
+	return &DiagnosticsError{
+		Command:     command,
+		Diagnostics: diagnostics,
+		Err:         err,
+	}
+}
+
+// IsDiagnosticsError returns true if the error is a DiagnosticsError.
+func IsDiagnosticsError(err error) bool {
+	var diagErr *DiagnosticsError
+	return errors.As(err, &diagErr)
+}
@@ -5,32 +5,115 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"tfapp/internal/models"
+	"tfapp/internal/ui"
 	"tfapp/internal/ui/spinner"
+	"tfapp/internal/uijson"
+	"tfapp/internal/utils"
 )
 
 // CommandExecutor handles executing Terraform commands.
 type CommandExecutor struct {
-	// Add fields if needed in the future for configuration
 	progressCallbacks []ProgressCallback
+	eventCallbacks    []EventCallback
+	quiet             bool     // Suppress the spinner and interactive stdin
+	dir               string   // Directory terraform runs in, the current one if empty
+	binary            string   // Binary run, terraform or tofu by default
+	env               []string // Variables set for the binary on top of tfapp's environment, as NAME=value
+
+	mu         sync.Mutex
+	version    *VersionInfo // Version of the binary, detected once
+	versionErr error        // Why the version could not be detected
 }
 
 // ProgressCallback is a function type that gets called with progress updates
 type ProgressCallback func(status string)
 
+// EventCallback is a function type that gets called with each event of a command run with -json
+type EventCallback func(event uijson.Event)
+
 // NewCommandExecutor creates a new Terraform command executor.
 func NewCommandExecutor() *CommandExecutor {
 	return &CommandExecutor{
 		progressCallbacks: make([]ProgressCallback, 0),
+		binary:            "terraform",
+	}
+}
+
+// SetQuiet enables or disables quiet mode.
+// In quiet mode no spinner is drawn, no progress is reported and the command
+// does not read from stdin, so it can run in pipelines without a terminal.
+func (e *CommandExecutor) SetQuiet(quiet bool) {
+	e.quiet = quiet
+}
+
+// SetDir sets the directory terraform runs in, the working directory of its
+// root module. By default terraform runs in the current directory.
+func (e *CommandExecutor) SetDir(dir string) {
+	e.dir = dir
+}
+
+// SetBinary sets the binary run, a path or a name looked up in PATH, such as
+// tofu to run OpenTofu. By default terraform is run.
+func (e *CommandExecutor) SetBinary(binary string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.binary = binary
+	e.version, e.versionErr = nil, nil
+}
+
+// SetEnv sets an environment variable for the commands the executor runs,
+// on top of tfapp's own environment.
+func (e *CommandExecutor) SetEnv(name, value string) {
+	e.env = append(e.env, name+"="+value)
+}
+
+// Workspace returns the workspace the executor's commands run in: the
+// TF_WORKSPACE set with SetEnv, else the one selected in its directory.
+func (e *CommandExecutor) Workspace() string {
+	for i := len(e.env) - 1; i >= 0; i-- {
+		if workspace, ok := strings.CutPrefix(e.env[i], WorkspaceEnv+"="); ok {
+			return workspace
+		}
+	}
+	return SelectedWorkspaceIn(e.dir)
+}
+
+// Binary returns the binary run.
+func (e *CommandExecutor) Binary() string {
+	return e.binary
+}
+
+// Version returns the version of the binary, detecting it the first time
+// only. A failed detection is not retried either.
+func (e *CommandExecutor) Version(ctx interface{}) (*VersionInfo, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.version == nil && e.versionErr == nil {
+		e.version, e.versionErr = detectVersion(ctx, e)
 	}
+	return e.version, e.versionErr
+}
+
+// command creates the command running the binary with args in the executor's directory.
+func (e *CommandExecutor) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, e.binary, args...)
+	cmd.Dir = e.dir
+	if len(e.env) > 0 {
+		// Later values win, so these override tfapp's environment
+		cmd.Env = append(os.Environ(), e.env...)
+	}
+	return cmd
 }
 
 // RegisterProgressCallback registers a callback function to receive progress updates
@@ -38,8 +121,17 @@ func (e *CommandExecutor) RegisterProgressCallback(callback ProgressCallback) {
 	e.progressCallbacks = append(e.progressCallbacks, callback)
 }
 
+// RegisterEventCallback registers a callback function to receive the events of
+// the commands RunCommand runs with -json
+func (e *CommandExecutor) RegisterEventCallback(callback EventCallback) {
+	e.eventCallbacks = append(e.eventCallbacks, callback)
+}
+
 // notifyProgress sends a status update to all registered callbacks
 func (e *CommandExecutor) notifyProgress(status string) {
+	if e.quiet {
+		return
+	}
 	for _, callback := range e.progressCallbacks {
 		callback(status)
 	}
@@ -47,15 +139,22 @@ func (e *CommandExecutor) notifyProgress(status string) {
 
 // RunCommand executes a terraform command with the given arguments.
 // If redirectOutput is true, the command's output will be redirected to stdout/stderr.
-// Otherwise, it captures the output and returns any errors that occurred.
+// Otherwise, it captures the output and returns any errors that occurred. Plan,
+// apply and init then run with -json where terraform supports it, their events
+// driving the spinner and progress callbacks.
 func (e *CommandExecutor) RunCommand(ctx interface{}, args []string, spinnerMsg string, redirectOutput bool) error {
 	ctxTyped, ok := ctx.(context.Context)
 	if !ok {
 		return fmt.Errorf("context type assertion failed")
 	}
+	if !redirectOutput && e.streamable(ctx, args) {
+		return e.runJSON(ctxTyped, args, spinnerMsg)
+	}
 
-	cmd := exec.CommandContext(ctxTyped, "terraform", args...)
-	cmd.Stdin = os.Stdin
+	cmd := e.command(ctxTyped, args...)
+	if !e.quiet {
+		cmd.Stdin = os.Stdin
+	}
 
 	var stdout, stderr bytes.Buffer
 	var wg sync.WaitGroup
@@ -120,32 +219,39 @@ func (e *CommandExecutor) RunCommand(ctx interface{}, args []string, spinnerMsg
 	}
 
 	// Start an enhanced spinner with status updates
-	s := spinner.New(spinnerMsg)
-	s.Start()
+	var s *spinner.Spinner
+	if !e.quiet {
+		s = spinner.New(spinnerMsg)
+		s.Start()
+	}
 
 	// Start the command
 	err = cmd.Start()
 	if err != nil {
-		s.Stop()
+		if s != nil {
+			s.Stop()
+		}
 		return fmt.Errorf("error starting terraform command: %w", err)
 	}
 
 	// Start a goroutine to periodically update the spinner message with status
 	statusCtx, statusCancel := context.WithCancel(ctxTyped)
-	go func() {
-		ticker := time.NewTicker(5 * time.Second)
-		defer ticker.Stop()
-		counter := 0
-		for {
-			select {
-			case <-statusCtx.Done():
-				return
-			case <-ticker.C:
-				counter++
-				s.UpdateMessage(fmt.Sprintf("%s (running for %ds)", spinnerMsg, counter*5))
+	if s != nil {
+		go func() {
+			ticker := time.NewTicker(5 * time.Second)
+			defer ticker.Stop()
+			counter := 0
+			for {
+				select {
+				case <-statusCtx.Done():
+					return
+				case <-ticker.C:
+					counter++
+					s.UpdateMessage(fmt.Sprintf("%s (running for %ds)", spinnerMsg, counter*5))
+				}
 			}
-		}
-	}()
+		}()
+	}
 
 	// Wait for the command to finish
 	cmdErr := cmd.Wait()
@@ -164,7 +270,9 @@ func (e *CommandExecutor) RunCommand(ctx interface{}, args []string, spinnerMsg
 	progressWg.Wait()
 
 	// Stop the spinner
-	s.Stop()
+	if s != nil {
+		s.Stop()
+	}
 
 	if cmdErr != nil {
 		e.notifyProgress(fmt.Sprintf("Command failed: %v", cmdErr))
@@ -178,6 +286,218 @@ func (e *CommandExecutor) RunCommand(ctx interface{}, args []string, spinnerMsg
 	return nil
 }
 
+// Output executes a terraform command and returns its standard output.
+// Standard error is passed through to the terminal. No spinner is shown.
+func (e *CommandExecutor) Output(ctx interface{}, args []string) ([]byte, error) {
+	ctxTyped, ok := ctx.(context.Context)
+	if !ok {
+		return nil, fmt.Errorf("context type assertion failed")
+	}
+
+	cmd := e.command(ctxTyped, args...)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// RunInteractive executes a terraform command attached to the terminal, without a spinner.
+// It is meant for commands whose output the user reads directly or that may prompt for input.
+func (e *CommandExecutor) RunInteractive(ctx interface{}, args []string) error {
+	ctxTyped, ok := ctx.(context.Context)
+	if !ok {
+		return fmt.Errorf("context type assertion failed")
+	}
+
+	cmd := e.command(ctxTyped, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Stream executes a terraform command with -json and sends the typed event of
+// each message of its machine-readable output to events, in order, without
+// closing the channel. The command runs with -input=false, as -json implies,
+// and does not read from stdin. Cancelling ctx
+// interrupts terraform, letting it stop gracefully as it does on Ctrl+C.
+// A failing command that reported error diagnostics returns a *DiagnosticsError.
+func (e *CommandExecutor) Stream(ctx interface{}, args []string, events chan<- uijson.Event) error {
+	ctxTyped, ok := ctx.(context.Context)
+	if !ok {
+		return fmt.Errorf("context type assertion failed")
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no terraform command to stream")
+	}
+
+	jsonArgs := append([]string{args[0], "-json", "-input=false"}, args[1:]...)
+	cmd := e.command(ctxTyped, jsonArgs...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("error creating stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting terraform command: %w", err)
+	}
+
+	// Collect the error diagnostics on their way to the caller
+	var failures []uijson.Diagnostic
+	forwarded := make(chan uijson.Event)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event := range forwarded {
+			if diagnostic, ok := event.(*uijson.DiagnosticEvent); ok && diagnostic.Severity == "error" {
+				failures = append(failures, diagnostic.Diagnostic)
+			}
+			events <- event
+		}
+	}()
+	uijson.Publish(stdout, forwarded)
+	close(forwarded)
+	<-done
+
+	// Keep terraform from blocking on a full pipe if reading stopped early
+	io.Copy(io.Discard, stdout)
+
+	if err := cmd.Wait(); err != nil {
+		if len(failures) > 0 {
+			return NewDiagnosticsError(args[0], failures, err)
+		}
+		return fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return nil
+}
+
+// jsonCommands are the commands whose output RunCommand follows through -json
+// when the binary supports it, with the feature gating the flag.
+var jsonCommands = map[string]Feature{"plan": FeaturePlanJSON, "apply": FeatureApplyJSON, "init": FeatureInitJSON}
+
+// streamable reports whether RunCommand can run args with -json: the version
+// of the binary must accept the flag for the command, and apply needs a plan
+// file or -auto-approve since terraform cannot ask for approval in JSON mode.
+// Neither can it ask for input, such as the value of a variable, so commands
+// that may do so keep their human-readable output when run from a terminal.
+func (e *CommandExecutor) streamable(ctx interface{}, args []string) bool {
+	if len(args) == 0 || slices.Contains(args, "-json") {
+		return false
+	}
+	feature, ok := jsonCommands[args[0]]
+	if !ok {
+		return false
+	}
+	planFile := args[0] == "apply" && slices.ContainsFunc(args[1:], func(arg string) bool { return !strings.HasPrefix(arg, "-") })
+	if args[0] == "apply" && !planFile && !slices.Contains(args, "-auto-approve") {
+		return false
+	}
+	// A plan file holds the values of the variables already
+	if !planFile && !e.quiet && utils.IsTerminal(os.Stdin) {
+		return false
+	}
+	return Supports(ctx, e, feature)
+}
+
+// runJSON runs a command with -json and publishes its events to the spinner,
+// the progress callbacks and the display of warnings and errors.
+func (e *CommandExecutor) runJSON(ctx context.Context, args []string, spinnerMsg string) error {
+	var s *spinner.Spinner
+	if !e.quiet {
+		s = spinner.New(spinnerMsg)
+		s.Start()
+	}
+
+	events := make(chan uijson.Event, 64)
+	result := make(chan error, 1)
+	go func() {
+		result <- e.Stream(ctx, args, events)
+		close(events)
+	}()
+
+	started := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var status commandStatus
+	for events != nil {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			e.consume(event, &status)
+		case <-ticker.C:
+		}
+		if s != nil {
+			s.UpdateMessage(spinnerStatus(spinnerMsg, status.text, time.Since(started)))
+		}
+	}
+	err := <-result
+
+	if s != nil {
+		s.Stop()
+	}
+	if !e.quiet {
+		for _, warning := range status.warnings {
+			fmt.Fprintf(os.Stderr, "%sWarning: %s%s\n", ui.ColorWarning, warning.Summary, ui.ColorReset)
+		}
+	}
+
+	if err != nil {
+		e.notifyProgress(fmt.Sprintf("Command failed: %v", errors.Unwrap(err)))
+		return err
+	}
+	return nil
+}
+
+// commandStatus is what the events of a running command have reported so far.
+type commandStatus struct {
+	text     string // Latest status, shown next to the spinner
+	planned  int    // Number of planned changes
+	warnings []uijson.Diagnostic
+}
+
+// consume updates the status of a running command with one of its events and
+// passes it on to the event callbacks, and apply progress to the progress callbacks.
+func (e *CommandExecutor) consume(event uijson.Event, status *commandStatus) {
+	for _, callback := range e.eventCallbacks {
+		callback(event)
+	}
+
+	switch event := event.(type) {
+	case *uijson.PlannedChangeEvent:
+		status.planned++
+		status.text = fmt.Sprintf("%d changes planned", status.planned)
+	case *uijson.ApplyStartEvent:
+		status.text = event.Text()
+	case *uijson.ApplyProgressEvent, *uijson.ApplyCompleteEvent, *uijson.ApplyErroredEvent:
+		status.text = event.Text()
+		e.notifyProgress(event.Text())
+	case *uijson.ChangeSummaryEvent:
+		if event.Operation != "plan" {
+			e.notifyProgress(event.Text())
+		}
+	case *uijson.DiagnosticEvent:
+		if event.Severity == "warning" {
+			status.warnings = append(status.warnings, event.Diagnostic)
+		}
+	}
+}
+
+// spinnerStatus returns the spinner message of a command run with -json.
+func spinnerStatus(message, status string, elapsed time.Duration) string {
+	message = fmt.Sprintf("%s (running for %ds)", message, int(elapsed.Seconds()))
+	if status != "" {
+		message += ": " + status
+	}
+	return message
+}
+
 // processOutputForProgress monitors the command output for progress indicators
 func (e *CommandExecutor) processOutputForProgress(reader io.Reader, source string) {
 	scanner := bufio.NewScanner(reader)
@@ -0,0 +1,215 @@
+package terraform
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/testutil"
+	"tfapp/internal/uijson"
+)
+
+func TestRunCommandPassesArguments(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("init", testutil.Response{Stdout: "Terraform has been successfully initialized!\n"})
+
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+
+	if err := executor.RunCommand(context.Background(), []string{"init", "-upgrade"}, "Running init", false); err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+
+	want := [][]string{{"version", "-json"}, {"init", "-upgrade"}}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestRunCommandFailureIncludesOutput(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("plan", testutil.Response{
+		Stdout:   "Planning...\n",
+		Stderr:   "Error: Unsupported argument\n",
+		ExitCode: 1,
+	})
+
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+
+	err := executor.RunCommand(context.Background(), []string{"plan"}, "Planning", false)
+	if err == nil {
+		t.Fatal("RunCommand succeeded, want error")
+	}
+	for _, want := range []string{"Planning...", "Error: Unsupported argument", "exit status 1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %q", err, want)
+		}
+	}
+}
+
+func TestRunCommandRequiresContext(t *testing.T) {
+	executor := NewCommandExecutor()
+	if err := executor.RunCommand("not a context", []string{"plan"}, "Planning", false); err == nil {
+		t.Fatal("RunCommand accepted a non-context value")
+	}
+}
+
+func TestProcessOutputForProgress(t *testing.T) {
+	var updates []string
+	executor := NewCommandExecutor()
+	executor.RegisterProgressCallback(func(status string) {
+		updates = append(updates, status)
+	})
+
+	output := strings.Join([]string{
+		"Terraform will perform the following actions:",
+		"aws_instance.web: Creating...",
+		"aws_instance.web: Still creating... [10s elapsed]",
+		"Plan: 1 to add, 0 to change, 0 to destroy.",
+		"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+	}, "\n")
+	executor.processOutputForProgress(strings.NewReader(output), "stdout")
+
+	want := []string{
+		"aws_instance.web: Still creating... [10s elapsed]",
+		"Apply complete! Resources: 1 added, 0 changed, 0 destroyed.",
+	}
+	if !reflect.DeepEqual(updates, want) {
+		t.Errorf("progress updates = %q, want %q", updates, want)
+	}
+}
+
+func TestStreamDecodesMessages(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("apply", testutil.Response{Stdout: string(testutil.UIFixture(t, "apply_errored")) + "not a message\n", ExitCode: 1})
+
+	events := make(chan uijson.Event, 32)
+	err := NewCommandExecutor().Stream(context.Background(), []string{"apply", "plan.tfplan"}, events)
+	close(events)
+
+	var diagnosticsErr *DiagnosticsError
+	if !errors.As(err, &diagnosticsErr) || !strings.Contains(err.Error(), "Cannot upgrade postgres from 15.4 to 16.1") {
+		t.Errorf("Stream error = %v, want the error diagnostic", err)
+	}
+
+	var received []uijson.Event
+	for event := range events {
+		received = append(received, event)
+	}
+	// Every fixture message but the version one
+	if len(received) != 14 {
+		t.Fatalf("got %d events, want 14", len(received))
+	}
+	if _, ok := received[0].(*uijson.ApplyStartEvent); !ok {
+		t.Errorf("first event is %T, want *uijson.ApplyStartEvent", received[0])
+	}
+	if !fake.CalledWith("apply", "-json", "-input=false", "plan.tfplan") {
+		t.Errorf("terraform was not called with -json: %q", fake.Calls())
+	}
+}
+
+func TestRunCommandStreamsJSON(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("Terraform", "1.9.5")
+	fake.On("plan", testutil.Response{Stdout: string(testutil.UIFixture(t, "plan"))})
+
+	planFile := filepath.Join(t.TempDir(), "plan.tfplan")
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+	for range 2 {
+		if err := executor.RunCommand(context.Background(), []string{"plan", "-out", planFile}, "Planning", false); err != nil {
+			t.Fatalf("RunCommand returned error: %v", err)
+		}
+	}
+
+	// The version, which decides whether -json is supported, is detected once
+	want := [][]string{
+		{"version", "-json"},
+		{"version"},
+		{"plan", "-json", "-input=false", "-out", planFile},
+		{"plan", "-json", "-input=false", "-out", planFile},
+	}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestRunCommandJSONFailureReportsDiagnostics(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("Terraform", "1.9.5")
+	fake.On("plan", testutil.Response{Stdout: string(testutil.UIFixture(t, "plan_errored")), ExitCode: 1})
+
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+	err := executor.RunCommand(context.Background(), []string{"plan"}, "Planning", false)
+
+	var diagnosticsErr *DiagnosticsError
+	if !errors.As(err, &diagnosticsErr) {
+		t.Fatalf("RunCommand error = %v, want a *DiagnosticsError", err)
+	}
+	if len(diagnosticsErr.Diagnostics) != 1 {
+		t.Errorf("got %d diagnostics, want only the error", len(diagnosticsErr.Diagnostics))
+	}
+	want := "Error: Reference to undeclared input variable\n\nAn input variable with the name \"region\" has not been declared."
+	if !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("error = %q, want it to start with %q", err, want)
+	}
+	if strings.Contains(err.Error(), "@level") {
+		t.Errorf("error contains raw JSON: %q", err)
+	}
+}
+
+func TestRunCommandApplyWithoutPlanIsNotStreamed(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("Terraform", "1.9.5")
+
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+	if err := executor.RunCommand(context.Background(), []string{"apply", "-target=aws_instance.web"}, "Applying", false); err != nil {
+		t.Fatalf("RunCommand returned error: %v", err)
+	}
+
+	// Terraform cannot ask for approval in JSON mode
+	want := [][]string{{"apply", "-target=aws_instance.web"}}
+	if got := fake.Calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("calls = %q, want %q", got, want)
+	}
+}
+
+func TestConsumeEvents(t *testing.T) {
+	var updates []string
+	executor := NewCommandExecutor()
+	executor.RegisterProgressCallback(func(status string) {
+		updates = append(updates, status)
+	})
+
+	var status commandStatus
+	for _, fixture := range []string{"plan", "plan_errored", "apply_errored"} {
+		for _, line := range strings.Split(strings.TrimSpace(string(testutil.UIFixture(t, fixture))), "\n") {
+			msg, err := uijson.Decode([]byte(line))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event := msg.Event(); event != nil {
+				executor.consume(event, &status)
+			}
+		}
+	}
+
+	if status.planned != 2 {
+		t.Errorf("planned = %d, want 2", status.planned)
+	}
+	if len(status.warnings) != 1 || status.warnings[0].Summary != "Argument is deprecated" {
+		t.Errorf("warnings = %+v", status.warnings)
+	}
+	if status.text != "aws_db_instance.main: Creation errored after 5s" {
+		t.Errorf("status = %q", status.text)
+	}
+	if len(updates) != 7 || updates[0] != "aws_iam_role.legacy: Destruction complete after 1s" {
+		t.Errorf("progress updates = %q", updates)
+	}
+}
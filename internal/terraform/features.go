@@ -0,0 +1,91 @@
+package terraform
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/models"
+)
+
+// Feature is a plan flag, or a flag of another command, whose support depends
+// on the product and version of the binary.
+type Feature string
+
+// Features gated by version.
+const (
+	FeatureExclude           Feature = "-exclude"
+	FeatureGenerateConfigOut Feature = "-generate-config-out"
+	FeaturePlanJSON          Feature = "plan -json"
+	FeatureApplyJSON         Feature = "apply -json"
+	FeatureInitJSON          Feature = "init -json"
+)
+
+// featureVersions holds the first version of each product supporting a
+// feature. Products left out do not support it.
+var featureVersions = map[Feature]map[Product]string{
+	FeatureExclude: {
+		ProductOpenTofu: "1.9.0",
+	},
+	FeatureGenerateConfigOut: {
+		ProductTerraform: "1.5.0",
+		ProductOpenTofu:  "1.6.0",
+	},
+	FeaturePlanJSON: {
+		ProductTerraform: "0.15.3",
+		ProductOpenTofu:  "1.6.0",
+	},
+	FeatureApplyJSON: {
+		ProductTerraform: "0.15.3",
+		ProductOpenTofu:  "1.6.0",
+	},
+	FeatureInitJSON: {
+		ProductTerraform: "1.9.0",
+		ProductOpenTofu:  "1.10.0",
+	},
+}
+
+// Supports reports whether this version of the product supports a feature.
+func (v *VersionInfo) Supports(feature Feature) bool {
+	since, ok := featureVersions[feature][v.Product]
+	return ok && v.AtLeast(since)
+}
+
+// Supports reports whether the binary the executor runs supports a feature.
+// A feature is taken as unsupported when the version cannot be detected.
+func Supports(ctx interface{}, executor models.Executor, feature Feature) bool {
+	info, err := DetectVersion(ctx, executor)
+	return err == nil && info.Supports(feature)
+}
+
+// CheckFlags returns an error if args use a gated flag that the binary the
+// executor runs does not support. When the version cannot be detected the
+// flags are left for the binary to reject.
+func CheckFlags(ctx interface{}, executor models.Executor, args []string) error {
+	var used []Feature
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if _, gated := featureVersions[Feature(name)]; gated {
+			used = append(used, Feature(name))
+		}
+	}
+	if len(used) == 0 {
+		return nil
+	}
+
+	info, err := DetectVersion(ctx, executor)
+	if err != nil {
+		return nil
+	}
+	for _, feature := range used {
+		if info.Supports(feature) {
+			continue
+		}
+		message := fmt.Sprintf("%s is not supported by %s", feature, info)
+		if since, ok := featureVersions[feature][info.Product]; ok {
+			message = fmt.Sprintf("%s needs %s %s or later, found %s", feature, info.Product, since, info.Version)
+		}
+		return apperrors.NewValidationError(string(feature), message, apperrors.ErrInvalidInput)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package terraform
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestSupportsByVersion(t *testing.T) {
+	for _, tc := range []struct {
+		product Product
+		version string
+		feature Feature
+		want    bool
+	}{
+		{ProductOpenTofu, "1.9.0", FeatureExclude, true},
+		{ProductOpenTofu, "1.10.0-beta1", FeatureExclude, true},
+		{ProductOpenTofu, "1.8.5", FeatureExclude, false},
+		{ProductTerraform, "1.9.5", FeatureExclude, false},
+		{ProductTerraform, "1.5.0", FeatureGenerateConfigOut, true},
+		{ProductTerraform, "1.4.6", FeatureGenerateConfigOut, false},
+		{ProductOpenTofu, "1.6.0", FeatureGenerateConfigOut, true},
+		{ProductTerraform, "0.15.3", FeaturePlanJSON, true},
+		{ProductTerraform, "0.14.11", FeatureApplyJSON, false},
+		{ProductTerraform, "1.8.5", FeatureInitJSON, false},
+		{ProductTerraform, "1.9.0", FeatureInitJSON, true},
+	} {
+		info := &VersionInfo{Version: tc.version, Product: tc.product}
+		if got := info.Supports(tc.feature); got != tc.want {
+			t.Errorf("%s supports %s = %v, want %v", info, tc.feature, got, tc.want)
+		}
+	}
+}
+
+func TestSupportsDetectsVersionOnce(t *testing.T) {
+	fake := testutil.InstallFakeTofu(t)
+	fake.OnVersion("OpenTofu", "1.9.1")
+
+	executor := NewCommandExecutor()
+	executor.SetBinary("tofu")
+	if !Supports(context.Background(), executor, FeatureExclude) {
+		t.Error("OpenTofu 1.9.1 reported without -exclude")
+	}
+	if !Supports(context.Background(), executor, FeatureGenerateConfigOut) {
+		t.Error("OpenTofu 1.9.1 reported without -generate-config-out")
+	}
+	// version -json, then version for the product
+	if calls := fake.Calls(); len(calls) != 2 {
+		t.Errorf("version detected %d times: %q", len(calls), calls)
+	}
+}
+
+func TestSupportsWithoutVersion(t *testing.T) {
+	fake := testutil.InstallFakeTofu(t)
+	fake.On("version", testutil.Response{Stderr: "unknown command", ExitCode: 1})
+
+	executor := NewCommandExecutor()
+	executor.SetBinary("tofu")
+	if Supports(context.Background(), executor, FeatureExclude) {
+		t.Error("-exclude reported as supported without a version")
+	}
+}
+
+func TestCheckFlags(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("Terraform", "1.4.6")
+	executor := NewCommandExecutor()
+
+	if err := CheckFlags(context.Background(), executor, []string{"-var=a=b"}); err != nil {
+		t.Errorf("CheckFlags without gated flags returned error: %v", err)
+	}
+	if fake.CalledWith("version") {
+		t.Error("version detected without gated flags")
+	}
+
+	err := CheckFlags(context.Background(), executor, []string{"-generate-config-out=generated.tf"})
+	if err == nil || !strings.Contains(err.Error(), "needs Terraform 1.5.0 or later, found 1.4.6") {
+		t.Errorf("CheckFlags error = %v", err)
+	}
+	err = CheckFlags(context.Background(), executor, []string{"-exclude=aws_instance.web"})
+	if err == nil || !strings.Contains(err.Error(), "-exclude is not supported by Terraform 1.4.6") {
+		t.Errorf("CheckFlags error = %v", err)
+	}
+}
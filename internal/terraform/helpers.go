@@ -1,12 +1,12 @@
 package terraform
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 
 	"tfapp/internal/models"
+	"tfapp/internal/planjson"
 	"tfapp/internal/ui"
 
 	"github.com/charmbracelet/lipgloss"
@@ -14,25 +14,22 @@ import (
 
 // DisplayPlanSummary displays a summary of a Terraform plan and returns the identified resources.
 // It supports both regular and drifted resources with consistent styling.
-func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Resource, error) {
-	// Get plan details in JSON format
-	tfshow := exec.CommandContext(ctx, "terraform", "show", "-json", planFilePath)
-	output, err := tfshow.Output()
+func (p *PlanManager) DisplayPlanSummary(ctx interface{}, planFilePath string) ([]models.Resource, error) {
+	plan, err := p.LoadPlan(ctx, planFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("error showing plan in JSON format: %w", err)
+		return nil, err
 	}
 
-	// Parse the JSON output
-	var plan TerraformPlan
-	if err := json.Unmarshal(output, &plan); err != nil {
-		return nil, fmt.Errorf("error parsing plan JSON: %w", err)
-	}
+	return p.writeSummary(os.Stdout, plan), nil
+}
 
+// WritePlanSummary writes the human-readable plan summary to w and returns the identified resources.
+func WritePlanSummary(w io.Writer, plan *planjson.Plan) []models.Resource {
 	var resources []models.Resource
 
 	// Process resource drift if present
 	if len(plan.ResourceDrift) > 0 {
-		fmt.Printf("\n%s%sResources that have changed outside of Terraform:%s\n",
+		fmt.Fprintf(w, "\n%s%sResources that have changed outside of Terraform:%s\n",
 			ui.TextBold,
 			ui.ColorCyan,
 			ui.ColorReset)
@@ -43,7 +40,7 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 			}
 
 			resourceName := drift.Address
-			action := "drift:" + mapActions(drift.Change.Actions)
+			action := "drift:" + drift.Change.Actions.Summary()
 
 			// Generate a human-friendly line for drift
 			line := fmt.Sprintf("# %s has drifted", resourceName)
@@ -61,12 +58,12 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 				Foreground(lipgloss.Color("#FF9900")). // Orange color for drift phrase only
 				Render(driftText)
 			colorizedLine := resourcePrefix + colorizedDriftText
-			fmt.Println(colorizedLine)
+			fmt.Fprintln(w, colorizedLine)
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
 	}
 
-	fmt.Printf("\n%s%sSummary of proposed changes:%s\n",
+	fmt.Fprintf(w, "\n%s%sSummary of proposed changes:%s\n",
 		ui.TextBold,
 		ui.ColorCyan,
 		ui.ColorReset)
@@ -80,17 +77,16 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 
 	// Process each resource change
 	for _, change := range plan.ResourceChanges {
-		if len(change.Change.Actions) == 0 || change.Change.Actions[0] == "no-op" {
+		if change.Change.Actions.IsNoOp() && !change.MovedOnly() {
 			continue
 		}
 
 		resourceName := change.Address
-		action := mapActions(change.Change.Actions)
+		action := changeAction(change)
 
 		// Check if this is a moved resource
-		wasMoved := false
-		if change.PreviousAddress != "" && change.PreviousAddress != change.Address {
-			wasMoved = true
+		wasMoved := change.Moved()
+		if wasMoved {
 			moves++
 		}
 
@@ -110,13 +106,15 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 
 		// Generate a human-friendly line similar to the text output
 		var line string
-		if wasMoved {
+		if change.MovedOnly() {
+			line = fmt.Sprintf("# %s has moved to %s", change.PreviousAddress, resourceName)
+		} else if wasMoved {
 			// Add information about the move
 			line = fmt.Sprintf("# %s will be %s (moved from %s)",
 				resourceName, getGrammaticalAction(action), change.PreviousAddress)
 		} else if change.ActionReason != "" {
 			// Include action reason if available
-			reasonText := getActionReasonText(change.ActionReason)
+			reasonText := planjson.ActionReasonDescription(change.ActionReason)
 			line = fmt.Sprintf("# %s will be %s (%s)",
 				resourceName, getGrammaticalAction(action), reasonText)
 		} else {
@@ -130,7 +128,7 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 		})
 
 		// Display the line with appropriate color
-		fmt.Println(ui.Colorize(line))
+		fmt.Fprintln(w, ui.Colorize(line))
 	}
 
 	// Display plan summary
@@ -138,10 +136,19 @@ func DisplayPlanSummary(ctx context.Context, planFilePath string) ([]models.Reso
 	if moves > 0 {
 		summary += fmt.Sprintf(" (%d resources moved)", moves)
 	}
-	fmt.Println(ui.Colorize(summary))
-	fmt.Println()
+	fmt.Fprintln(w, ui.Colorize(summary))
+	fmt.Fprintln(w)
 
-	return resources, nil
+	return resources
+}
+
+// changeAction returns the action of a resource change as a single verb,
+// "move" for a resource moved without any other change.
+func changeAction(change planjson.ResourceChange) string {
+	if change.MovedOnly() {
+		return "move"
+	}
+	return change.Change.Actions.Summary()
 }
 
 // getGrammaticalAction returns the grammatically correct form of an action
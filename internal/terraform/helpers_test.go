@@ -0,0 +1,25 @@
+package terraform
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func TestPlanSummaryGolden(t *testing.T) {
+	for _, name := range testutil.PlanFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			plan, err := planjson.Parse(testutil.PlanFixture(t, name))
+			if err != nil {
+				t.Fatalf("parsing fixture: %v", err)
+			}
+
+			var out bytes.Buffer
+			WritePlanSummary(&out, plan)
+			testutil.Golden(t, filepath.Join("summary", name), out.Bytes())
+		})
+	}
+}
@@ -1,91 +1,108 @@
 package terraform
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"strings"
+	"slices"
 
+	"tfapp/internal/cost"
+	apperrors "tfapp/internal/errors"
 	"tfapp/internal/models"
+	"tfapp/internal/planjson"
+	"tfapp/internal/policy"
+	"tfapp/internal/risk"
 	"tfapp/internal/ui"
 	"tfapp/internal/ui/plan"
 )
 
-// JSON structs for parsing terraform plan output
-type TerraformPlan struct {
-	ResourceChanges []ResourceChange `json:"resource_changes"`
-	PlannedValues   PlannedValues    `json:"planned_values"`
-	ResourceDrift   []ResourceChange `json:"resource_drift"`
-	FormatVersion   string           `json:"format_version"`
-	Applyable       bool             `json:"applyable"`
-	Complete        bool             `json:"complete"`
-	Errored         bool             `json:"errored"`
+// PlanManager handles Terraform plan operations.
+type PlanManager struct {
+	executor models.Executor
+	cache    *PlanCache
+	quiet    bool // Suppress human-readable output and never exit the process
+	policy   *policy.Policy
+	catalog  *cost.Catalog
+	risk     *risk.Scorer
+	args     []string // Added to every plan, before the caller's arguments
 }
 
-type PlannedValues struct {
-	RootModule RootModule `json:"root_module"`
+// NewPlanManager creates a new Terraform plan manager.
+func NewPlanManager(executor models.Executor) *PlanManager {
+	return &PlanManager{
+		executor: executor,
+		cache:    NewPlanCache(),
+	}
 }
 
-type RootModule struct {
-	Resources    []Resource    `json:"resources"`
-	ChildModules []ChildModule `json:"child_modules"`
+// SetQuiet enables or disables quiet mode.
+// In quiet mode CreatePlan prints nothing, leaving reporting to the caller.
+func (p *PlanManager) SetQuiet(quiet bool) {
+	p.quiet = quiet
 }
 
-type ChildModule struct {
-	Resources []Resource `json:"resources"`
-	Address   string     `json:"address"`
+// SetDefaultArgs sets the arguments added to every plan, such as -var-file
+// flags, before the arguments CreatePlan is given.
+func (p *PlanManager) SetDefaultArgs(args []string) {
+	p.args = args
 }
 
-type Resource struct {
-	Address      string `json:"address"`
-	Type         string `json:"type"`
-	Name         string `json:"name"`
-	ProviderName string `json:"provider_name"`
+// SetPolicy sets the policy checked in plan summaries and annotated in the plan viewer.
+func (p *PlanManager) SetPolicy(policy *policy.Policy) {
+	p.policy = policy
 }
 
-type ResourceChange struct {
-	Address         string      `json:"address"`
-	PreviousAddress string      `json:"previous_address,omitempty"`
-	ModuleAddress   string      `json:"module_address,omitempty"`
-	Mode            string      `json:"mode"`
-	Type            string      `json:"type"`
-	Name            string      `json:"name"`
-	Index           interface{} `json:"index,omitempty"` // Can be int or string
-	Deposed         string      `json:"deposed,omitempty"`
-	Change          Change      `json:"change"`
-	ActionReason    string      `json:"action_reason,omitempty"`
+// SetCatalog sets the pricing catalog used to estimate cost changes in plan summaries and the plan viewer.
+func (p *PlanManager) SetCatalog(catalog *cost.Catalog) {
+	p.catalog = catalog
 }
 
-type Change struct {
-	Actions         []string    `json:"actions"`
-	Before          interface{} `json:"before"`
-	After           interface{} `json:"after"`
-	AfterUnknown    interface{} `json:"after_unknown,omitempty"`
-	BeforeSensitive interface{} `json:"before_sensitive,omitempty"`
-	AfterSensitive  interface{} `json:"after_sensitive,omitempty"`
-	ReplacePaths    [][]string  `json:"replace_paths,omitempty"`
+// SetRisk sets the scorer used to list high risk changes in plan summaries and badge them in the plan viewer.
+func (p *PlanManager) SetRisk(scorer *risk.Scorer) {
+	p.risk = scorer
 }
 
-// PlanManager handles Terraform plan operations.
-type PlanManager struct {
-	executor models.Executor
+// LoadPlan runs `terraform show -json` on a saved plan file and parses the result.
+// The parsed plan is cached until the plan file changes.
+func (p *PlanManager) LoadPlan(ctx interface{}, planFilePath string) (*planjson.Plan, error) {
+	if cached, ok := p.cache.Get(planFilePath); ok {
+		return cached, nil
+	}
+
+	output, err := p.PlanJSON(ctx, planFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := planjson.Parse(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// A plan that cannot be cached is still usable, it will just be parsed again next time
+	_ = p.cache.Put(planFilePath, plan)
+	return plan, nil
 }
 
-// NewPlanManager creates a new Terraform plan manager.
-func NewPlanManager(executor models.Executor) *PlanManager {
-	return &PlanManager{
-		executor: executor,
+// PlanJSON returns the output of `terraform show -json` on a saved plan file,
+// with every field terraform emits.
+func (p *PlanManager) PlanJSON(ctx interface{}, planFilePath string) ([]byte, error) {
+	output, err := p.executor.Output(ctx, []string{"show", "-json", planFilePath})
+	if err != nil {
+		return nil, fmt.Errorf("error showing plan in JSON format: %w", err)
 	}
+	return output, nil
 }
 
 // CreatePlan generates a Terraform plan and returns a list of affected resources.
 // It saves the plan to the specified file path and runs `terraform plan`.
+// If the plan has no changes, it returns errors.ErrNoChanges. The default
+// arguments come before args. Flags that the installed version does not
+// support are refused before planning.
 func (p *PlanManager) CreatePlan(ctx interface{}, planFilePath string, args []string, targeted bool) ([]models.Resource, error) {
-	ctxTyped, ok := ctx.(context.Context)
-	if !ok {
-		return nil, fmt.Errorf("context type assertion failed")
+	args = append(slices.Clone(p.args), args...)
+	if err := CheckFlags(ctx, p.executor, args); err != nil {
+		return nil, err
 	}
 
 	planArgs := []string{"plan", "-out", planFilePath}
@@ -102,22 +119,41 @@ func (p *PlanManager) CreatePlan(ctx interface{}, planFilePath string, args []st
 		return nil, fmt.Errorf("error executing terraform plan: %w", err)
 	}
 
-	fmt.Printf("%s%sTerraform plan has been successfully created!%s\n",
-		ui.ColorSuccess, ui.TextBold, ui.ColorReset)
-
-	// Get plan details in JSON format
-	tfshow := exec.CommandContext(ctxTyped, "terraform", "show", "-json", planFilePath)
-	tfshow.Stderr = os.Stderr
-	output, err := tfshow.Output()
+	plan, err := p.LoadPlan(ctx, planFilePath)
 	if err != nil {
-		return nil, fmt.Errorf("error showing plan in JSON format: %w", err)
+		return nil, err
+	}
+
+	if !p.quiet {
+		p.printPlanStatus(plan)
+	}
+
+	if !plan.HasChanges() {
+		return nil, apperrors.ErrNoChanges
 	}
 
-	// Parse the JSON output
-	var plan TerraformPlan
-	if err := json.Unmarshal(output, &plan); err != nil {
-		return nil, fmt.Errorf("error parsing plan JSON: %w", err)
+	var out io.Writer = os.Stdout
+	if p.quiet {
+		out = io.Discard
 	}
+	return p.writeSummary(out, plan), nil
+}
+
+// writeSummary writes the selected workspace, the high risk changes, the plan
+// summary, the cost estimate and the policy check results.
+func (p *PlanManager) writeSummary(w io.Writer, plan *planjson.Plan) []models.Resource {
+	WriteWorkspace(w, WorkspaceOf(p.executor))
+	WriteHighRisk(w, p.risk.Assess(plan))
+	resources := WritePlanSummary(w, plan)
+	WriteCostEstimate(w, p.catalog.Estimate(plan))
+	WritePolicyReport(w, p.policy.Check(plan))
+	return resources
+}
+
+// printPlanStatus reports plan creation and any warnings from the plan metadata.
+func (p *PlanManager) printPlanStatus(plan *planjson.Plan) {
+	fmt.Printf("%s%sTerraform plan has been successfully created!%s\n",
+		ui.ColorSuccess, ui.TextBold, ui.ColorReset)
 
 	// Check plan metadata
 	if plan.Errored {
@@ -134,30 +170,6 @@ func (p *PlanManager) CreatePlan(ctx interface{}, planFilePath string, args []st
 		fmt.Printf("%s%sNote: This plan is incomplete. After applying, you will need to run plan again.%s\n",
 			ui.ColorInfo, ui.TextBold, ui.ColorReset)
 	}
-
-	// Check if there are no changes
-	if len(plan.ResourceChanges) == 0 {
-		fmt.Printf("%s%sNo changes detected in plan. Your infrastructure is up-to-date.%s\n",
-			ui.ColorInfo, ui.TextBold, ui.ColorReset)
-		os.Exit(0)
-	}
-
-	changing := false
-	for _, resource := range plan.ResourceChanges {
-		if len(resource.Change.Actions) > 0 && resource.Change.Actions[0] != "no-op" {
-			changing = true
-			break
-		}
-	}
-
-	if !changing {
-		fmt.Printf("%s%sNo changes detected in plan. Your infrastructure is up-to-date.%s\n",
-			ui.ColorInfo, ui.TextBold, ui.ColorReset)
-		os.Exit(0)
-	}
-
-	// Use the unified DisplayPlanSummary function to show and return resources
-	return DisplayPlanSummary(ctxTyped, planFilePath)
 }
 
 // formatResourceChangeLine generates a human-readable line for a resource change
@@ -178,74 +190,25 @@ func formatResourceChangeLine(resourceName, action string) string {
 	return line
 }
 
-// mapActions maps the array of actions to a single action string
-func mapActions(actions []string) string {
-	if contains(actions, "create") && contains(actions, "delete") {
-		return "replace"
-	} else if contains(actions, "create") {
-		return "create"
-	} else if contains(actions, "delete") {
-		return "destroy"
-	} else if contains(actions, "update") {
-		return "update"
-	}
-	return strings.Join(actions, "/")
-}
-
-// contains checks if a string slice contains a specific string
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
-
-// getActionReasonText returns a human-readable description of the action reason
-func getActionReasonText(reason string) string {
-	switch reason {
-	case "replace_because_tainted":
-		return "tainted, so must be replaced"
-	case "replace_because_cannot_update":
-		return "cannot be updated in-place"
-	case "replace_by_request":
-		return "replacement requested"
-	case "delete_because_no_resource_config":
-		return "no resource configuration found"
-	case "delete_because_no_module":
-		return "containing module is gone"
-	case "delete_because_wrong_repetition":
-		return "wrong repetition mode"
-	case "delete_because_count_index":
-		return "count index out of range"
-	case "delete_because_each_key":
-		return "for_each key not found"
-	case "read_because_config_unknown":
-		return "configuration contains unknown values"
-	case "read_because_dependency_pending":
-		return "has pending dependent resources"
-	default:
-		return reason
-	}
-}
-
 // ShowPlan displays the full details of a saved plan file.
 func (p *PlanManager) ShowPlan(ctx interface{}, planFilePath string) error {
-	ctxTyped, ok := ctx.(context.Context)
-	if !ok {
-		return fmt.Errorf("context type assertion failed")
-	}
-
-	tfshow := exec.CommandContext(ctxTyped, "terraform", "show", "-json", planFilePath)
-	tfshow.Stderr = os.Stderr
-	output, err := tfshow.Output()
+	parsedPlan, err := p.LoadPlan(ctx, planFilePath)
 	if err != nil {
 		return fmt.Errorf("error showing plan: %w", err)
 	}
 
+	annotations := PolicyAnnotations(p.policy.Check(parsedPlan))
+	for _, more := range []map[string][]plan.Annotation{
+		RiskAnnotations(p.risk.Assess(parsedPlan)),
+		CostAnnotations(p.catalog.Estimate(parsedPlan)),
+	} {
+		for address, notes := range more {
+			annotations[address] = append(annotations[address], notes...)
+		}
+	}
+
 	// Use the interactive plan viewer
-	return plan.Show(string(output))
+	return plan.ShowPlanAnnotated(parsedPlan, annotations)
 }
 
 var _ models.PlanService = (*PlanManager)(nil)
@@ -0,0 +1,96 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	apperrors "tfapp/internal/errors"
+	"tfapp/internal/models"
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func newQuietPlanManager() *PlanManager {
+	executor := NewCommandExecutor()
+	executor.SetQuiet(true)
+	planManager := NewPlanManager(executor)
+	planManager.SetQuiet(true)
+	return planManager
+}
+
+func TestCreatePlan(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("replace")
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	resources, err := newQuietPlanManager().CreatePlan(context.Background(), planFile, []string{"-var=env=prod"}, false)
+	if err != nil {
+		t.Fatalf("CreatePlan returned error: %v", err)
+	}
+
+	want := []models.Resource{
+		{Name: "aws_db_instance.main", Action: "replace", Line: "# aws_db_instance.main will be replaced (cannot be updated in-place)"},
+		{Name: "aws_s3_bucket.logs", Action: "update", Line: "# aws_s3_bucket.logs will be updated in-place"},
+		{Name: "aws_iam_role.legacy", Action: "destroy", Line: "# aws_iam_role.legacy will be destroyed (no resource configuration found)"},
+		{Name: "aws_instance.cache", Action: "replace", Line: "# aws_instance.cache will be replaced (tainted, so must be replaced)"},
+	}
+	if !reflect.DeepEqual(resources, want) {
+		t.Errorf("resources = %#v, want %#v", resources, want)
+	}
+
+	calls := fake.Calls()
+	wantCalls := [][]string{
+		{"version", "-json"},
+		{"plan", "-out", planFile, "-var=env=prod"},
+		{"show", "-json", planFile},
+	}
+	if !reflect.DeepEqual(calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", calls, wantCalls)
+	}
+}
+
+func TestCreatePlanNoChanges(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnShowPlan("no_changes")
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	_, err := newQuietPlanManager().CreatePlan(context.Background(), planFile, nil, false)
+	if !apperrors.IsErrNoChanges(err) {
+		t.Fatalf("CreatePlan error = %v, want ErrNoChanges", err)
+	}
+}
+
+func TestCreatePlanFailure(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("plan", testutil.Response{Stderr: "Error: No configuration files\n", ExitCode: 1})
+
+	planFile := filepath.Join(t.TempDir(), "terraform.tfplan")
+	_, err := newQuietPlanManager().CreatePlan(context.Background(), planFile, nil, false)
+	if err == nil || !strings.Contains(err.Error(), "No configuration files") {
+		t.Fatalf("CreatePlan error = %v, want terraform's error output", err)
+	}
+	if fake.CalledWith("show") {
+		t.Error("terraform show was called after a failed plan")
+	}
+}
+
+func TestPlanReportGolden(t *testing.T) {
+	for _, name := range testutil.PlanFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			plan, err := planjson.Parse(testutil.PlanFixture(t, name))
+			if err != nil {
+				t.Fatalf("parsing fixture: %v", err)
+			}
+
+			got, err := json.MarshalIndent(NewPlanReport(plan), "", "  ")
+			if err != nil {
+				t.Fatalf("marshalling report: %v", err)
+			}
+			testutil.Golden(t, filepath.Join("report", name), append(got, '\n'))
+		})
+	}
+}
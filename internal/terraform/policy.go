@@ -0,0 +1,83 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+
+	"tfapp/internal/config"
+	"tfapp/internal/policy"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/plan"
+)
+
+// WritePolicyReport writes the protected resource violations and the results
+// of the policy rules. It writes nothing when there is no policy.
+func WritePolicyReport(w io.Writer, report *policy.Report) {
+	WriteProtectedViolations(w, report.Violations)
+	WritePolicyFailures(w, report.Failures, report.Rules)
+}
+
+// WriteProtectedViolations lists the protected resources a plan would destroy or replace.
+// It writes nothing when there are no violations.
+func WriteProtectedViolations(w io.Writer, violations []policy.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "%s%sProtected resources would be destroyed:%s\n", ui.TextBold, ui.ColorError, ui.ColorReset)
+	for _, violation := range violations {
+		action := "destroyed"
+		if violation.Action == "replace" {
+			action = "replaced"
+		}
+		fmt.Fprintf(w, "  %s✗%s %s will be %s (protected by %s)\n",
+			ui.ColorError, ui.ColorReset, violation.Address, action, violation.Rule)
+	}
+	fmt.Fprintln(w)
+}
+
+// WritePolicyFailures lists the resources failing policy rules, or notes that
+// every rule passed. It writes nothing when no rules are configured.
+func WritePolicyFailures(w io.Writer, failures []policy.Failure, rules int) {
+	if rules == 0 {
+		return
+	}
+	if len(failures) == 0 {
+		fmt.Fprintf(w, "%s✓ All %d policy rules passed.%s\n\n", ui.ColorSuccess, rules, ui.ColorReset)
+		return
+	}
+
+	fmt.Fprintf(w, "%s%sPolicy checks failed:%s\n", ui.TextBold, ui.ColorError, ui.ColorReset)
+	for _, failure := range failures {
+		symbol, color := "✗", ui.ColorError
+		if failure.Severity == config.SeverityWarning {
+			symbol, color = "!", ui.ColorWarning
+		}
+		fmt.Fprintf(w, "  %s%s%s %s [%s]: %s\n", color, symbol, ui.ColorReset, failure.Address, failure.Rule, failure.Message())
+	}
+	fmt.Fprintln(w)
+}
+
+// PolicyAnnotations converts a policy report to plan viewer annotations.
+func PolicyAnnotations(report *policy.Report) map[string][]plan.Annotation {
+	annotations := make(map[string][]plan.Annotation)
+	for _, violation := range report.Violations {
+		annotations[violation.Address] = append(annotations[violation.Address], plan.Annotation{
+			Level: "error",
+			Badge: "✗ protected",
+			Text:  fmt.Sprintf("✗ protected by %s", violation.Rule),
+		})
+	}
+	for _, failure := range report.Failures {
+		level, symbol := "error", "✗"
+		if failure.Severity == config.SeverityWarning {
+			level, symbol = "warning", "!"
+		}
+		annotations[failure.Address] = append(annotations[failure.Address], plan.Annotation{
+			Level: level,
+			Badge: symbol + " policy",
+			Text:  fmt.Sprintf("%s policy %s: %s", symbol, failure.Rule, failure.Message()),
+		})
+	}
+	return annotations
+}
@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"tfapp/internal/cost"
+	"tfapp/internal/planjson"
+	"tfapp/internal/policy"
+	"tfapp/internal/risk"
+)
+
+// ReportVersion is the version of the machine-readable plan report format.
+// It is bumped whenever a field is removed or changes meaning.
+const ReportVersion = "1"
+
+// PlanReport is the machine-readable summary of a plan emitted by `tfapp -output=json`.
+type PlanReport struct {
+	ReportVersion string           `json:"report_version"`
+	FormatVersion string           `json:"format_version"`
+	Applyable     bool             `json:"applyable"`
+	Complete      bool             `json:"complete"`
+	Errored       bool             `json:"errored"`
+	HasChanges    bool             `json:"has_changes"`
+	Counts        ReportCounts     `json:"counts"`
+	Resources     []ReportResource `json:"resources"`
+	Drift         []ReportResource `json:"drift"`
+
+	// The policy check results, cost estimate and risk scores are set by the caller, since
+	// the report itself knows nothing of the configuration.
+	ProtectedViolations []policy.Violation `json:"protected_violations,omitempty"`
+	PolicyFailures      []policy.Failure   `json:"policy_failures,omitempty"`
+	CostEstimate        *cost.Estimate     `json:"cost_estimate,omitempty"`
+	Risk                *risk.Assessment   `json:"risk,omitempty"`
+}
+
+// ReportCounts holds the number of changes per action in a plan report.
+type ReportCounts struct {
+	Add     int `json:"add"`
+	Change  int `json:"change"`
+	Destroy int `json:"destroy"`
+	Replace int `json:"replace"`
+	Move    int `json:"move"`
+	Drift   int `json:"drift"`
+}
+
+// ReportResource describes a single changed resource in a plan report.
+type ReportResource struct {
+	Address         string   `json:"address"`
+	PreviousAddress string   `json:"previous_address,omitempty"`
+	ModuleAddress   string   `json:"module_address,omitempty"`
+	Type            string   `json:"type"`
+	Name            string   `json:"name"`
+	Action          string   `json:"action"`
+	Actions         []string `json:"actions"`
+	ActionReason    string   `json:"action_reason,omitempty"`
+	Reason          string   `json:"reason,omitempty"`
+	Moved           bool     `json:"moved"`
+}
+
+// NewPlanReport builds a machine-readable report from a parsed plan.
+// No-op changes are omitted, unless the resource was moved; counts follow the
+// same rules as the text summary.
+func NewPlanReport(plan *planjson.Plan) *PlanReport {
+	report := &PlanReport{
+		ReportVersion: ReportVersion,
+		FormatVersion: plan.FormatVersion,
+		Applyable:     plan.Applyable,
+		Complete:      plan.Complete,
+		Errored:       plan.Errored,
+		HasChanges:    plan.HasChanges(),
+		Resources:     []ReportResource{},
+		Drift:         []ReportResource{},
+	}
+
+	for _, drift := range plan.ResourceDrift {
+		if len(drift.Change.Actions) == 0 {
+			continue
+		}
+		report.Drift = append(report.Drift, newReportResource(drift))
+		report.Counts.Drift++
+	}
+
+	for _, change := range plan.ResourceChanges {
+		if change.Change.Actions.IsNoOp() && !change.MovedOnly() {
+			continue
+		}
+
+		resource := newReportResource(change)
+		if resource.Moved {
+			report.Counts.Move++
+		}
+		if resource.Action == "replace" {
+			report.Counts.Replace++
+		}
+
+		for _, a := range change.Change.Actions {
+			switch a {
+			case "create":
+				report.Counts.Add++
+			case "update":
+				report.Counts.Change++
+			case "delete":
+				report.Counts.Destroy++
+			}
+		}
+
+		report.Resources = append(report.Resources, resource)
+	}
+
+	return report
+}
+
+// newReportResource converts a plan resource change into its report form.
+func newReportResource(change planjson.ResourceChange) ReportResource {
+	resource := ReportResource{
+		Address:         change.Address,
+		PreviousAddress: change.PreviousAddress,
+		ModuleAddress:   change.ModuleAddress,
+		Type:            change.Type,
+		Name:            change.Name,
+		Action:          changeAction(change),
+		Actions:         change.Change.Actions,
+		ActionReason:    change.ActionReason,
+		Moved:           change.Moved(),
+	}
+	if change.ActionReason != "" {
+		resource.Reason = planjson.ActionReasonDescription(change.ActionReason)
+	}
+	return resource
+}
@@ -0,0 +1,57 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"tfapp/internal/risk"
+	"tfapp/internal/ui"
+	"tfapp/internal/ui/plan"
+)
+
+// WriteHighRisk lists the high risk changes of a plan, riskiest first.
+// It writes nothing when no change reaches the high risk threshold.
+func WriteHighRisk(w io.Writer, assessment *risk.Assessment) {
+	WriteRiskScores(w, "High risk changes:", assessment.High())
+}
+
+// WriteRiskScores lists scored changes under a title, with the factors of each score.
+// It writes nothing without scores.
+func WriteRiskScores(w io.Writer, title string, scores []risk.Score) {
+	if len(scores) == 0 {
+		return
+	}
+
+	width := 0
+	for _, score := range scores {
+		width = max(width, len(score.Address))
+	}
+
+	fmt.Fprintf(w, "\n%s%s%s%s\n", ui.TextBold, ui.ColorError, title, ui.ColorReset)
+	for _, score := range scores {
+		fmt.Fprintf(w, "  %s%4d%s  %-*s  %s%s%s\n",
+			ui.ColorError, score.Score, ui.ColorReset,
+			width, score.Address,
+			ui.ColorFaint, strings.Join(score.Reasons, ", "), ui.ColorReset)
+	}
+}
+
+// RiskAnnotations converts a risk assessment to plan viewer badges. High risk
+// changes are marked as errors and also list the factors of their score.
+func RiskAnnotations(assessment *risk.Assessment) map[string][]plan.Annotation {
+	annotations := make(map[string][]plan.Annotation)
+	if assessment == nil {
+		return annotations
+	}
+	for _, score := range assessment.Scores {
+		annotation := plan.Annotation{Level: "info", Badge: fmt.Sprintf("risk %d", score.Score)}
+		if assessment.IsHigh(score) {
+			annotation.Level = "error"
+			annotation.Badge = "⚠ " + annotation.Badge
+			annotation.Text = fmt.Sprintf("⚠ high risk (%d): %s", score.Score, strings.Join(score.Reasons, ", "))
+		}
+		annotations[score.Address] = append(annotations[score.Address], annotation)
+	}
+	return annotations
+}
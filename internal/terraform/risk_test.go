@@ -0,0 +1,54 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"tfapp/internal/risk"
+)
+
+func testAssessment() *risk.Assessment {
+	return &risk.Assessment{
+		Scores: []risk.Score{
+			{Address: "aws_db_instance.main", Action: "replace", Score: 110, Reasons: []string{"replaced", "stateful type aws_db_instance"}},
+			{Address: "aws_s3_bucket.logs", Action: "update", Score: 5, Reasons: []string{"updated in-place"}},
+		},
+		HighRisk:     50,
+		ConfirmAbove: 80,
+	}
+}
+
+func TestWriteHighRisk(t *testing.T) {
+	var out strings.Builder
+	WriteHighRisk(&out, testAssessment())
+	got := out.String()
+
+	if !strings.Contains(got, "High risk changes:") || !strings.Contains(got, "aws_db_instance.main") {
+		t.Errorf("output does not list the database:\n%s", got)
+	}
+	if !strings.Contains(got, "replaced, stateful type aws_db_instance") {
+		t.Errorf("output does not list the risk factors:\n%s", got)
+	}
+	if strings.Contains(got, "aws_s3_bucket.logs") {
+		t.Errorf("output lists a low risk change:\n%s", got)
+	}
+
+	out.Reset()
+	WriteHighRisk(&out, nil)
+	if out.Len() != 0 {
+		t.Errorf("expected no output without an assessment, got %q", out.String())
+	}
+}
+
+func TestRiskAnnotations(t *testing.T) {
+	annotations := RiskAnnotations(testAssessment())
+
+	db := annotations["aws_db_instance.main"]
+	if len(db) != 1 || db[0].Level != "error" || db[0].Badge != "⚠ risk 110" || db[0].Text == "" {
+		t.Errorf("unexpected database annotation: %+v", db)
+	}
+	bucket := annotations["aws_s3_bucket.logs"]
+	if len(bucket) != 1 || bucket[0].Level != "info" || bucket[0].Badge != "risk 5" || bucket[0].Text != "" {
+		t.Errorf("unexpected bucket annotation: %+v", bucket)
+	}
+}
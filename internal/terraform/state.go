@@ -0,0 +1,43 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"tfapp/internal/models"
+)
+
+// StateVersion identifies a version of the Terraform state.
+// Every state write increments the serial; the lineage changes only when
+// the state is recreated from scratch.
+type StateVersion struct {
+	Lineage string `json:"lineage"`
+	Serial  int64  `json:"serial"`
+}
+
+// CurrentStateVersion returns the version of the current state, using `terraform state pull`.
+// It returns nil when there is no state yet.
+func CurrentStateVersion(ctx interface{}, executor models.Executor) (*StateVersion, error) {
+	output, err := executor.Output(ctx, []string{"state", "pull"})
+	if err != nil {
+		return nil, fmt.Errorf("error reading terraform state: %w", err)
+	}
+	if len(bytes.TrimSpace(output)) == 0 {
+		return nil, nil
+	}
+
+	var version StateVersion
+	if err := json.Unmarshal(output, &version); err != nil {
+		return nil, fmt.Errorf("error parsing terraform state: %w", err)
+	}
+	return &version, nil
+}
+
+// String returns a short description of the state version.
+func (v *StateVersion) String() string {
+	if v == nil || v.Lineage == "" {
+		return "no state"
+	}
+	return fmt.Sprintf("serial %d of lineage %s", v.Serial, v.Lineage)
+}
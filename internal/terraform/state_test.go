@@ -0,0 +1,34 @@
+package terraform
+
+import (
+	"context"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestCurrentStateVersion(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("state pull", testutil.Response{Stdout: `{"version": 4, "serial": 7, "lineage": "5f2c"}`})
+
+	executor := NewCommandExecutor()
+	version, err := CurrentStateVersion(context.Background(), executor)
+	if err != nil {
+		t.Fatalf("CurrentStateVersion returned error: %v", err)
+	}
+	if version == nil || *version != (StateVersion{Lineage: "5f2c", Serial: 7}) {
+		t.Errorf("CurrentStateVersion() = %v, want serial 7 of lineage 5f2c", version)
+	}
+}
+
+func TestCurrentStateVersionWithoutState(t *testing.T) {
+	testutil.InstallFakeTerraform(t)
+
+	version, err := CurrentStateVersion(context.Background(), NewCommandExecutor())
+	if err != nil || version != nil {
+		t.Errorf("CurrentStateVersion() = %v, %v, want no state", version, err)
+	}
+	if got := version.String(); got != "no state" {
+		t.Errorf("String() = %q, want %q", got, "no state")
+	}
+}
@@ -0,0 +1,223 @@
+package terraform
+
+import (
+	"strings"
+
+	"tfapp/internal/planjson"
+)
+
+// Kinds of nodes in a target tree.
+const (
+	TargetKindModule   = "module"
+	TargetKindResource = "resource"
+	TargetKindInstance = "instance"
+)
+
+// TargetNode is a node of the hierarchy offered for -target selection:
+// module → module instance → resource → resource instance.
+// Leaves are the changed resource instances (or unindexed resources) of the plan.
+type TargetNode struct {
+	Address    string
+	Kind       string
+	Action     string // Summarized change action, set on leaves only
+	Targetable bool   // Whether the leaf can be selected, set on leaves only
+	Children   []*TargetNode
+}
+
+// IsLeaf reports whether the node is a single changed resource instance.
+func (n *TargetNode) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// TargetableLeaves returns the number of selectable leaves below (or at) the node.
+func (n *TargetNode) TargetableLeaves() int {
+	if n.IsLeaf() {
+		if n.Targetable {
+			return 1
+		}
+		return 0
+	}
+
+	count := 0
+	for _, child := range n.Children {
+		count += child.TargetableLeaves()
+	}
+	return count
+}
+
+// TargetOption is a node of a target tree along with its depth, in display order.
+type TargetOption struct {
+	Node  *TargetNode
+	Depth int
+}
+
+// BuildTargetTree builds the target hierarchy for the resource changes of a plan.
+// No-op changes and data source reads are left out. Moved resources are kept
+// as non-targetable leaves, so that their module is never targeted as a whole
+// on the user's behalf.
+func BuildTargetTree(plan *planjson.Plan) []*TargetNode {
+	var roots []*TargetNode
+	nodes := make(map[string]*TargetNode)
+
+	// child returns the node for address below parent, creating it if needed
+	child := func(parent *TargetNode, address, kind string) *TargetNode {
+		if node, ok := nodes[address]; ok {
+			return node
+		}
+		node := &TargetNode{Address: address, Kind: kind}
+		nodes[address] = node
+		if parent == nil {
+			roots = append(roots, node)
+		} else {
+			parent.Children = append(parent.Children, node)
+		}
+		return node
+	}
+
+	for _, change := range plan.ResourceChanges {
+		if change.Change.Actions.IsNoOp() || change.Mode == "data" {
+			continue
+		}
+
+		var parent *TargetNode
+		for _, step := range splitModuleAddress(change.ModuleAddress) {
+			parent = child(parent, step.call, TargetKindModule)
+			if step.instance != step.call {
+				parent = child(parent, step.instance, TargetKindModule)
+			}
+		}
+
+		resourceAddress := change.Type + "." + change.Name
+		if change.ModuleAddress != "" {
+			resourceAddress = change.ModuleAddress + "." + resourceAddress
+		}
+
+		leaf := child(parent, resourceAddress, TargetKindResource)
+		if change.Index != nil {
+			leaf = child(leaf, change.Address, TargetKindInstance)
+		}
+
+		leaf.Action = change.Change.Actions.Summary()
+		leaf.Targetable = !change.Moved() && isTargetableAction(leaf.Action)
+	}
+
+	return roots
+}
+
+// isTargetableAction reports whether a change with the given action can be applied on its own.
+func isTargetableAction(action string) bool {
+	switch action {
+	case "create", "update", "destroy", "replace":
+		return true
+	}
+	return false
+}
+
+// FlattenTargetTree lists the nodes that have at least one targetable leaf, depth first.
+func FlattenTargetTree(roots []*TargetNode) []TargetOption {
+	var options []TargetOption
+
+	var walk func(nodes []*TargetNode, depth int)
+	walk = func(nodes []*TargetNode, depth int) {
+		for _, node := range nodes {
+			if node.TargetableLeaves() == 0 {
+				continue
+			}
+			options = append(options, TargetOption{Node: node, Depth: depth})
+			walk(node.Children, depth+1)
+		}
+	}
+	walk(roots, 0)
+
+	return options
+}
+
+// CoarseTargets returns the smallest list of target addresses covering the selected leaves.
+// A module or resource is targeted as a whole only when every change below it is selected;
+// otherwise its selected descendants are targeted individually.
+func CoarseTargets(roots []*TargetNode, selected map[string]bool) []string {
+	var targets []string
+
+	var fullySelected func(node *TargetNode) bool
+	fullySelected = func(node *TargetNode) bool {
+		if node.IsLeaf() {
+			return node.Targetable && selected[node.Address]
+		}
+		for _, child := range node.Children {
+			if !fullySelected(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var walk func(nodes []*TargetNode)
+	walk = func(nodes []*TargetNode) {
+		for _, node := range nodes {
+			if fullySelected(node) {
+				targets = append(targets, node.Address)
+				continue
+			}
+			walk(node.Children)
+		}
+	}
+	walk(roots)
+
+	return targets
+}
+
+// moduleStep is one module call in a module address, e.g. module.app[0].
+type moduleStep struct {
+	call     string // Address of the call, covering all its instances
+	instance string // Address of the instance; equal to call for modules without count or for_each
+}
+
+// splitModuleAddress splits a module address such as `module.app["blue"].module.db`
+// into its steps. Instance keys may be quoted strings containing dots or brackets.
+func splitModuleAddress(address string) []moduleStep {
+	var steps []moduleStep
+
+	i := 0
+	for i < len(address) {
+		if !strings.HasPrefix(address[i:], "module.") {
+			break
+		}
+		i += len("module.")
+		for i < len(address) && address[i] != '.' && address[i] != '[' {
+			i++
+		}
+		callEnd := i
+
+		if i < len(address) && address[i] == '[' {
+			inString := false
+			for i++; i < len(address); i++ {
+				c := address[i]
+				if inString {
+					if c == '\\' {
+						i++
+					} else if c == '"' {
+						inString = false
+					}
+					continue
+				}
+				if c == '"' {
+					inString = true
+				} else if c == ']' {
+					break
+				}
+			}
+			i++
+		}
+		if i > len(address) {
+			i = len(address)
+		}
+
+		steps = append(steps, moduleStep{call: address[:callEnd], instance: address[:i]})
+
+		if i < len(address) && address[i] == '.' {
+			i++
+		}
+	}
+
+	return steps
+}
@@ -0,0 +1,110 @@
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+func loadFixture(t *testing.T, name string) *planjson.Plan {
+	t.Helper()
+
+	plan, err := planjson.Parse(testutil.PlanFixture(t, name))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+	return plan
+}
+
+func TestFlattenTargetTree(t *testing.T) {
+	options := FlattenTargetTree(BuildTargetTree(loadFixture(t, "modules")))
+
+	var got []string
+	for _, option := range options {
+		got = append(got, fmt.Sprintf("%s%s (%s)", strings.Repeat("  ", option.Depth), option.Node.Address, option.Node.Kind))
+	}
+	want := []string{
+		"aws_iam_role.deployer (resource)",
+		"module.app (module)",
+		"  module.app[0] (module)",
+		"    module.app[0].aws_instance.web (resource)",
+		`      module.app[0].aws_instance.web["blue"] (instance)`,
+		`      module.app[0].aws_instance.web["green"] (instance)`,
+		"module.network (module)",
+		"  module.network.aws_subnet.private (resource)",
+		"    module.network.aws_subnet.private[0] (instance)",
+		"    module.network.aws_subnet.private[1] (instance)",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tree =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCoarseTargets(t *testing.T) {
+	tree := BuildTargetTree(loadFixture(t, "modules"))
+
+	tests := []struct {
+		name     string
+		selected []string
+		want     []string
+	}{
+		{
+			name:     "whole module",
+			selected: []string{`module.app[0].aws_instance.web["blue"]`, `module.app[0].aws_instance.web["green"]`},
+			want:     []string{"module.app"},
+		},
+		{
+			name:     "single instance",
+			selected: []string{`module.app[0].aws_instance.web["green"]`},
+			want:     []string{`module.app[0].aws_instance.web["green"]`},
+		},
+		{
+			name:     "unindexed resource and module",
+			selected: []string{"aws_iam_role.deployer", "module.network.aws_subnet.private[0]", "module.network.aws_subnet.private[1]"},
+			want:     []string{"aws_iam_role.deployer", "module.network"},
+		},
+		{
+			name:     "nothing",
+			selected: nil,
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := make(map[string]bool)
+			for _, address := range tt.selected {
+				selected[address] = true
+			}
+			if got := CoarseTargets(tree, selected); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CoarseTargets() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCoarseTargetsKeepsMovedResourcesOut(t *testing.T) {
+	tree := BuildTargetTree(loadFixture(t, "moves"))
+
+	if options := FlattenTargetTree(tree); len(options) != 0 {
+		t.Errorf("moved resources offered for targeting: %+v", options)
+	}
+	if got := CoarseTargets(tree, map[string]bool{"module.storage.aws_s3_bucket.backups": true}); got != nil {
+		t.Errorf("CoarseTargets() = %q, want no targets", got)
+	}
+}
+
+func TestSplitModuleAddress(t *testing.T) {
+	got := splitModuleAddress(`module.app["a.b]"].module.db[2]`)
+	want := []moduleStep{
+		{call: "module.app", instance: `module.app["a.b]"]`},
+		{call: `module.app["a.b]"].module.db`, instance: `module.app["a.b]"].module.db[2]`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitModuleAddress() = %+v, want %+v", got, want)
+	}
+}
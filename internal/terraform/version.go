@@ -0,0 +1,92 @@
+package terraform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"tfapp/internal/models"
+)
+
+// VersionInfo is the output of `terraform version -json`, which OpenTofu shares.
+type VersionInfo struct {
+	Version            string            `json:"terraform_version"`
+	Platform           string            `json:"platform"`
+	ProviderSelections map[string]string `json:"provider_selections"`
+	Outdated           bool              `json:"terraform_outdated"`
+	Product            Product           `json:"-"` // Named by the plain version output
+}
+
+// String returns the product and its version, e.g. "OpenTofu 1.9.0".
+func (v *VersionInfo) String() string {
+	return fmt.Sprintf("%s %s", v.Product, v.Version)
+}
+
+// AtLeast reports whether the version is the given one or later. Pre-release
+// suffixes are ignored, so 1.9.0-beta1 counts as 1.9.0.
+func (v *VersionInfo) AtLeast(version string) bool {
+	have, want := versionNumbers(v.Version), versionNumbers(version)
+	for i := range want {
+		if have[i] != want[i] {
+			return have[i] > want[i]
+		}
+	}
+	return true
+}
+
+// versionNumbers returns the major, minor and patch numbers of a version such
+// as "1.9.5" or "v1.10.0-rc1". Missing or invalid numbers are zero.
+func versionNumbers(version string) [3]int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+
+	var numbers [3]int
+	for i, part := range strings.SplitN(version, ".", 3) {
+		numbers[i], _ = strconv.Atoi(part)
+	}
+	return numbers
+}
+
+// DetectVersion returns the version of the binary the executor runs. A
+// CommandExecutor detects it once and remembers it.
+func DetectVersion(ctx interface{}, executor models.Executor) (*VersionInfo, error) {
+	if e, ok := executor.(*CommandExecutor); ok {
+		return e.Version(ctx)
+	}
+	return detectVersion(ctx, executor)
+}
+
+// detectVersion runs `version -json` with the executor for the version, then
+// `version` for the product, which only the plain output names.
+func detectVersion(ctx interface{}, executor models.Executor) (*VersionInfo, error) {
+	output, err := executor.Output(ctx, []string{"version", "-json"})
+	if err != nil {
+		return nil, fmt.Errorf("error getting version: %w", err)
+	}
+
+	var info VersionInfo
+	if err := json.Unmarshal(output, &info); err != nil {
+		return nil, fmt.Errorf("error parsing version: %w", err)
+	}
+
+	output, err = executor.Output(ctx, []string{"version"})
+	if err != nil {
+		return nil, fmt.Errorf("error getting version: %w", err)
+	}
+	info.Product = productOf(output)
+	return &info, nil
+}
+
+// productOf returns the product named at the start of the plain version
+// output, e.g. "OpenTofu v1.9.0", whatever the name of the binary: tofu can
+// be installed as terraform by version managers.
+func productOf(output []byte) Product {
+	if bytes.HasPrefix(bytes.TrimSpace(output), []byte("OpenTofu")) {
+		return ProductOpenTofu
+	}
+	return ProductTerraform
+}
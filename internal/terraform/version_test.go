@@ -0,0 +1,71 @@
+package terraform
+
+import (
+	"context"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestDetectVersion(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("version -json", testutil.Response{Stdout: `{
+  "terraform_version": "1.9.5",
+  "platform": "linux_amd64",
+  "provider_selections": {"registry.terraform.io/hashicorp/aws": "5.70.0"},
+  "terraform_outdated": true
+}`})
+
+	info, err := DetectVersion(context.Background(), NewCommandExecutor())
+	if err != nil {
+		t.Fatalf("DetectVersion returned error: %v", err)
+	}
+	if info.Version != "1.9.5" || info.Platform != "linux_amd64" || !info.Outdated {
+		t.Errorf("DetectVersion() = %+v", info)
+	}
+	if got := info.ProviderSelections["registry.terraform.io/hashicorp/aws"]; got != "5.70.0" {
+		t.Errorf("aws provider version = %q, want 5.70.0", got)
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	for _, tc := range []struct {
+		version, since string
+		want           bool
+	}{
+		{"1.9.0", "1.9.0", true},
+		{"1.10.0", "1.9.0", true},
+		{"v1.9.1", "1.9.0", true},
+		{"1.9.0-rc1", "1.9.0", true},
+		{"1.8.9", "1.9.0", false},
+		{"0.15.5", "1.5.0", false},
+	} {
+		info := &VersionInfo{Version: tc.version}
+		if got := info.AtLeast(tc.since); got != tc.want {
+			t.Errorf("%s at least %s = %v, want %v", tc.version, tc.since, got, tc.want)
+		}
+	}
+}
+
+func TestDetectVersionProduct(t *testing.T) {
+	// tofu installed as terraform, as version managers do
+	fake := testutil.InstallFakeTerraform(t)
+	fake.OnVersion("OpenTofu", "1.9.0")
+
+	info, err := DetectVersion(context.Background(), NewCommandExecutor())
+	if err != nil {
+		t.Fatalf("DetectVersion returned error: %v", err)
+	}
+	if info.Product != ProductOpenTofu || !info.Supports(FeatureExclude) {
+		t.Errorf("DetectVersion() = %s, want OpenTofu with -exclude", info)
+	}
+
+	// A Terraform binary named tofu
+	fake = testutil.InstallFakeTofu(t)
+	fake.OnVersion("Terraform", "1.9.5")
+	executor := NewCommandExecutor()
+	executor.SetBinary("tofu")
+	if info, err := DetectVersion(context.Background(), executor); err != nil || info.Product != ProductTerraform {
+		t.Errorf("DetectVersion() = %v, %v, want Terraform", info, err)
+	}
+}
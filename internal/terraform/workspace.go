@@ -0,0 +1,119 @@
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"tfapp/internal/models"
+	"tfapp/internal/ui"
+)
+
+// DefaultWorkspace is the workspace every working directory starts with.
+const DefaultWorkspace = "default"
+
+// WorkspaceEnv is the environment variable selecting the workspace terraform
+// runs in, overriding the workspace selected in the working directory.
+const WorkspaceEnv = "TF_WORKSPACE"
+
+// CurrentWorkspace returns the name of the selected workspace, using `terraform workspace show`.
+func CurrentWorkspace(ctx interface{}, executor models.Executor) (string, error) {
+	output, err := executor.Output(ctx, []string{"workspace", "show"})
+	if err != nil {
+		return "", fmt.Errorf("error getting terraform workspace: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Workspaces lists the workspaces of the working directory and returns the
+// selected one, using `terraform workspace list`.
+func Workspaces(ctx interface{}, executor models.Executor) (workspaces []string, current string, err error) {
+	output, err := executor.Output(ctx, []string{"workspace", "list"})
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing terraform workspaces: %w", err)
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		name, selected := strings.CutPrefix(strings.TrimSpace(line), "* ")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if selected {
+			current = name
+		}
+		workspaces = append(workspaces, name)
+	}
+	return workspaces, current, nil
+}
+
+// SelectWorkspace selects an existing workspace with `terraform workspace select`.
+func SelectWorkspace(ctx interface{}, executor models.Executor, name string) error {
+	if _, err := executor.Output(ctx, []string{"workspace", "select", name}); err != nil {
+		return fmt.Errorf("error selecting terraform workspace %q: %w", name, err)
+	}
+	return nil
+}
+
+// NewWorkspace creates a workspace and selects it with `terraform workspace new`.
+func NewWorkspace(ctx interface{}, executor models.Executor, name string) error {
+	if _, err := executor.Output(ctx, []string{"workspace", "new", name}); err != nil {
+		return fmt.Errorf("error creating terraform workspace %q: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteWorkspace deletes a workspace with `terraform workspace delete`.
+// Terraform refuses to delete the selected workspace, and one whose state
+// still tracks resources.
+func DeleteWorkspace(ctx interface{}, executor models.Executor, name string) error {
+	if _, err := executor.Output(ctx, []string{"workspace", "delete", name}); err != nil {
+		return fmt.Errorf("error deleting terraform workspace %q: %w", name, err)
+	}
+	return nil
+}
+
+// WriteWorkspace writes the workspace a plan applies to, as the header of its summary.
+func WriteWorkspace(w io.Writer, workspace string) {
+	fmt.Fprintf(w, "\n%sWorkspace:%s %s%s%s\n", ui.TextBold, ui.ColorReset, ui.ColorHighlight, workspace, ui.ColorReset)
+}
+
+// WorkspaceOf returns the workspace the commands of an executor run in.
+func WorkspaceOf(executor models.Executor) string {
+	if e, ok := executor.(*CommandExecutor); ok {
+		return e.Workspace()
+	}
+	return SelectedWorkspace()
+}
+
+// SelectedWorkspace returns the workspace selected in the working directory
+// without running terraform: TF_WORKSPACE if set, otherwise the workspace
+// recorded in the data directory by `terraform workspace select`.
+func SelectedWorkspace() string {
+	return SelectedWorkspaceIn(".")
+}
+
+// SelectedWorkspaceIn returns the workspace selected in the root module in dir, like SelectedWorkspace.
+func SelectedWorkspaceIn(dir string) string {
+	if workspace := os.Getenv(WorkspaceEnv); workspace != "" {
+		return workspace
+	}
+
+	dataDir := os.Getenv("TF_DATA_DIR")
+	if dataDir == "" {
+		dataDir = ".terraform"
+	}
+	if !filepath.IsAbs(dataDir) {
+		dataDir = filepath.Join(dir, dataDir)
+	}
+	data, err := os.ReadFile(filepath.Join(dataDir, "environment"))
+	if err != nil {
+		return DefaultWorkspace
+	}
+	if workspace := strings.TrimSpace(string(data)); workspace != "" {
+		return workspace
+	}
+	return DefaultWorkspace
+}
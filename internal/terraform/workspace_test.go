@@ -0,0 +1,65 @@
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestCurrentWorkspace(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("workspace show", testutil.Response{Stdout: "staging\n"})
+
+	workspace, err := CurrentWorkspace(context.Background(), NewCommandExecutor())
+	if err != nil {
+		t.Fatalf("CurrentWorkspace returned error: %v", err)
+	}
+	if workspace != "staging" {
+		t.Errorf("CurrentWorkspace() = %q, want %q", workspace, "staging")
+	}
+}
+
+func TestSelectedWorkspace(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Setenv("TF_WORKSPACE", "")
+	t.Setenv("TF_DATA_DIR", "")
+
+	if got := SelectedWorkspace(); got != DefaultWorkspace {
+		t.Errorf("SelectedWorkspace() without a data directory = %q, want %q", got, DefaultWorkspace)
+	}
+
+	if err := os.MkdirAll(".terraform", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(".terraform", "environment"), []byte("staging"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := SelectedWorkspace(); got != "staging" {
+		t.Errorf("SelectedWorkspace() = %q, want staging", got)
+	}
+
+	t.Setenv("TF_WORKSPACE", "production")
+	if got := SelectedWorkspace(); got != "production" {
+		t.Errorf("SelectedWorkspace() with TF_WORKSPACE = %q, want production", got)
+	}
+}
+
+func TestWorkspaces(t *testing.T) {
+	fake := testutil.InstallFakeTerraform(t)
+	fake.On("workspace list", testutil.Response{Stdout: "  default\n* staging\n  production\n\n"})
+
+	workspaces, current, err := Workspaces(context.Background(), NewCommandExecutor())
+	if err != nil {
+		t.Fatalf("Workspaces returned error: %v", err)
+	}
+	if want := []string{"default", "staging", "production"}; !reflect.DeepEqual(workspaces, want) {
+		t.Errorf("Workspaces() = %q, want %q", workspaces, want)
+	}
+	if current != "staging" {
+		t.Errorf("current workspace = %q, want staging", current)
+	}
+}
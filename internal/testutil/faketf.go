@@ -0,0 +1,214 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Response is the canned behaviour of the fake terraform binary for one command.
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// FakeTerraform is a scripted stand-in terraform executable installed on PATH.
+// Each invocation is looked up by its first two arguments (e.g. "workspace show")
+// and then by its first argument (e.g. "plan"), first among the responses for
+// the directory it runs in; commands without a response succeed silently.
+// `plan -out FILE` always creates FILE so later commands can inspect it.
+type FakeTerraform struct {
+	t   testing.TB
+	dir string
+}
+
+// fakeTerraformScript dispatches on the command line and logs every call.
+const fakeTerraformScript = `#!/bin/sh
+dir="$(dirname "$0")"
+# One write per call, so that concurrent calls do not interleave
+line="$(pwd -P)	$TF_WORKSPACE"
+for arg in "$@"; do line="$line	$arg"; done
+printf '%s\n' "$line" >> "$dir/calls.log"
+
+if [ "$1" = "plan" ]; then
+	prev=""
+	for arg in "$@"; do
+		[ "$prev" = "-out" ] && : > "$arg"
+		case "$arg" in -out=*) : > "${arg#-out=}" ;; esac
+		prev="$arg"
+	done
+fi
+
+responses="$dir/responses"
+wd="$dir/responses/$(pwd -P | tr / _)"
+[ -f "$wd/$1.code" ] || [ -f "$wd/$1_$2.code" ] && responses="$wd"
+key="$1"
+[ -n "$2" ] && [ -f "$responses/$1_$2.code" ] && key="$1_$2"
+[ -f "$responses/$key.stdout" ] && cat "$responses/$key.stdout"
+[ -f "$responses/$key.stderr" ] && cat "$responses/$key.stderr" >&2
+code=0
+[ -f "$responses/$key.code" ] && code=$(cat "$responses/$key.code")
+exit "$code"
+`
+
+// InstallFakeTerraform writes a fake terraform binary to a temporary directory
+// and puts it first on PATH for the duration of the test.
+func InstallFakeTerraform(t testing.TB) *FakeTerraform {
+	t.Helper()
+	return installFake(t, "terraform")
+}
+
+// InstallFakeTofu is InstallFakeTerraform for a binary named tofu, standing in for OpenTofu.
+func InstallFakeTofu(t testing.TB) *FakeTerraform {
+	t.Helper()
+	return installFake(t, "tofu")
+}
+
+// installFake writes the fake binary under the given name and puts it first on PATH.
+func installFake(t testing.TB, name string) *FakeTerraform {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake terraform binary is a POSIX shell script")
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "responses"), 0755); err != nil {
+		t.Fatalf("creating fake terraform directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(fakeTerraformScript), 0755); err != nil {
+		t.Fatalf("writing fake terraform: %v", err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return &FakeTerraform{t: t, dir: dir}
+}
+
+// On sets the response for a command such as "plan", "show" or "workspace show".
+func (f *FakeTerraform) On(command string, response Response) {
+	f.t.Helper()
+	f.write(filepath.Join(f.dir, "responses"), command, response)
+}
+
+// OnIn sets the response for a command run in the given directory, taking
+// precedence over the response set by On.
+func (f *FakeTerraform) OnIn(dir, command string, response Response) {
+	f.t.Helper()
+
+	resolved, err := filepath.Abs(dir)
+	if err == nil {
+		resolved, err = filepath.EvalSymlinks(resolved)
+	}
+	if err != nil {
+		f.t.Fatalf("resolving fake terraform directory: %v", err)
+	}
+	responses := filepath.Join(f.dir, "responses", strings.ReplaceAll(resolved, "/", "_"))
+	if err := os.MkdirAll(responses, 0755); err != nil {
+		f.t.Fatalf("creating fake terraform directory: %v", err)
+	}
+	f.write(responses, command, response)
+}
+
+// write writes the files of a response to the given responses directory.
+func (f *FakeTerraform) write(responses, command string, response Response) {
+	f.t.Helper()
+
+	key := strings.ReplaceAll(command, " ", "_")
+	files := map[string]string{
+		key + ".stdout": response.Stdout,
+		key + ".stderr": response.Stderr,
+		key + ".code":   fmt.Sprint(response.ExitCode),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(responses, name), []byte(content), 0644); err != nil {
+			f.t.Fatalf("writing fake terraform response: %v", err)
+		}
+	}
+}
+
+// OnVersion makes `version -json` and `version` report the given product,
+// "Terraform" or "OpenTofu", and version, whatever the name of the fake.
+func (f *FakeTerraform) OnVersion(product, version string) {
+	f.t.Helper()
+	f.On("version -json", Response{Stdout: `{"terraform_version": "` + version + `", "platform": "linux_amd64"}`})
+	f.On("version", Response{Stdout: product + " v" + version + "\non linux_amd64\n"})
+}
+
+// OnShowPlan makes `terraform show -json` print the named plan fixture.
+func (f *FakeTerraform) OnShowPlan(fixture string) {
+	f.t.Helper()
+	f.On("show", Response{Stdout: string(PlanFixture(f.t, fixture))})
+}
+
+// Calls returns the arguments of every invocation so far, in order.
+func (f *FakeTerraform) Calls() [][]string {
+	f.t.Helper()
+
+	var calls [][]string
+	for _, line := range f.lines() {
+		calls = append(calls, line[2:])
+	}
+	return calls
+}
+
+// Dirs returns the directory of every invocation so far, in order.
+func (f *FakeTerraform) Dirs() []string {
+	f.t.Helper()
+
+	var dirs []string
+	for _, line := range f.lines() {
+		dirs = append(dirs, line[0])
+	}
+	return dirs
+}
+
+// Workspaces returns the TF_WORKSPACE of every invocation so far, in order,
+// with "" for the invocations run without it.
+func (f *FakeTerraform) Workspaces() []string {
+	f.t.Helper()
+
+	var workspaces []string
+	for _, line := range f.lines() {
+		workspaces = append(workspaces, line[1])
+	}
+	return workspaces
+}
+
+// lines returns the logged invocations, each the directory and TF_WORKSPACE
+// followed by the arguments.
+func (f *FakeTerraform) lines() [][]string {
+	f.t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(f.dir, "calls.log"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		f.t.Fatalf("reading fake terraform calls: %v", err)
+	}
+
+	var lines [][]string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		lines = append(lines, strings.Split(line, "\t"))
+	}
+	return lines
+}
+
+// CalledWith reports whether terraform was invoked with arguments starting
+// with the given ones, e.g. CalledWith("apply") or CalledWith("state", "rm").
+func (f *FakeTerraform) CalledWith(args ...string) bool {
+	f.t.Helper()
+
+	for _, call := range f.Calls() {
+		if len(call) >= len(args) && slices.Equal(call[:len(args)], args) {
+			return true
+		}
+	}
+	return false
+}
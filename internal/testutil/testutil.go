@@ -0,0 +1,134 @@
+// Package testutil provides shared helpers for tfapp tests: plan JSON fixtures,
+// golden files, stdio redirection and a scripted stand-in terraform binary.
+package testutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that, set to 1, makes Golden rewrite
+// golden files with the current output instead of comparing. Run
+// `UPDATE_GOLDEN=1 go test ./...` after an intentional output change; unlike
+// a test flag, it is accepted by the packages that have no golden files.
+const UpdateEnv = "UPDATE_GOLDEN"
+
+// fixturesDir returns the absolute path of the shared fixture directory.
+func fixturesDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "testdata")
+}
+
+// PlanFixtures returns the names of all plan JSON fixtures, without extension.
+func PlanFixtures(t testing.TB) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(fixturesDir(), "plans", "*.json"))
+	if err != nil {
+		t.Fatalf("listing plan fixtures: %v", err)
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, match := range matches {
+		names = append(names, filepath.Base(match[:len(match)-len(".json")]))
+	}
+	return names
+}
+
+// PlanFixture returns the `terraform show -json` output stored under the given name.
+func PlanFixture(t testing.TB, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(fixturesDir(), "plans", name+".json"))
+	if err != nil {
+		t.Fatalf("reading plan fixture %q: %v", name, err)
+	}
+	return data
+}
+
+// UIFixture returns the machine-readable UI output (`terraform apply -json`
+// and the like) stored under the given name.
+func UIFixture(t testing.TB, name string) []byte {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(fixturesDir(), "ui", name+".jsonl"))
+	if err != nil {
+		t.Fatalf("reading UI fixture %q: %v", name, err)
+	}
+	return data
+}
+
+// Golden compares got with the content of testdata/<name>.golden in the
+// calling package's directory. With UPDATE_GOLDEN=1, the golden file is rewritten.
+func Golden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv(UpdateEnv) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("creating golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("writing golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with UPDATE_GOLDEN=1 to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output does not match %s (run with UPDATE_GOLDEN=1 to accept)\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+// CaptureStdout runs fn with os.Stdout redirected and returns what was written.
+func CaptureStdout(t testing.TB, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+
+	fn()
+
+	w.Close()
+	return string(<-done)
+}
+
+// Stdin replaces os.Stdin with the given input for the rest of the test.
+func Stdin(t testing.TB, input string) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("creating pipe: %v", err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatalf("writing stdin: %v", err)
+	}
+	w.Close()
+
+	original := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = original
+		r.Close()
+	})
+}
@@ -12,10 +12,16 @@ import (
 )
 
 // Option represents a single checkbox menu option.
+// Options with a Depth greater than the option before them are its children:
+// toggling an option toggles all of its descendants.
+// Requires lists the names of other options that are implicitly included
+// whenever this option is checked; they are marked in the menu.
 type Option struct {
 	Name        string
 	Description string
 	Checked     bool
+	Depth       int
+	Requires    []string
 }
 
 // String implements the fmt.Stringer interface.
@@ -25,6 +31,7 @@ func (o Option) String() string {
 
 // model represents the checkbox menu state.
 type model struct {
+	title        string
 	options      []Option
 	cursor       int
 	quitting     bool
@@ -110,7 +117,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if clickedIndex >= 0 && clickedIndex < len(m.options) {
 					// If clicking the same option, toggle it
 					if m.cursor == clickedIndex {
-						m.options[m.cursor].Checked = !m.options[m.cursor].Checked
+						m.toggle(m.cursor)
 					} else {
 						// Move cursor to the clicked option
 						m.cursor = clickedIndex
@@ -158,7 +165,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case " ":
 			// Toggle the selected item
-			m.options[m.cursor].Checked = !m.options[m.cursor].Checked
+			m.toggle(m.cursor)
 
 		case "a":
 			// Select all
@@ -172,6 +179,14 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.options[i].Checked = false
 			}
 
+		case "d":
+			// Select the item along with everything it requires
+			m.selectWithRequired(m.cursor)
+
+		case "D":
+			// Select the item along with everything that requires it
+			m.selectWithDependents(m.cursor)
+
 		case "home", "g":
 			// Jump to the top of the list
 			m.cursor = 0
@@ -205,6 +220,7 @@ var (
 	cursorStyle      = lipgloss.NewStyle()
 	checkedStyle     = lipgloss.NewStyle()
 	uncheckedStyle   = lipgloss.NewStyle()
+	impliedStyle     = lipgloss.NewStyle()
 	keyBindingStyle  = lipgloss.NewStyle()
 	helpTextStyle    = lipgloss.NewStyle()
 	instructionStyle = lipgloss.NewStyle()
@@ -228,7 +244,7 @@ func (m model) View() string {
 
 	var sb strings.Builder
 
-	sb.WriteString("Select resources to apply\n\n")
+	sb.WriteString(m.title + "\n\n")
 
 	// Calculate visible range
 	start := m.windowTop
@@ -241,6 +257,8 @@ func (m model) View() string {
 		end = len(m.options)
 	}
 
+	implied := m.implied()
+
 	// Render visible options
 	for i := start; i < end; i++ {
 		option := m.options[i]
@@ -260,25 +278,23 @@ func (m model) View() string {
 		// Style based on selection state
 		optNameStyle := nameStyle
 		if i == m.cursor {
-			// Highlight the cursor position
-			if option.Checked {
-				checkedSymbol = checkedStyle.Render("[x] ")
-			} else {
-				checkedSymbol = uncheckedStyle.Render("[ ] ")
-			}
 			cursor = cursorStyle.Render(cursor)
 			optNameStyle = activeStyle
+		}
+		if option.Checked {
+			checkedSymbol = checkedStyle.Render("[x] ")
+		} else if m.partiallyChecked(i) {
+			checkedSymbol = checkedStyle.Render("[-] ")
+		} else if implied[option.Name] {
+			checkedSymbol = impliedStyle.Render("[+] ")
 		} else {
-			if option.Checked {
-				checkedSymbol = checkedStyle.Render("[x] ")
-			} else {
-				checkedSymbol = uncheckedStyle.Render("[ ] ")
-			}
+			checkedSymbol = uncheckedStyle.Render("[ ] ")
 		}
 
-		// Render name with checkbox
-		line := fmt.Sprintf("%s%s%s",
+		// Render name with checkbox, indented by depth
+		line := fmt.Sprintf("%s%s%s%s",
 			cursor,
+			strings.Repeat("  ", option.Depth),
 			checkedSymbol,
 			optNameStyle.Render(option.Name))
 
@@ -299,6 +315,9 @@ func (m model) View() string {
 
 			line += fmt.Sprintf(" - %s", descStyle.Render(option.Description))
 		}
+		if implied[option.Name] {
+			line += impliedStyle.Render(" (included as a dependency)")
+		}
 
 		// Highlight the current line with background
 		if i == m.cursor {
@@ -349,6 +368,10 @@ func (m model) View() string {
 		}
 	}
 
+	if len(implied) > 0 {
+		statusMsg += fmt.Sprintf(" - %d more included as dependencies", len(implied))
+	}
+
 	// Add the status bar
 	sb.WriteString(statusStyle.Render(statusMsg))
 
@@ -364,11 +387,18 @@ func (m model) View() string {
 
 // Show displays a checkbox menu with the provided options.
 func Show(options []Option) ([]Option, error) {
+	return ShowWithTitle("Select resources to apply", options)
+}
+
+// ShowWithTitle displays a checkbox menu with the given title and options.
+// It returns the checked options, or nil if the user quits without confirming.
+func ShowWithTitle(title string, options []Option) ([]Option, error) {
 	if len(options) == 0 {
 		return nil, nil
 	}
 
 	m := model{
+		title:        title,
 		options:      options,
 		cursor:       0,
 		windowTop:    0,
@@ -377,6 +407,9 @@ func Show(options []Option) ([]Option, error) {
 		showHelp:     false,
 	}
 
+	// Bring pre-checked parents in line with their children
+	m.syncParents()
+
 	// Initialize styles
 	m.updateStyles()
 
@@ -390,7 +423,7 @@ func Show(options []Option) ([]Option, error) {
 		return nil, nil
 	}
 
-	var selected []Option
+	selected := make([]Option, 0)
 	for _, opt := range finalModel.(model).options {
 		if opt.Checked {
 			selected = append(selected, opt)
@@ -416,6 +449,7 @@ func (m *model) updateStyles() {
 	cursorStyle = lipgloss.NewStyle().Foreground(highlightColor)
 	checkedStyle = lipgloss.NewStyle().Foreground(successColor)
 	uncheckedStyle = lipgloss.NewStyle().Foreground(faintColor)
+	impliedStyle = lipgloss.NewStyle().Foreground(infoColor)
 	keyBindingStyle = lipgloss.NewStyle().Foreground(infoColor)
 	helpTextStyle = lipgloss.NewStyle().Foreground(faintColor)
 	instructionStyle = lipgloss.NewStyle().Foreground(faintColor)
@@ -452,9 +486,11 @@ func renderHelpTooltip() string {
 
 	helpContent.WriteString(keyStyle.Render("j/down") + ": Move cursor down\n")
 	helpContent.WriteString(keyStyle.Render("k/up") + ": Move cursor up\n")
-	helpContent.WriteString(keyStyle.Render("space") + ": Toggle selection\n")
+	helpContent.WriteString(keyStyle.Render("space") + ": Toggle selection (and nested items)\n")
 	helpContent.WriteString(keyStyle.Render("a") + ": Select all items\n")
 	helpContent.WriteString(keyStyle.Render("n") + ": Deselect all items\n")
+	helpContent.WriteString(keyStyle.Render("d") + ": Select with dependencies\n")
+	helpContent.WriteString(keyStyle.Render("D") + ": Select with dependents\n")
 	helpContent.WriteString(keyStyle.Render("g/home") + ": Jump to first item\n")
 	helpContent.WriteString(keyStyle.Render("G/end") + ": Jump to last item\n")
 	helpContent.WriteString(keyStyle.Render("enter") + ": Confirm selection\n")
@@ -464,6 +500,123 @@ func renderHelpTooltip() string {
 	return helpStyle.Render(helpContent.String())
 }
 
+// descendantsEnd returns the index just past the last descendant of option i.
+func (m *model) descendantsEnd(i int) int {
+	end := i + 1
+	for end < len(m.options) && m.options[end].Depth > m.options[i].Depth {
+		end++
+	}
+	return end
+}
+
+// toggle flips option i along with all of its descendants, then updates its ancestors.
+func (m *model) toggle(i int) {
+	checked := !m.options[i].Checked
+	for j := i; j < m.descendantsEnd(i); j++ {
+		m.options[j].Checked = checked
+	}
+	m.syncParents()
+}
+
+// syncParents marks each option with children as checked exactly when all its children are.
+func (m *model) syncParents() {
+	for i := len(m.options) - 1; i >= 0; i-- {
+		end := m.descendantsEnd(i)
+		if end == i+1 {
+			continue
+		}
+
+		allChecked := true
+		for j := i + 1; j < end; j++ {
+			if m.options[j].Depth == m.options[i].Depth+1 && !m.options[j].Checked {
+				allChecked = false
+				break
+			}
+		}
+		m.options[i].Checked = allChecked
+	}
+}
+
+// check checks option i and all of its descendants.
+func (m *model) check(i int) {
+	for j := i; j < m.descendantsEnd(i); j++ {
+		m.options[j].Checked = true
+	}
+}
+
+// checkNames checks every option whose name is in names.
+func (m *model) checkNames(names map[string]bool) {
+	for j := range m.options {
+		if names[m.options[j].Name] {
+			m.check(j)
+		}
+	}
+}
+
+// selectWithRequired checks option i, its descendants and everything they require.
+func (m *model) selectWithRequired(i int) {
+	required := make(map[string]bool)
+	for j := i; j < m.descendantsEnd(i); j++ {
+		for _, name := range m.options[j].Requires {
+			required[name] = true
+		}
+	}
+	m.check(i)
+	m.checkNames(required)
+	m.syncParents()
+}
+
+// selectWithDependents checks option i, its descendants and every option requiring any of them.
+func (m *model) selectWithDependents(i int) {
+	names := make(map[string]bool)
+	for j := i; j < m.descendantsEnd(i); j++ {
+		names[m.options[j].Name] = true
+	}
+
+	dependents := make(map[string]bool)
+	for _, option := range m.options {
+		for _, name := range option.Requires {
+			if names[name] {
+				dependents[option.Name] = true
+			}
+		}
+	}
+	m.check(i)
+	m.checkNames(dependents)
+	m.syncParents()
+}
+
+// implied returns the unchecked options required by a checked option.
+func (m *model) implied() map[string]bool {
+	implied := make(map[string]bool)
+	for _, option := range m.options {
+		if option.Checked {
+			for _, name := range option.Requires {
+				implied[name] = true
+			}
+		}
+	}
+	for _, option := range m.options {
+		if option.Checked {
+			delete(implied, option.Name)
+		}
+	}
+	return implied
+}
+
+// partiallyChecked reports whether option i is unchecked but some of its descendants are checked.
+func (m *model) partiallyChecked(i int) bool {
+	if m.options[i].Checked {
+		return false
+	}
+	for j := i + 1; j < m.descendantsEnd(i); j++ {
+		if m.options[j].Checked {
+			return true
+		}
+	}
+	return false
+}
+
 // ensureCursorVisible adjusts the view window to keep the cursor visible.
 func ensureCursorVisible(m *model) {
 	// Check if cursor is below the visible window
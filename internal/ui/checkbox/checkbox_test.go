@@ -0,0 +1,75 @@
+package checkbox
+
+import "testing"
+
+func checkedNames(m *model) []string {
+	var names []string
+	for _, option := range m.options {
+		if option.Checked {
+			names = append(names, option.Name)
+		}
+	}
+	return names
+}
+
+func TestToggleNestedOptions(t *testing.T) {
+	m := &model{options: []Option{
+		{Name: "module.app", Depth: 0},
+		{Name: "module.app.aws_instance.web", Depth: 1},
+		{Name: "module.app.aws_instance.web[0]", Depth: 2},
+		{Name: "module.app.aws_instance.web[1]", Depth: 2},
+		{Name: "aws_iam_role.deployer", Depth: 0},
+	}}
+
+	m.toggle(0)
+	if got := len(checkedNames(m)); got != 4 {
+		t.Fatalf("toggling a module checked %v, want the module and all its descendants", checkedNames(m))
+	}
+
+	m.toggle(3)
+	if m.options[0].Checked || m.options[1].Checked {
+		t.Errorf("parents still checked after unchecking a child: %v", checkedNames(m))
+	}
+	if !m.partiallyChecked(0) || !m.partiallyChecked(1) {
+		t.Error("parents of a partially selected subtree are not shown as partially checked")
+	}
+
+	m.toggle(3)
+	if !m.options[0].Checked || !m.options[1].Checked {
+		t.Errorf("parents not checked after selecting every child: %v", checkedNames(m))
+	}
+	if m.options[4].Checked {
+		t.Error("toggling a module changed a sibling")
+	}
+}
+
+func TestSelectWithDependencies(t *testing.T) {
+	newModel := func() *model {
+		return &model{options: []Option{
+			{Name: "aws_instance.web", Requires: []string{"aws_security_group.web"}},
+			{Name: "aws_security_group.web"},
+			{Name: "aws_iam_role.deployer"},
+		}}
+	}
+
+	m := newModel()
+	m.toggle(0)
+	if implied := m.implied(); !implied["aws_security_group.web"] || len(implied) != 1 {
+		t.Errorf("implied() = %v, want the security group", implied)
+	}
+
+	m = newModel()
+	m.selectWithRequired(0)
+	if got := checkedNames(m); len(got) != 2 || got[1] != "aws_security_group.web" {
+		t.Errorf("select with dependencies checked %v", got)
+	}
+	if len(m.implied()) != 0 {
+		t.Errorf("implied() = %v after selecting the dependencies", m.implied())
+	}
+
+	m = newModel()
+	m.selectWithDependents(1)
+	if got := checkedNames(m); len(got) != 2 || got[0] != "aws_instance.web" {
+		t.Errorf("select with dependents checked %v", got)
+	}
+}
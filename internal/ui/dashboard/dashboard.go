@@ -0,0 +1,413 @@
+// Package dashboard provides a live view of a terraform apply: every resource
+// of the plan with its status and elapsed time, and an overall progress bar.
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"tfapp/internal/ui"
+	"tfapp/internal/uijson"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Status is the progress of a resource change.
+type Status int
+
+// Resource change statuses.
+const (
+	StatusPending Status = iota
+	StatusInProgress
+	StatusDone
+	StatusFailed
+)
+
+// Resource is a resource change followed by the dashboard.
+type Resource struct {
+	Address string
+	Action  string // create, update, destroy, replace, ...
+	Status  Status
+	Elapsed time.Duration
+
+	started time.Time // Start of the current operation
+	steps   int       // Operations left; a replace deletes and creates
+}
+
+// eventMsg carries an event of the machine-readable UI output.
+type eventMsg struct {
+	event uijson.Event
+}
+
+// doneMsg is sent once the apply has finished.
+type doneMsg struct{}
+
+// tickMsg refreshes the elapsed times.
+type tickMsg time.Time
+
+// model represents the dashboard state.
+type model struct {
+	title       string
+	resources   []*Resource
+	index       map[string]*Resource
+	errors      []string // Summaries of error diagnostics
+	summary     string   // Terraform's closing message, e.g. "Apply complete! Resources: ..."
+	started     time.Time
+	now         time.Time
+	height      int
+	done        bool
+	interrupted bool
+	interrupt   func()
+}
+
+var (
+	successStyle = lipgloss.NewStyle()
+	errorStyle   = lipgloss.NewStyle()
+	activeStyle  = lipgloss.NewStyle()
+	faintStyle   = lipgloss.NewStyle()
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+)
+
+// updateStyles applies the configured colors.
+func updateStyles() {
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("success")))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("error")))
+	activeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("highlight")))
+	faintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("faint")))
+}
+
+// newModel creates a dashboard for the given resources.
+func newModel(title string, resources []Resource, interrupt func()) model {
+	now := time.Now()
+	m := model{
+		title:     title,
+		index:     make(map[string]*Resource),
+		started:   now,
+		now:       now,
+		height:    25, // Default height, adjusted when we receive WindowSizeMsg
+		interrupt: interrupt,
+	}
+	for i := range resources {
+		m.add(resources[i])
+	}
+	return m
+}
+
+// add starts following a resource change.
+func (m *model) add(resource Resource) *Resource {
+	r := resource
+	if r.Action == "delete" {
+		r.Action = "destroy" // As in plan summaries
+	}
+	r.steps = 1
+	if r.Action == "replace" {
+		r.steps = 2
+	}
+	m.resources = append(m.resources, &r)
+	m.index[r.Address] = &r
+	return &r
+}
+
+// Init implements tea.Model.
+func (m model) Init() tea.Cmd {
+	return tick()
+}
+
+// tick schedules the next refresh of the elapsed times.
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update implements tea.Model.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" && !m.interrupted {
+			// Let terraform stop gracefully rather than quitting under it
+			m.interrupted = true
+			if m.interrupt != nil {
+				m.interrupt()
+			}
+		}
+	case tickMsg:
+		m.now = time.Time(msg)
+		return m, tick()
+	case eventMsg:
+		m.now = time.Now()
+		m.handle(msg.event)
+	case doneMsg:
+		m.now = time.Now()
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// handle updates the resources from an event of the apply output.
+func (m *model) handle(event uijson.Event) {
+	switch event := event.(type) {
+	case *uijson.PlannedChangeEvent:
+		// Applying without a saved plan plans first
+		if m.index[event.Resource.Addr] == nil && isApplied(event.Action) {
+			m.add(Resource{Address: event.Resource.Addr, Action: event.Action})
+		}
+	case *uijson.ApplyStartEvent:
+		r := m.resource(event.Hook)
+		if r.Status == StatusPending {
+			r.Status = StatusInProgress
+		}
+		r.started = m.now
+	case *uijson.ApplyCompleteEvent:
+		r := m.resource(event.Hook)
+		r.Elapsed += m.elapsedOf(r, event.Hook)
+		r.started = time.Time{}
+		if r.steps--; r.steps <= 0 && r.Status != StatusFailed {
+			r.Status = StatusDone
+		}
+	case *uijson.ApplyErroredEvent:
+		r := m.resource(event.Hook)
+		r.Elapsed += m.elapsedOf(r, event.Hook)
+		r.started = time.Time{}
+		r.Status = StatusFailed
+	case *uijson.DiagnosticEvent:
+		if event.Severity == "error" {
+			m.errors = append(m.errors, event.Summary)
+		}
+	case *uijson.ChangeSummaryEvent:
+		if event.Operation != "plan" {
+			m.summary = event.Text()
+		}
+	}
+}
+
+// isApplied reports whether a planned change action runs during apply.
+func isApplied(action string) bool {
+	switch action {
+	case "create", "update", "delete", "replace":
+		return true
+	}
+	return false
+}
+
+// resource returns the resource a hook is about, following it if it was not in the plan.
+func (m *model) resource(hook uijson.Hook) *Resource {
+	if r, ok := m.index[hook.Resource.Addr]; ok {
+		return r
+	}
+	return m.add(Resource{Address: hook.Resource.Addr, Action: hook.Action})
+}
+
+// elapsedOf returns how long the operation a hook finishes took, preferring terraform's count.
+func (m *model) elapsedOf(r *Resource, hook uijson.Hook) time.Duration {
+	if hook.ElapsedSeconds > 0 {
+		return time.Duration(hook.ElapsedSeconds * float64(time.Second))
+	}
+	if !r.started.IsZero() {
+		return m.now.Sub(r.started)
+	}
+	return 0
+}
+
+// elapsed returns the time a resource has spent being applied so far.
+func (m model) elapsed(r *Resource) time.Duration {
+	if r.started.IsZero() {
+		return r.Elapsed
+	}
+	return r.Elapsed + m.now.Sub(r.started)
+}
+
+// counts returns the number of finished and failed resources.
+func (m model) counts() (finished, failed int) {
+	for _, r := range m.resources {
+		switch r.Status {
+		case StatusDone:
+			finished++
+		case StatusFailed:
+			finished++
+			failed++
+		}
+	}
+	return finished, failed
+}
+
+// View implements tea.Model.
+func (m model) View() string {
+	var sb strings.Builder
+
+	finished, failed := m.counts()
+	total := len(m.resources)
+	sb.WriteString(titleStyle.Render(m.title) + "  ")
+	sb.WriteString(progressBar(finished, total, 30))
+	sb.WriteString(fmt.Sprintf("  %d/%d  %s", finished, total, formatDuration(m.now.Sub(m.started))))
+	if failed > 0 {
+		sb.WriteString("  " + errorStyle.Render(fmt.Sprintf("%d failed", failed)))
+	}
+	sb.WriteString("\n\n")
+
+	width := 0
+	for _, r := range m.resources {
+		width = max(width, len(r.Address))
+	}
+
+	rows, hidden := m.visibleResources()
+	for _, r := range rows {
+		line := fmt.Sprintf("%s %-*s  %-8s", statusSymbol(r.Status), width, r.Address, r.Action)
+		if r.Status != StatusPending {
+			line += "  " + formatDuration(m.elapsed(r))
+		}
+		sb.WriteString("  " + styleFor(r.Status).Render(line) + "\n")
+	}
+	if hidden != "" {
+		sb.WriteString("  " + faintStyle.Render(hidden) + "\n")
+	}
+
+	for _, err := range m.errors {
+		sb.WriteString("\n" + errorStyle.Render("Error: "+err))
+	}
+	if len(m.errors) > 0 {
+		sb.WriteString("\n")
+	}
+
+	switch {
+	case m.done && m.summary != "":
+		sb.WriteString("\n" + successStyle.Render(m.summary) + "\n")
+	case m.done:
+	case m.interrupted:
+		sb.WriteString("\n" + faintStyle.Render("Interrupting, waiting for terraform to stop...") + "\n")
+	default:
+		sb.WriteString("\n" + faintStyle.Render("ctrl+c: interrupt") + "\n")
+	}
+
+	return sb.String()
+}
+
+// visibleResources returns the resources that fit on the screen, with a note on
+// the ones left out. Finished resources are left out first, then pending ones;
+// once the apply is done every resource is shown.
+func (m model) visibleResources() ([]*Resource, string) {
+	room := m.height - 8 // Title, progress, errors and help
+	if m.done || len(m.resources) <= room {
+		return m.resources, ""
+	}
+	room = max(room, 3)
+
+	var active, pending []*Resource
+	done := 0
+	for _, r := range m.resources {
+		switch r.Status {
+		case StatusInProgress, StatusFailed:
+			active = append(active, r)
+		case StatusPending:
+			pending = append(pending, r)
+		default:
+			done++
+		}
+	}
+
+	rows := active
+	if len(rows) > room {
+		rows = rows[:room]
+	}
+	shownPending := min(len(pending), room-len(rows))
+	rows = append(rows, pending[:shownPending]...)
+
+	var notes []string
+	if done > 0 {
+		notes = append(notes, fmt.Sprintf("%d done", done))
+	}
+	if left := len(pending) - shownPending; left > 0 {
+		notes = append(notes, fmt.Sprintf("%d more pending", left))
+	}
+	if left := len(active) - min(len(active), room); left > 0 {
+		notes = append(notes, fmt.Sprintf("%d more in progress", left))
+	}
+	return rows, "… " + strings.Join(notes, ", ")
+}
+
+// progressBar renders the share of finished resources.
+func progressBar(finished, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = finished * width / total
+	}
+	return activeStyle.Render(strings.Repeat("█", filled)) + faintStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// statusSymbol returns the marker of a status.
+func statusSymbol(status Status) string {
+	switch status {
+	case StatusInProgress:
+		return "◐"
+	case StatusDone:
+		return "✓"
+	case StatusFailed:
+		return "✗"
+	}
+	return "·"
+}
+
+// styleFor returns the style of a resource line.
+func styleFor(status Status) lipgloss.Style {
+	switch status {
+	case StatusInProgress:
+		return activeStyle
+	case StatusDone:
+		return successStyle
+	case StatusFailed:
+		return errorStyle
+	}
+	return faintStyle
+}
+
+// formatDuration formats an elapsed time like terraform does, e.g. "1m12s".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// Run shows the dashboard while apply runs. apply sends the events of
+// terraform's machine-readable output to the given channel; the dashboard stops
+// once apply returns, leaving the final status of every resource on the screen.
+// Pressing Ctrl+C cancels the context passed to apply.
+func Run(ctx context.Context, title string, resources []Resource, apply func(ctx context.Context, events chan<- uijson.Event) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updateStyles()
+	p := tea.NewProgram(newModel(title, resources, cancel))
+
+	result := make(chan error, 1)
+	go func() {
+		events := make(chan uijson.Event)
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			for event := range events {
+				p.Send(eventMsg{event})
+			}
+		}()
+
+		err := apply(ctx, events)
+		close(events)
+		<-forwarded
+		p.Send(doneMsg{})
+		result <- err
+	}()
+
+	if _, err := p.Run(); err != nil {
+		cancel()
+		<-result
+		return fmt.Errorf("error running apply dashboard: %w", err)
+	}
+	return <-result
+}
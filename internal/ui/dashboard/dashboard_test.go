@@ -0,0 +1,138 @@
+package dashboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"tfapp/internal/testutil"
+	"tfapp/internal/uijson"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var keyCtrlC = tea.KeyMsg{Type: tea.KeyCtrlC}
+
+// feed passes every message of a UI fixture to the model.
+func feed(t *testing.T, m model, fixture string) model {
+	t.Helper()
+
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, fixture)), []byte("\n")) {
+		msg, err := uijson.Decode(line)
+		if err != nil {
+			t.Fatalf("decoding fixture: %v", err)
+		}
+		event := msg.Event()
+		if event == nil {
+			continue
+		}
+		updated, _ := m.Update(eventMsg{event})
+		m = updated.(model)
+	}
+	return m
+}
+
+func TestDashboardFollowsApply(t *testing.T) {
+	m := newModel("Applying terraform plan", []Resource{
+		{Address: "aws_db_instance.main", Action: "replace"},
+		{Address: "aws_s3_bucket.logs", Action: "update"},
+		{Address: "aws_iam_role.legacy", Action: "destroy"},
+		{Address: "aws_instance.cache", Action: "replace"},
+	}, nil)
+	m = feed(t, m, "apply_errored")
+
+	want := map[string]Status{
+		"aws_db_instance.main": StatusFailed,
+		"aws_s3_bucket.logs":   StatusDone,
+		"aws_iam_role.legacy":  StatusDone,
+		"aws_instance.cache":   StatusDone, // Done only once the deposed object is destroyed too
+	}
+	for address, status := range want {
+		if got := m.index[address].Status; got != status {
+			t.Errorf("%s status = %v, want %v", address, got, status)
+		}
+	}
+	if len(m.resources) != 4 {
+		t.Errorf("got %d resources, want the 4 planned ones", len(m.resources))
+	}
+	if got := m.index["aws_instance.cache"].Elapsed; got != 15*time.Second {
+		t.Errorf("cache elapsed = %v, want 15s", got)
+	}
+	if got := m.index["aws_db_instance.main"].Elapsed; got != 25*time.Second {
+		t.Errorf("database elapsed = %v, want 25s", got)
+	}
+
+	finished, failed := m.counts()
+	if finished != 4 || failed != 1 {
+		t.Errorf("counts = %d finished, %d failed", finished, failed)
+	}
+
+	updated, _ := m.Update(doneMsg{})
+	view := updated.(model).View()
+	for _, want := range []string{"4/4", "1 failed", "✗ aws_db_instance.main", "Error: creating RDS DB Instance (main)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestDashboardAddsUnplannedResources(t *testing.T) {
+	m := newModel("Applying terraform to selected resources", nil, nil)
+	m = feed(t, m, "apply_errored")
+
+	if len(m.resources) != 4 {
+		t.Fatalf("got %d resources, want the 4 applied ones", len(m.resources))
+	}
+	if r := m.index["aws_iam_role.legacy"]; r.Action != "destroy" || r.Status != StatusDone {
+		t.Errorf("unexpected role: %+v", r)
+	}
+}
+
+func TestDashboardInterrupt(t *testing.T) {
+	interrupted := 0
+	m := newModel("Applying terraform plan", nil, func() { interrupted++ })
+
+	for range 2 {
+		updated, _ := m.Update(keyCtrlC)
+		m = updated.(model)
+	}
+	if interrupted != 1 {
+		t.Errorf("interrupt called %d times, want once", interrupted)
+	}
+	if !strings.Contains(m.View(), "waiting for terraform to stop") {
+		t.Errorf("view does not show the interruption:\n%s", m.View())
+	}
+}
+
+func TestVisibleResources(t *testing.T) {
+	var resources []Resource
+	for _, address := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k", "l"} {
+		resources = append(resources, Resource{Address: "null_resource." + address, Action: "create"})
+	}
+	m := newModel("Applying", resources, nil)
+	m.height = 12
+	m.resources[0].Status = StatusDone
+	m.resources[5].Status = StatusInProgress
+
+	rows, hidden := m.visibleResources()
+	if len(rows) != 4 || rows[0].Address != "null_resource.f" {
+		t.Errorf("rows = %v, want the resource in progress first", rows)
+	}
+	if hidden != "… 1 done, 7 more pending" {
+		t.Errorf("hidden = %q", hidden)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                     "0s",
+		12*time.Second + 400*time.Millisecond: "12s",
+		72 * time.Second:                      "1m12s",
+	}
+	for d, want := range tests {
+		if got := formatDuration(d); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
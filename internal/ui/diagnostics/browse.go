@@ -0,0 +1,136 @@
+package diagnostics
+
+import (
+	"fmt"
+	"strings"
+
+	"tfapp/internal/ui"
+	"tfapp/internal/uijson"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// browser lists diagnostics one per line and shows the selected one in full below the list.
+type browser struct {
+	title       string
+	diagnostics []uijson.Diagnostic
+	cursor      int
+	top         int // First diagnostic of the visible part of the list
+	height      int
+}
+
+var (
+	cursorStyle  = lipgloss.NewStyle()
+	errorStyle   = lipgloss.NewStyle()
+	warningStyle = lipgloss.NewStyle()
+	faintStyle   = lipgloss.NewStyle()
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+)
+
+// updateStyles applies the configured colors.
+func updateStyles() {
+	cursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("highlight"))).Bold(true)
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("error")))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("warning")))
+	faintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("faint")))
+}
+
+// newBrowser creates a browser for the given diagnostics.
+func newBrowser(title string, diagnostics []uijson.Diagnostic) browser {
+	return browser{
+		title:       title,
+		diagnostics: diagnostics,
+		height:      25, // Default height, adjusted when we receive WindowSizeMsg
+	}
+}
+
+// Init implements tea.Model.
+func (m browser) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+func (m browser) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc", "enter":
+			return m, tea.Quit
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(m.diagnostics)-1 {
+				m.cursor++
+			}
+		case "home", "g":
+			m.cursor = 0
+		case "end", "G":
+			m.cursor = len(m.diagnostics) - 1
+		}
+	}
+
+	// Keep the cursor in the visible part of the list
+	rows := m.listHeight()
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+rows {
+		m.top = m.cursor - rows + 1
+	}
+	return m, nil
+}
+
+// listHeight returns the number of list rows, leaving most of the screen to the selected diagnostic.
+func (m browser) listHeight() int {
+	return max(min(len(m.diagnostics), m.height/3), 1)
+}
+
+// View implements tea.Model.
+func (m browser) View() string {
+	var sb strings.Builder
+
+	errors, warnings := Count(m.diagnostics)
+	sb.WriteString(titleStyle.Render(m.title) + "  ")
+	sb.WriteString(faintStyle.Render(fmt.Sprintf("%d errors, %d warnings", errors, warnings)) + "\n\n")
+
+	end := min(m.top+m.listHeight(), len(m.diagnostics))
+	for i := m.top; i < end; i++ {
+		d := m.diagnostics[i]
+		style := errorStyle
+		if d.Severity == "warning" {
+			style = warningStyle
+		}
+		prefix := "  "
+		line := style.Render(Summary(d))
+		if i == m.cursor {
+			prefix = cursorStyle.Render("> ")
+			line = cursorStyle.Render(Summary(d))
+		}
+		sb.WriteString(prefix + line + "\n")
+	}
+	if hidden := len(m.diagnostics) - (end - m.top); hidden > 0 {
+		sb.WriteString(faintStyle.Render(fmt.Sprintf("  … %d more", hidden)) + "\n")
+	}
+
+	sb.WriteString("\n" + Format(m.diagnostics[m.cursor]))
+	sb.WriteString("\n" + faintStyle.Render("↑/↓: select  q: quit") + "\n")
+	return sb.String()
+}
+
+// Browse shows the diagnostics in a list until the user quits.
+func Browse(title string, diagnostics []uijson.Diagnostic) error {
+	if len(diagnostics) == 0 {
+		return nil
+	}
+
+	updateStyles()
+	p := tea.NewProgram(newBrowser(title, diagnostics), tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("error running diagnostics browser: %w", err)
+	}
+	return nil
+}
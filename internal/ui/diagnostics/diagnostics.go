@@ -0,0 +1,176 @@
+// Package diagnostics renders terraform's warnings and errors like a compiler
+// does: the summary, the location, the offending source lines with the range
+// highlighted, and the detail. Many diagnostics can be browsed in a list.
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"tfapp/internal/ui"
+	"tfapp/internal/uijson"
+)
+
+// BrowseThreshold is the number of diagnostics above which interactive
+// commands open the browser rather than printing them all.
+const BrowseThreshold = 3
+
+// Write writes the diagnostics, separated by blank lines.
+func Write(w io.Writer, diagnostics []uijson.Diagnostic) {
+	for i, diagnostic := range diagnostics {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprint(w, Format(diagnostic))
+	}
+}
+
+// Format renders one diagnostic:
+//
+//	error: Reference to undeclared input variable
+//	  --> main.tf:2:12 (provider "aws")
+//	   |
+//	 2 |   region = var.region
+//	   |            ^^^^^^^^^^
+//	   |
+//	  An input variable with the name "region" has not been declared.
+func Format(d uijson.Diagnostic) string {
+	var sb strings.Builder
+	color := severityColor(d.Severity)
+
+	fmt.Fprintf(&sb, "%s%s%s:%s %s%s%s\n", color, ui.TextBold, severityName(d.Severity), ui.ColorReset, ui.TextBold, d.Summary, ui.ColorReset)
+
+	if location := Location(d); location != "" {
+		if d.Snippet != nil && d.Snippet.Context != nil && *d.Snippet.Context != "" {
+			location += " (" + *d.Snippet.Context + ")"
+		}
+		fmt.Fprintf(&sb, "  %s-->%s %s\n", ui.ColorFaint, ui.ColorReset, location)
+	} else if d.Address != "" {
+		fmt.Fprintf(&sb, "  %s-->%s %s\n", ui.ColorFaint, ui.ColorReset, d.Address)
+	}
+
+	if d.Snippet != nil && d.Snippet.Code != "" {
+		writeSnippet(&sb, d.Snippet, color)
+	}
+
+	if d.Detail != "" {
+		for _, line := range strings.Split(strings.TrimRight(d.Detail, "\n"), "\n") {
+			if line == "" {
+				sb.WriteString("\n")
+				continue
+			}
+			sb.WriteString("  " + line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// Location returns where a diagnostic points, e.g. main.tf:2:12, or "" if it has no range.
+func Location(d uijson.Diagnostic) string {
+	if d.Range == nil || d.Range.Filename == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", d.Range.Filename, d.Range.Start.Line, d.Range.Start.Column)
+}
+
+// Summary renders a diagnostic on one line, e.g. "✗ main.tf:2:12  Reference to undeclared input variable".
+func Summary(d uijson.Diagnostic) string {
+	symbol := "✗"
+	if d.Severity == "warning" {
+		symbol = "!"
+	}
+	line := symbol + " "
+	if location := Location(d); location != "" {
+		line += location + "  "
+	}
+	return line + d.Summary
+}
+
+// writeSnippet writes the source lines of a snippet with a gutter of line
+// numbers, highlighting the range and, when it fits on one line, underlining it.
+func writeSnippet(sb *strings.Builder, snippet *uijson.Snippet, color string) {
+	lines := strings.Split(strings.TrimRight(snippet.Code, "\n"), "\n")
+	width := len(fmt.Sprint(snippet.StartLine + len(lines) - 1))
+	gutter := func(label string) string {
+		return fmt.Sprintf("%s%*s |%s", ui.ColorFaint, width+1, label, ui.ColorReset)
+	}
+
+	start, end := snippet.HighlightStartOffset, snippet.HighlightEndOffset
+	sb.WriteString(gutter("") + "\n")
+
+	offset := 0 // Byte offset of the current line within the code
+	for i, line := range lines {
+		lineStart, lineEnd := offset, offset+len(line)
+		offset = lineEnd + 1
+
+		from := clamp(start-lineStart, 0, len(line))
+		to := clamp(end-lineStart, 0, len(line))
+		if from < to {
+			line = line[:from] + color + ui.TextUnderline + line[from:to] + ui.ColorReset + line[to:]
+		}
+		fmt.Fprintf(sb, "%s %s\n", gutter(fmt.Sprint(snippet.StartLine+i)), line)
+
+		// Underline a highlight within a single line
+		if start >= lineStart && end <= lineEnd && start < end {
+			raw := lines[i]
+			fmt.Fprintf(sb, "%s %s%s%s%s\n", gutter(""), indent(raw[:start-lineStart]), color,
+				strings.Repeat("^", max(utf8.RuneCountInString(raw[start-lineStart:end-lineStart]), 1)), ui.ColorReset)
+		}
+	}
+
+	if len(snippet.Values) > 0 {
+		sb.WriteString(gutter("") + "\n")
+		for _, value := range snippet.Values {
+			fmt.Fprintf(sb, "%s %s=%s %s %s\n", gutter(""), ui.ColorFaint, ui.ColorReset, value.Traversal, value.Statement)
+		}
+	}
+	sb.WriteString(gutter("") + "\n")
+}
+
+// indent returns blank space as wide as text, keeping its tabs so the underline lines up.
+func indent(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if r == '\t' {
+			sb.WriteRune('\t')
+		} else {
+			sb.WriteRune(' ')
+		}
+	}
+	return sb.String()
+}
+
+// clamp limits n to the range [low, high].
+func clamp(n, low, high int) int {
+	return min(max(n, low), high)
+}
+
+// severityName returns the header of a severity.
+func severityName(severity string) string {
+	if severity == "" {
+		return "error"
+	}
+	return severity
+}
+
+// severityColor returns the color of a severity.
+func severityColor(severity string) string {
+	if severity == "warning" {
+		return ui.ColorWarning
+	}
+	return ui.ColorError
+}
+
+// Count returns the number of errors and warnings among the diagnostics.
+func Count(diagnostics []uijson.Diagnostic) (errors, warnings int) {
+	for _, d := range diagnostics {
+		if d.Severity == "warning" {
+			warnings++
+		} else {
+			errors++
+		}
+	}
+	return errors, warnings
+}
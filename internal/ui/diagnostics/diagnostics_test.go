@@ -0,0 +1,107 @@
+package diagnostics
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"tfapp/internal/testutil"
+	"tfapp/internal/uijson"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// fixtureDiagnostics returns the diagnostics of a UI fixture.
+func fixtureDiagnostics(t *testing.T, fixture string) []uijson.Diagnostic {
+	t.Helper()
+
+	var diagnostics []uijson.Diagnostic
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, fixture)), []byte("\n")) {
+		msg, err := uijson.Decode(line)
+		if err != nil {
+			t.Fatalf("decoding fixture: %v", err)
+		}
+		if msg.Diagnostic != nil {
+			diagnostics = append(diagnostics, *msg.Diagnostic)
+		}
+	}
+	return diagnostics
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	Write(&buf, fixtureDiagnostics(t, "plan_errored"))
+	testutil.Golden(t, "plan_errored", []byte(ansi.ReplaceAllString(buf.String(), "")))
+}
+
+func TestFormatMultiLineHighlight(t *testing.T) {
+	context := `resource "aws_instance" "web"`
+	d := uijson.Diagnostic{
+		Severity: "error",
+		Summary:  "Invalid value",
+		Range:    &uijson.Range{Filename: "main.tf", Start: uijson.Pos{Line: 4, Column: 10}},
+		Snippet: &uijson.Snippet{
+			Context:              &context,
+			Code:                 "  tags = {\n    Name = 1\n  }",
+			StartLine:            4,
+			HighlightStartOffset: 9,
+			HighlightEndOffset:   26,
+			Values:               []uijson.ExpressionValue{{Traversal: "var.name", Statement: "is null"}},
+		},
+	}
+
+	got := ansi.ReplaceAllString(Format(d), "")
+	want := "error: Invalid value\n" +
+		"  --> main.tf:4:10 (resource \"aws_instance\" \"web\")\n" +
+		"   |\n" +
+		" 4 |   tags = {\n" +
+		" 5 |     Name = 1\n" +
+		" 6 |   }\n" +
+		"   |\n" +
+		"   | = var.name is null\n" +
+		"   |\n"
+	if got != want {
+		t.Errorf("Format =\n%s\nwant\n%s", got, want)
+	}
+	// No underline across lines
+	if strings.Contains(got, "^") {
+		t.Errorf("multi-line highlight was underlined:\n%s", got)
+	}
+}
+
+func TestFormatWithoutRange(t *testing.T) {
+	d := uijson.Diagnostic{Severity: "error", Summary: "Provider produced inconsistent result", Address: "aws_instance.web", Detail: "Please report this."}
+
+	got := ansi.ReplaceAllString(Format(d), "")
+	want := "error: Provider produced inconsistent result\n  --> aws_instance.web\n  Please report this.\n"
+	if got != want {
+		t.Errorf("Format = %q, want %q", got, want)
+	}
+}
+
+func TestBrowserSelects(t *testing.T) {
+	diagnostics := fixtureDiagnostics(t, "plan_errored")
+	var m tea.Model = newBrowser("Plan failed", diagnostics)
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	view := ansi.ReplaceAllString(m.View(), "")
+	if !strings.Contains(view, "> ✗ main.tf:2:12  Reference to undeclared input variable") {
+		t.Errorf("second diagnostic is not selected:\n%s", view)
+	}
+	if !strings.Contains(view, "1 errors, 1 warnings") {
+		t.Errorf("view does not count the diagnostics:\n%s", view)
+	}
+
+	// The cursor stops at the last diagnostic
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if cursor := m.(browser).cursor; cursor != 1 {
+		t.Errorf("cursor = %d, want 1", cursor)
+	}
+
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")}); cmd == nil {
+		t.Error("q does not quit")
+	}
+}
@@ -30,6 +30,7 @@ func (o Option) String() string {
 
 // model represents the menu state.
 type model struct {
+	title    string
 	options  []Option
 	cursor   int
 	selected *Option
@@ -101,7 +102,7 @@ var (
 func (m model) View() string {
 	var s strings.Builder
 
-	s.WriteString("Select Action\n\n")
+	s.WriteString(m.title + "\n\n")
 
 	for i, option := range m.options {
 		var cursor string
@@ -134,7 +135,18 @@ func (m model) View() string {
 
 // Show displays the menu and returns the selected option.
 func Show() (string, error) {
-	p := tea.NewProgram(initialModel())
+	return run(initialModel())
+}
+
+// Select displays a menu of the given options under title and returns the
+// name of the selected option, or an empty string if the user quit.
+func Select(title string, options []Option) (string, error) {
+	return run(newModel(title, options))
+}
+
+// run runs a menu model and returns the choice made.
+func run(mod model) (string, error) {
+	p := tea.NewProgram(mod)
 	m, err := p.Run()
 	if err != nil {
 		return "", err
@@ -150,8 +162,18 @@ func Show() (string, error) {
 // ClearMenuOutput clears the menu output area from the terminal
 // without clearing other content.
 func ClearMenuOutput() {
-	// Calculate number of lines in menu (header + blank line + 4 options + blank line)
-	menuHeight := 7
+	clearOutput(len(choices))
+}
+
+// ClearSelectOutput clears the output of a menu shown by Select with the given options.
+func ClearSelectOutput(options []Option) {
+	clearOutput(len(options))
+}
+
+// clearOutput clears the output of a menu with the given number of options.
+func clearOutput(count int) {
+	// Calculate number of lines in menu (header + blank line + options + blank line)
+	menuHeight := 3 + count
 
 	// ANSI escape sequence to:
 	// 1. Move cursor up menuHeight lines
@@ -159,21 +181,27 @@ func ClearMenuOutput() {
 	fmt.Printf("\033[%dA\033[J", menuHeight)
 }
 
-// initialModel creates a new model for the menu.
-func initialModel() model {
-	choices := []string{
+// choices are the actions offered by the menu, with their descriptions.
+var (
+	choices = []string{
 		"Apply Plan",
 		"Show Full Plan",
 		"Do a target apply",
+		"Apply all except...",
 		"Exit",
 	}
 
-	descriptions := []string{
+	descriptions = []string{
 		"Apply the plan to your infrastructure",
 		"View the plan with collapsible resources",
 		"Apply specific resources from the plan",
+		"Apply the plan without the resources you deselect",
 		"Exit without applying changes",
 	}
+)
+
+// initialModel creates a new model for the menu.
+func initialModel() model {
 
 	options := make([]Option, len(choices))
 	for i, choice := range choices {
@@ -184,7 +212,13 @@ func initialModel() model {
 		}
 	}
 
+	return newModel("Select Action", options)
+}
+
+// newModel creates a menu model with the given title and options.
+func newModel(title string, options []Option) model {
 	mod := model{
+		title:   title,
 		options: options,
 		cursor:  0,
 	}
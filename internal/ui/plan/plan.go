@@ -2,13 +2,13 @@
 package plan
 
 import (
-	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 
+	"tfapp/internal/planjson"
 	"tfapp/internal/ui"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -29,6 +29,16 @@ type TreeNode struct {
 	PreviousAddress string      // Previous address for moved resources
 	IsDrifted       bool        // Whether this resource has drifted
 	ActionReason    string      // Reason for the action (e.g., tainted)
+	Address         string      // Resource address, for resource nodes of planned changes
+	Level           string      // Severity of annotation nodes (error, warning, info)
+}
+
+// Annotation is a note attached to a resource in the plan viewer, such as a failed policy check.
+type Annotation struct {
+	Level  string // "error", "warning" or "info"
+	Badge  string // Short marker shown after the resource line, e.g. "✗ policy"
+	Text   string // Line shown at the top of the expanded resource
+	Column string // Value shown in a column aligned across resources, e.g. a cost
 }
 
 // Model represents the state of the plan viewer.
@@ -52,7 +62,66 @@ type Model struct {
 
 // New creates a new plan viewer model.
 func New(planOutput string) Model {
-	nodes := parsePlan(planOutput)
+	return newModel(parsePlan(planOutput))
+}
+
+// NewFromPlan creates a new plan viewer model from an already parsed plan.
+func NewFromPlan(plan *planjson.Plan) Model {
+	return newModel(buildPlanTree(plan))
+}
+
+// NewFromPlanAnnotated creates a plan viewer model with annotations attached to resources by address.
+func NewFromPlanAnnotated(plan *planjson.Plan, annotations map[string][]Annotation) Model {
+	nodes := buildPlanTree(plan)
+	annotate(nodes, annotations)
+	return newModel(nodes)
+}
+
+// annotate adds the annotations of each resource node as its first children,
+// their badges to the resource line and their column values, aligned, after it.
+func annotate(nodes []*TreeNode, annotations map[string][]Annotation) {
+	columns := make(map[*TreeNode]string)
+	for _, node := range nodes {
+		notes := annotations[node.Address]
+		if node.Address == "" || len(notes) == 0 {
+			continue
+		}
+
+		var children []*TreeNode
+		seen := make(map[string]bool)
+		for _, note := range notes {
+			if note.Badge != "" && !seen[note.Badge] {
+				seen[note.Badge] = true
+				node.Text += "  " + note.Badge
+			}
+			if note.Column != "" {
+				columns[node] = note.Column
+			}
+			if note.Text == "" {
+				continue
+			}
+			children = append(children, &TreeNode{
+				Text:   note.Text,
+				Type:   "annotation",
+				Depth:  node.Depth + 1,
+				Parent: node,
+				Level:  note.Level,
+			})
+		}
+		node.Children = append(children, node.Children...)
+	}
+
+	width := 0
+	for node := range columns {
+		width = max(width, lipgloss.Width(node.Text))
+	}
+	for node, column := range columns {
+		node.Text += strings.Repeat(" ", width-lipgloss.Width(node.Text)+2) + column
+	}
+}
+
+// newModel creates a plan viewer model for the given tree of nodes.
+func newModel(nodes []*TreeNode) Model {
 
 	// Set only the root section nodes to expanded by default, collapse all others
 	for _, node := range nodes {
@@ -567,6 +636,15 @@ func (m Model) View() string {
 				Foreground(lipgloss.Color("#FFFFFF")).
 				Background(lipgloss.Color(ui.GetHexColorByName("highlight"))).
 				Render(line)
+		} else if node.Type == "annotation" {
+			switch node.Level {
+			case "error":
+				colorized = ui.ColorError + line + ui.ColorForegroundReset
+			case "warning":
+				colorized = ui.ColorWarning + line + ui.ColorForegroundReset
+			default:
+				colorized = ui.ColorInfo + line + ui.ColorForegroundReset
+			}
 		} else if node.IsDrifted {
 			// Apply drift color only to the "has drifted" phrase
 			if strings.Contains(line, "has drifted") {
@@ -860,8 +938,21 @@ func (m Model) View() string {
 
 // Show displays the plan viewer and returns when the user quits.
 func Show(planOutput string) error {
-	model := New(planOutput)
+	return run(New(planOutput))
+}
+
+// ShowPlan displays the plan viewer for a parsed plan and returns when the user quits.
+func ShowPlan(plan *planjson.Plan) error {
+	return run(NewFromPlan(plan))
+}
 
+// ShowPlanAnnotated displays the plan viewer with annotations attached to resources by address.
+func ShowPlanAnnotated(plan *planjson.Plan, annotations map[string][]Annotation) error {
+	return run(NewFromPlanAnnotated(plan, annotations))
+}
+
+// run runs the plan viewer program until the user quits.
+func run(model Model) error {
 	p := tea.NewProgram(
 		model,
 		tea.WithAltScreen(),       // Use alternate screen buffer
@@ -1250,79 +1341,6 @@ func flattenNodes(nodes []*TreeNode) []*TreeNode {
 	return result
 }
 
-// createClosingBrace creates a closing brace node with consistent type and formatting
-func createClosingBrace(depth int, parent *TreeNode) *TreeNode {
-	return &TreeNode{
-		Text:       "}",
-		Expanded:   true,
-		Type:       "closing_brace",
-		Depth:      depth,
-		Parent:     parent,
-		Toggleable: false,
-		ChangeType: "no-op",
-	}
-}
-
-// isEffectivelyEqual compares two values and determines if they're effectively equal
-// This handles special cases like empty strings, empty maps, and nulls
-func isEffectivelyEqual(a, b interface{}) bool {
-	// If both are nil or exactly equal, they're equal
-	if a == nil && b == nil {
-		return true
-	}
-	if reflect.DeepEqual(a, b) {
-		return true
-	}
-
-	// Check if one is nil and the other is an empty string
-	aStr, aIsStr := a.(string)
-	if aIsStr && b == nil && aStr == "" {
-		return true
-	}
-
-	bStr, bIsStr := b.(string)
-	if bIsStr && a == nil && bStr == "" {
-		return true
-	}
-
-	// Check for empty strings
-	if aIsStr && bIsStr && (aStr == "" && bStr == "") {
-		return true
-	}
-
-	// Check for empty maps
-	aMap, aIsMap := a.(map[string]interface{})
-	bMap, bIsMap := b.(map[string]interface{})
-	if aIsMap && bIsMap && (len(aMap) == 0 && len(bMap) == 0) {
-		return true
-	}
-
-	// Map to nil comparison
-	if aIsMap && b == nil && len(aMap) == 0 {
-		return true
-	}
-	if bIsMap && a == nil && len(bMap) == 0 {
-		return true
-	}
-
-	// Check for empty arrays
-	aSlice, aIsSlice := a.([]interface{})
-	bSlice, bIsSlice := b.([]interface{})
-	if aIsSlice && bIsSlice && (len(aSlice) == 0 && len(bSlice) == 0) {
-		return true
-	}
-
-	// Array to nil comparison
-	if aIsSlice && b == nil && len(aSlice) == 0 {
-		return true
-	}
-	if bIsSlice && a == nil && len(bSlice) == 0 {
-		return true
-	}
-
-	return false
-}
-
 // wrapText wraps text at the specified width while preserving ANSI color codes
 // and maintaining proper indentation for wrapped lines.
 func wrapText(text string, width int, indentStr string) string {
@@ -1403,9 +1421,7 @@ func wrapText(text string, width int, indentStr string) string {
 
 // parseTerraformPlanJSON parses the terraform show json output and builds a tree of nodes.
 func parseTerraformPlanJSON(planJSON string) []*TreeNode {
-	// Implementation - this function parses JSON format plans
-	var plan map[string]interface{}
-	err := json.Unmarshal([]byte(planJSON), &plan)
+	plan, err := planjson.Parse([]byte(planJSON))
 	if err != nil {
 		// If we can't parse JSON, return an error node
 		errorNode := &TreeNode{
@@ -1418,6 +1434,11 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 		return []*TreeNode{errorNode}
 	}
 
+	return buildPlanTree(plan)
+}
+
+// buildPlanTree builds the viewer's tree of nodes from a parsed plan.
+func buildPlanTree(plan *planjson.Plan) []*TreeNode {
 	// Root collection of nodes
 	var rootNodes []*TreeNode
 
@@ -1430,37 +1451,17 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 	driftCount := 0
 
 	// First, check for resource drift and add them directly as root nodes
-	if resourceDrift, ok := plan["resource_drift"].([]interface{}); ok && len(resourceDrift) > 0 {
+	if len(plan.ResourceDrift) > 0 {
 		// Create a map to store drifted resources by their path
 		driftedResources := make(map[string]*TreeNode)
 
 		// Process each drifted resource
-		for _, driftItem := range resourceDrift {
-			driftMap, ok := driftItem.(map[string]interface{})
-			if !ok {
-				continue
-			}
-
+		for _, drift := range plan.ResourceDrift {
 			driftCount++
-			address, _ := driftMap["address"].(string)
-			typeStr, _ := driftMap["type"].(string)
-			change, ok := driftMap["change"].(map[string]interface{})
-			if !ok {
-				continue
-			}
-
-			actions, _ := change["actions"].([]interface{})
-
-			// Convert actions to strings
-			actionStrs := make([]string, 0, len(actions))
-			for _, a := range actions {
-				if aStr, ok := a.(string); ok {
-					actionStrs = append(actionStrs, aStr)
-				}
-			}
+			address := drift.Address
 
 			// Create resource node as a root node
-			changeType := mapActionsToChangeType(actionStrs)
+			changeType := drift.Change.Actions.Summary()
 			resourceNode := &TreeNode{
 				Text:       fmt.Sprintf("# %s has drifted (%s)", address, changeType),
 				Expanded:   false, // Start collapsed
@@ -1473,7 +1474,7 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 
 			// Create a node for the resource block itself
 			resourceBlockNode := &TreeNode{
-				Text:       formatResourceDeclaration(address, typeStr, changeType),
+				Text:       formatResourceDeclaration(address, drift.Type, changeType),
 				Expanded:   false, // Start collapsed
 				Type:       "block",
 				Depth:      1, // One level deeper
@@ -1485,7 +1486,7 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 			resourceNode.Children = append(resourceNode.Children, resourceBlockNode)
 
 			// Add before/after details if available as children of the resource block
-			addResourceDiffNodes(resourceBlockNode, change)
+			addResourceDiffNodes(resourceBlockNode, drift.Change)
 
 			// Add closing brace
 			closingBraceNode := &TreeNode{
@@ -1523,8 +1524,7 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 	}
 
 	// Process resource changes
-	resourceChanges, ok := plan["resource_changes"].([]interface{})
-	if !ok {
+	if plan.ResourceChanges == nil {
 		// No changes found
 		noChangesNode := &TreeNode{
 			Text:       "No changes. Infrastructure is up-to-date.",
@@ -1549,55 +1549,28 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 	resources := make(map[string]resourceInfo)
 
 	// Process each resource change
-	for _, change := range resourceChanges {
-		changeMap, ok := change.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		address, _ := changeMap["address"].(string)
-		previousAddress, hasPrevious := changeMap["previous_address"].(string)
-		actionReason, _ := changeMap["action_reason"].(string)
-		typeStr, _ := changeMap["type"].(string)
-		mode, _ := changeMap["mode"].(string)
-
-		changeDetails, ok := changeMap["change"].(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		actions, _ := changeDetails["actions"].([]interface{})
-
+	for _, change := range plan.ResourceChanges {
 		// Skip no-ops
-		if len(actions) == 0 {
+		if change.Change.Actions.IsNoOp() {
 			continue
 		}
 
-		// Convert actions to strings
-		actionStrs := make([]string, 0, len(actions))
-		for _, a := range actions {
-			if aStr, ok := a.(string); ok {
-				actionStrs = append(actionStrs, aStr)
-			}
-		}
-
-		// Skip no-ops
-		if len(actionStrs) == 1 && actionStrs[0] == "no-op" {
-			continue
-		}
+		address := change.Address
+		previousAddress := change.PreviousAddress
+		actionReason := change.ActionReason
 
 		// Determine the change type
-		changeType := mapActionsToChangeType(actionStrs)
+		changeType := change.Change.Actions.Summary()
 
 		// Create resource node with appropriate text
 		var resourceText string
-		wasMoved := hasPrevious && previousAddress != address && previousAddress != ""
+		wasMoved := change.Moved()
 
 		if wasMoved {
 			resourceText = fmt.Sprintf("# %s will be %s (moved from %s)", address, getGrammaticalAction(changeType), previousAddress)
 			moveCount++
 		} else if actionReason != "" {
-			reasonText := getActionReasonDisplay(actionReason)
+			reasonText := planjson.ActionReasonDescription(actionReason)
 			resourceText = fmt.Sprintf("# %s will be %s (%s)", address, getGrammaticalAction(changeType), reasonText)
 		} else {
 			resourceText = fmt.Sprintf("# %s will be %s", address, getGrammaticalAction(changeType))
@@ -1613,6 +1586,7 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 			ChangeType:      changeType,
 			PreviousAddress: previousAddress,
 			ActionReason:    actionReason,
+			Address:         address,
 		}
 
 		// Create a node for the resource block itself with the appropriate formatting based on the action
@@ -1640,8 +1614,8 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 		// Format resource declaration (e.g., "+ resource "aws_instance" "example" {")
 		resourceBlockDeclaration := fmt.Sprintf("%s resource \"%s\" \"%s\" {",
 			resourceBlockPrefix,
-			typeStr,
-			getResourceNameFromAddress(address, mode, typeStr))
+			change.Type,
+			getResourceNameFromAddress(address, change.Mode, change.Type))
 
 		resourceBlockNode := &TreeNode{
 			Text:            resourceBlockDeclaration,
@@ -1657,7 +1631,7 @@ func parseTerraformPlanJSON(planJSON string) []*TreeNode {
 		resourceNode.Children = append(resourceNode.Children, resourceBlockNode)
 
 		// Add details as children of the resource block node
-		addResourceDiffNodes(resourceBlockNode, changeDetails, changeType)
+		addResourceDiffNodes(resourceBlockNode, change.Change)
 
 		// Add a closing brace node to the resource block
 		closingBraceNode := &TreeNode{
@@ -1754,93 +1728,19 @@ func formatResourceDeclaration(address, resourceType, changeType string) string
 	}
 }
 
-// Helper function to add resource attribute and diff nodes based on the change type
-func addResourceDiffNodes(parent *TreeNode, change map[string]interface{}, changeType ...string) {
-	before, hasBefore := change["before"]
-	after, hasAfter := change["after"]
-
-	// Determine the change type - either from parameter or parent node
-	var effectiveChangeType string
-	if len(changeType) > 0 {
-		effectiveChangeType = changeType[0]
-	} else if parent.ChangeType != "" {
-		effectiveChangeType = parent.ChangeType
-	} else {
-		// Default handling if no change type is provided
-		if hasBefore && hasAfter {
-			effectiveChangeType = "update"
-		} else if hasAfter {
-			effectiveChangeType = "create"
-		} else if hasBefore {
-			effectiveChangeType = "destroy"
-		}
-	}
-
-	// Process attributes based on change type
-	if effectiveChangeType == "create" && hasAfter {
+// addResourceDiffNodes adds attribute and diff nodes for a change, based on the parent's change type.
+func addResourceDiffNodes(parent *TreeNode, change planjson.Change) {
+	switch parent.ChangeType {
+	case "create":
 		// For creates, only show after values
-		addResourceAttributes(parent, after, "+", parent.Depth+1)
-	} else if effectiveChangeType == "destroy" && hasBefore {
+		addResourceAttributes(parent, change.After, "+", parent.Depth+1)
+	case "destroy":
 		// For destroys, only show before values
-		addResourceAttributes(parent, before, "-", parent.Depth+1)
-	} else if (effectiveChangeType == "update" || effectiveChangeType == "replace") && hasBefore && hasAfter {
+		addResourceAttributes(parent, change.Before, "-", parent.Depth+1)
+	case "update", "replace":
 		// For updates/replaces, compare before and after
-		processAttributeDiffs(parent, before, after, parent.Depth+1)
-	}
-}
-
-// Helper to map action strings to change type
-func mapActionsToChangeType(actions []string) string {
-	if sliceContains(actions, "create") && sliceContains(actions, "delete") {
-		return "replace"
-	} else if sliceContains(actions, "create") {
-		return "create"
-	} else if sliceContains(actions, "delete") {
-		return "destroy"
-	} else if sliceContains(actions, "update") {
-		return "update"
-	} else if len(actions) == 0 {
-		return "no-op"
-	}
-	return strings.Join(actions, "/")
-}
-
-// Helper to translate action reason to display string
-func getActionReasonDisplay(reason string) string {
-	switch reason {
-	case "replace_because_tainted":
-		return "tainted, so must be replaced"
-	case "replace_because_cannot_update":
-		return "cannot be updated in-place"
-	case "replace_by_request":
-		return "replacement requested"
-	case "delete_because_no_resource_config":
-		return "no resource configuration found"
-	case "delete_because_no_module":
-		return "containing module is gone"
-	case "delete_because_wrong_repetition":
-		return "wrong repetition mode"
-	case "delete_because_count_index":
-		return "count index out of range"
-	case "delete_because_each_key":
-		return "for_each key not found"
-	case "read_because_config_unknown":
-		return "configuration contains unknown values"
-	case "read_because_dependency_pending":
-		return "has pending dependent resources"
-	default:
-		return reason
-	}
-}
-
-// Helper to check if a slice contains a string
-func sliceContains(slice []string, str string) bool {
-	for _, s := range slice {
-		if s == str {
-			return true
-		}
+		processAttributeDiffs(parent, change.Before, change.After, parent.Depth+1)
 	}
-	return false
 }
 
 // Helper to get grammatically correct action text
@@ -0,0 +1,105 @@
+package plan
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"tfapp/internal/planjson"
+	"tfapp/internal/testutil"
+)
+
+// dumpTree renders every node regardless of expansion state, one per line.
+func dumpTree(sb *strings.Builder, nodes []*TreeNode, level int) {
+	for _, node := range nodes {
+		fmt.Fprintf(sb, "%s%s [%s/%s]\n", strings.Repeat("  ", level), node.Text, node.Type, node.ChangeType)
+		dumpTree(sb, node.Children, level+1)
+	}
+}
+
+func TestParsePlanGolden(t *testing.T) {
+	for _, name := range testutil.PlanFixtures(t) {
+		t.Run(name, func(t *testing.T) {
+			var sb strings.Builder
+			dumpTree(&sb, parsePlan(string(testutil.PlanFixture(t, name))), 0)
+			testutil.Golden(t, filepath.Join("tree", name), []byte(sb.String()))
+		})
+	}
+}
+
+func TestNewCollapsesResources(t *testing.T) {
+	model := New(string(testutil.PlanFixture(t, "create")))
+
+	visible := getVisibleNodes(model.nodes)
+	for _, node := range visible {
+		if node.Type != "resource" && node.Type != "summary" {
+			t.Errorf("node %q of type %q is visible in a freshly opened plan", node.Text, node.Type)
+		}
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	plan, err := planjson.Parse(testutil.PlanFixture(t, "create"))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	model := NewFromPlanAnnotated(plan, map[string][]Annotation{
+		"aws_security_group.web": {
+			{Level: "error", Badge: "✗ policy", Text: "✗ policy https-only: condition is false"},
+			{Level: "warning", Badge: "! policy", Text: "! policy owner-tag: condition is false"},
+		},
+	})
+
+	var node *TreeNode
+	for _, n := range model.nodes {
+		if n.Address == "aws_security_group.web" {
+			node = n
+		}
+	}
+	if node == nil {
+		t.Fatal("no resource node for aws_security_group.web")
+	}
+	if !strings.HasSuffix(node.Text, "  ✗ policy  ! policy") {
+		t.Errorf("resource line %q does not end with the badges", node.Text)
+	}
+	if len(node.Children) < 2 || node.Children[0].Type != "annotation" || node.Children[0].Level != "error" {
+		t.Fatalf("first child is not the error annotation: %+v", node.Children[0])
+	}
+	if node.Children[1].Text != "! policy owner-tag: condition is false" {
+		t.Errorf("second annotation = %q", node.Children[1].Text)
+	}
+}
+
+func TestAnnotateColumn(t *testing.T) {
+	plan, err := planjson.Parse(testutil.PlanFixture(t, "create"))
+	if err != nil {
+		t.Fatalf("parsing fixture: %v", err)
+	}
+
+	model := NewFromPlanAnnotated(plan, map[string][]Annotation{
+		"aws_instance.web":       {{Column: "+$7.59/mo"}},
+		"aws_security_group.web": {{Column: "? /mo"}},
+	})
+
+	columns := make(map[string]int)
+	for _, n := range model.nodes {
+		if n.Address == "" {
+			continue
+		}
+		for _, suffix := range []string{"+$7.59/mo", "? /mo"} {
+			if strings.HasSuffix(n.Text, suffix) {
+				columns[n.Address] = lipgloss.Width(strings.TrimSuffix(n.Text, suffix))
+			}
+		}
+	}
+	if len(columns) != 2 {
+		t.Fatalf("expected a cost column on both resources, got %v", columns)
+	}
+	if columns["aws_instance.web"] != columns["aws_security_group.web"] {
+		t.Errorf("cost columns are not aligned: %v", columns)
+	}
+}
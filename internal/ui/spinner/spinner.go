@@ -56,12 +56,12 @@ type model struct {
 	err      error
 	program  *tea.Program
 	done     chan struct{}
-	wg       sync.WaitGroup
 }
 
 // Spinner provides a terminal spinner with a message.
 type Spinner struct {
 	model *model
+	wg    sync.WaitGroup
 }
 
 // New creates a new bubbletea-based spinner.
@@ -125,7 +125,7 @@ func (m model) View() string {
 
 // Start begins the spinner animation.
 func (s *Spinner) Start() {
-	s.model.wg.Add(1)
+	s.wg.Add(1)
 	p := tea.NewProgram(s.model,
 		tea.WithoutCatchPanics(),
 		tea.WithMouseCellMotion(),
@@ -133,7 +133,7 @@ func (s *Spinner) Start() {
 	s.model.program = p
 
 	go func() {
-		defer s.model.wg.Done()
+		defer s.wg.Done()
 		if _, err := p.Run(); err != nil {
 			fmt.Printf("Error running spinner: %v\n", err)
 			os.Exit(1)
@@ -157,7 +157,7 @@ func (s *Spinner) Stop() {
 		// Wait for cleanup with timeout
 		cleanup := make(chan struct{})
 		go func() {
-			s.model.wg.Wait()
+			s.wg.Wait()
 			close(cleanup)
 		}()
 
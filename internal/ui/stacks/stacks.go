@@ -0,0 +1,539 @@
+// Package stacks provides the dashboard of a stacks session: the plan of every
+// root module at a glance, from which a plan can be opened in the plan viewer
+// and a selection of stacks applied.
+package stacks
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"tfapp/internal/ui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Status is the progress of a stack.
+type Status int
+
+// Stack statuses.
+const (
+	StatusPending Status = iota
+	StatusPlanning
+	StatusNoChanges
+	StatusChanges
+	StatusFailed // Planning failed
+	StatusApplying
+	StatusApplied
+	StatusApplyFailed
+	StatusSkipped // Not applied because a stack it depends on failed to apply
+)
+
+// Stack is a root module shown on the dashboard.
+type Stack struct {
+	Name      string
+	Workspace string
+	Status    Status
+	Changes   string   // Changes of its plan, e.g. "+2 ~1 -0"
+	Detail    []string // Shown while the stack is under the cursor: its changes or its error
+	DependsOn []string // Stacks applied before it, shown in the apply order
+}
+
+// Action is what the user chose to do from the dashboard.
+type Action int
+
+// Dashboard actions.
+const (
+	ActionQuit  Action = iota
+	ActionView         // Open the plan of the stack under the cursor
+	ActionApply        // Apply the selected stacks
+)
+
+// Choice is the outcome of the dashboard.
+type Choice struct {
+	Action   Action
+	Cursor   int    // Index of the stack under the cursor
+	Selected []bool // Stacks selected for apply, by index
+}
+
+// maxDetail is the number of detail lines shown for the stack under the cursor.
+const maxDetail = 8
+
+// updateMsg carries the new state of a stack.
+type updateMsg struct {
+	index int
+	stack Stack
+}
+
+// doneMsg is sent once every stack has been planned.
+type doneMsg struct{}
+
+// tickMsg refreshes the elapsed time while planning.
+type tickMsg time.Time
+
+// model represents the dashboard state.
+type model struct {
+	title       string
+	stacks      []Stack
+	cursor      int
+	selected    []bool
+	planning    bool
+	started     time.Time
+	now         time.Time
+	height      int
+	message     string // Feedback on the last key pressed
+	action      Action
+	interrupted bool
+	interrupt   func()
+}
+
+var (
+	successStyle = lipgloss.NewStyle()
+	errorStyle   = lipgloss.NewStyle()
+	activeStyle  = lipgloss.NewStyle()
+	faintStyle   = lipgloss.NewStyle()
+	titleStyle   = lipgloss.NewStyle().Bold(true)
+)
+
+// updateStyles applies the configured colors.
+func updateStyles() {
+	successStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("success")))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("error")))
+	activeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("highlight")))
+	faintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(ui.GetHexColorByName("faint")))
+}
+
+// newModel creates a dashboard for the given stacks, restoring the cursor and
+// selection of a previous choice.
+func newModel(title string, stacks []Stack, last Choice, interrupt func()) model {
+	now := time.Now()
+	m := model{
+		title:     title,
+		stacks:    append([]Stack(nil), stacks...),
+		cursor:    min(max(last.Cursor, 0), max(len(stacks)-1, 0)),
+		selected:  make([]bool, len(stacks)),
+		started:   now,
+		now:       now,
+		height:    25, // Default height, adjusted when we receive WindowSizeMsg
+		interrupt: interrupt,
+	}
+	for i := range m.selected {
+		m.selected[i] = i < len(last.Selected) && last.Selected[i] && stacks[i].Status == StatusChanges
+	}
+	return m
+}
+
+// Init implements tea.Model.
+func (m model) Init() tea.Cmd {
+	if m.planning {
+		return tick()
+	}
+	return nil
+}
+
+// tick schedules the next refresh of the elapsed time.
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update implements tea.Model.
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height
+	case tickMsg:
+		m.now = time.Time(msg)
+		if m.planning {
+			return m, tick()
+		}
+	case updateMsg:
+		if msg.index >= 0 && msg.index < len(m.stacks) {
+			m.stacks[msg.index] = msg.stack
+		}
+	case doneMsg:
+		m.now = time.Now()
+		m.planning = false
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// handleKey moves the cursor, selects stacks and chooses an action.
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.message = ""
+	key := msg.String()
+
+	switch key {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		return m, nil
+	case "down", "j":
+		if m.cursor < len(m.stacks)-1 {
+			m.cursor++
+		}
+		return m, nil
+	case "home", "g":
+		m.cursor = 0
+		return m, nil
+	case "end", "G":
+		m.cursor = max(len(m.stacks)-1, 0)
+		return m, nil
+	}
+
+	if m.planning {
+		if key == "ctrl+c" && !m.interrupted {
+			// Let terraform stop gracefully; the dashboard stays until every plan has returned
+			m.interrupted = true
+			if m.interrupt != nil {
+				m.interrupt()
+			}
+		}
+		return m, nil
+	}
+
+	switch key {
+	case " ":
+		if len(m.stacks) == 0 {
+			return m, nil
+		}
+		if m.stacks[m.cursor].Status != StatusChanges {
+			m.message = "Only stacks with changes can be applied."
+			return m, nil
+		}
+		m.selected[m.cursor] = !m.selected[m.cursor]
+	case "t":
+		// Select every stack with changes, or none if they all are
+		all := true
+		for i, stack := range m.stacks {
+			if stack.Status == StatusChanges && !m.selected[i] {
+				all = false
+			}
+		}
+		for i, stack := range m.stacks {
+			m.selected[i] = !all && stack.Status == StatusChanges
+		}
+	case "enter":
+		if len(m.stacks) == 0 {
+			return m, nil
+		}
+		if m.stacks[m.cursor].Status != StatusChanges {
+			m.message = "Only the plans of stacks with changes can be viewed."
+			return m, nil
+		}
+		m.action = ActionView
+		return m, tea.Quit
+	case "a":
+		if m.count() == 0 {
+			m.message = "Select the stacks to apply with space first."
+			return m, nil
+		}
+		m.action = ActionApply
+		return m, tea.Quit
+	case "q", "esc", "ctrl+c":
+		m.action = ActionQuit
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// count returns the number of selected stacks.
+func (m model) count() int {
+	count := 0
+	for _, selected := range m.selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+// View implements tea.Model.
+func (m model) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render(m.title) + "  ")
+	planned := 0
+	for _, stack := range m.stacks {
+		if stack.Status != StatusPending && stack.Status != StatusPlanning {
+			planned++
+		}
+	}
+	if m.planning {
+		sb.WriteString(progressBar(planned, len(m.stacks), 30))
+		sb.WriteString(fmt.Sprintf("  %d/%d planned  %s", planned, len(m.stacks), formatDuration(m.now.Sub(m.started))))
+	} else {
+		sb.WriteString(Totals(m.stacks))
+	}
+	sb.WriteString("\n\n")
+
+	width := nameWidth(m.stacks)
+	var detail []string
+	if len(m.stacks) > 0 {
+		detail = m.stacks[m.cursor].Detail
+		if len(detail) > maxDetail {
+			detail = append(detail[:maxDetail:maxDetail], fmt.Sprintf("… %d more", len(detail)-maxDetail))
+		}
+	}
+
+	first, last := m.visibleRange(len(detail))
+	if first > 0 {
+		sb.WriteString(faintStyle.Render(fmt.Sprintf("    … %d above", first)) + "\n")
+	}
+	for i := first; i < last; i++ {
+		cursor := " "
+		if i == m.cursor {
+			cursor = activeStyle.Render(ui.GetCursorChar())
+		}
+		box := "   "
+		if m.stacks[i].Status == StatusChanges {
+			box = "[ ]"
+			if m.selected[i] {
+				box = "[x]"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%s %s %s\n", cursor, box, formatRow(m.stacks[i], width)))
+	}
+	if last < len(m.stacks) {
+		sb.WriteString(faintStyle.Render(fmt.Sprintf("    … %d below", len(m.stacks)-last)) + "\n")
+	}
+
+	if len(detail) > 0 {
+		sb.WriteString("\n")
+		for _, line := range detail {
+			sb.WriteString("  " + faintStyle.Render(line) + "\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	switch {
+	case m.message != "":
+		sb.WriteString(activeStyle.Render(m.message) + "\n")
+	case m.interrupted && m.planning:
+		sb.WriteString(faintStyle.Render("Interrupting, waiting for terraform to stop...") + "\n")
+	case m.planning:
+		sb.WriteString(faintStyle.Render("↑/↓: move • ctrl+c: interrupt") + "\n")
+	default:
+		sb.WriteString(faintStyle.Render(fmt.Sprintf("↑/↓: move • space: select • t: select all • enter: view plan • a: apply %d selected • q: quit", m.count())) + "\n")
+	}
+	return sb.String()
+}
+
+// visibleRange returns the stacks that fit on the screen around the cursor.
+func (m model) visibleRange(detailLines int) (first, last int) {
+	room := max(m.height-8-detailLines, 3) // Title, scroll notes, detail and help
+	if len(m.stacks) <= room {
+		return 0, len(m.stacks)
+	}
+	first = min(max(m.cursor-room/2, 0), len(m.stacks)-room)
+	return first, first + room
+}
+
+// Write writes the status of every stack, as a table for the terminal.
+func Write(w io.Writer, stacks []Stack) {
+	updateStyles()
+	width := nameWidth(stacks)
+	for _, stack := range stacks {
+		fmt.Fprintf(w, "  %s\n", formatRow(stack, width))
+	}
+	fmt.Fprintf(w, "\n%s\n", Totals(stacks))
+}
+
+// WriteWaves writes the order in which stacks are applied: the stacks of a
+// wave are applied once every stack of the waves before it is.
+func WriteWaves(w io.Writer, waves [][]Stack) {
+	updateStyles()
+	var all []Stack
+	for _, wave := range waves {
+		all = append(all, wave...)
+	}
+	width := nameWidth(all)
+	for i, wave := range waves {
+		fmt.Fprintf(w, "Wave %d:\n", i+1)
+		for _, stack := range wave {
+			line := "  " + formatRow(stack, width)
+			if len(stack.DependsOn) > 0 {
+				line += faintStyle.Render("  after " + strings.Join(stack.DependsOn, ", "))
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// Totals summarizes the statuses of the stacks, e.g. "5 stacks: 2 with changes, 1 failed".
+func Totals(stacks []Stack) string {
+	counts := make(map[Status]int)
+	for _, stack := range stacks {
+		counts[stack.Status]++
+	}
+
+	var parts []string
+	for _, status := range []Status{StatusChanges, StatusNoChanges, StatusFailed, StatusApplied, StatusApplyFailed, StatusSkipped, StatusPending} {
+		if counts[status] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[status], statusNoun(status)))
+		}
+	}
+	total := fmt.Sprintf("%d stacks", len(stacks))
+	if len(stacks) == 1 {
+		total = "1 stack"
+	}
+	if len(parts) == 0 {
+		return total
+	}
+	return total + ": " + strings.Join(parts, ", ")
+}
+
+// formatRow formats the line of a stack: its status, name, workspace and changes.
+func formatRow(stack Stack, width int) string {
+	line := fmt.Sprintf("%s %-*s  %-12s  %s", statusSymbol(stack.Status), width, stack.Name, stack.Workspace, statusText(stack))
+	return styleFor(stack.Status).Render(line)
+}
+
+// nameWidth returns the width of the name column.
+func nameWidth(stacks []Stack) int {
+	width := 0
+	for _, stack := range stacks {
+		width = max(width, len(stack.Name))
+	}
+	return width
+}
+
+// statusText describes the status of a stack in its row.
+func statusText(stack Stack) string {
+	switch stack.Status {
+	case StatusPlanning:
+		return "planning..."
+	case StatusNoChanges:
+		return "no changes"
+	case StatusChanges:
+		return stack.Changes
+	case StatusFailed:
+		return "plan failed"
+	case StatusApplying:
+		return "applying..."
+	case StatusApplied:
+		return "applied " + stack.Changes
+	case StatusApplyFailed:
+		return "apply failed"
+	case StatusSkipped:
+		return "skipped"
+	}
+	return "pending"
+}
+
+// statusNoun names the stacks with a status in the totals.
+func statusNoun(status Status) string {
+	switch status {
+	case StatusChanges:
+		return "with changes"
+	case StatusNoChanges:
+		return "without changes"
+	case StatusFailed:
+		return "failed to plan"
+	case StatusApplied:
+		return "applied"
+	case StatusApplyFailed:
+		return "failed to apply"
+	case StatusSkipped:
+		return "skipped"
+	}
+	return "not planned"
+}
+
+// statusSymbol returns the marker of a status.
+func statusSymbol(status Status) string {
+	switch status {
+	case StatusPlanning, StatusApplying:
+		return "◐"
+	case StatusNoChanges:
+		return "="
+	case StatusChanges:
+		return "~"
+	case StatusApplied:
+		return "✓"
+	case StatusFailed, StatusApplyFailed:
+		return "✗"
+	case StatusSkipped:
+		return "-"
+	}
+	return "·"
+}
+
+// styleFor returns the style of a stack row.
+func styleFor(status Status) lipgloss.Style {
+	switch status {
+	case StatusPlanning, StatusApplying:
+		return activeStyle
+	case StatusApplied:
+		return successStyle
+	case StatusFailed, StatusApplyFailed:
+		return errorStyle
+	case StatusChanges:
+		return lipgloss.NewStyle()
+	}
+	return faintStyle
+}
+
+// progressBar renders the share of planned stacks.
+func progressBar(finished, total, width int) string {
+	filled := width
+	if total > 0 {
+		filled = finished * width / total
+	}
+	return activeStyle.Render(strings.Repeat("█", filled)) + faintStyle.Render(strings.Repeat("░", width-filled))
+}
+
+// formatDuration formats an elapsed time, e.g. "1m12s".
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// Show shows the dashboard and returns what the user chose to do. last restores
+// the cursor and selection of a previous choice. With plan set, the dashboard
+// first follows the planning of the stacks: plan reports the new state of each
+// stack through update, and Ctrl+C cancels the context passed to plan. Nothing
+// can be chosen before plan has returned.
+func Show(ctx context.Context, title string, stacks []Stack, last Choice, plan func(ctx context.Context, update func(i int, stack Stack))) (Choice, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updateStyles()
+	m := newModel(title, stacks, last, cancel)
+	m.planning = plan != nil
+	p := tea.NewProgram(m, tea.WithAltScreen())
+
+	planned := make(chan struct{})
+	if plan != nil {
+		go func() {
+			defer close(planned)
+			plan(ctx, func(i int, stack Stack) { p.Send(updateMsg{i, stack}) })
+			p.Send(doneMsg{})
+		}()
+	} else {
+		close(planned)
+	}
+
+	final, err := p.Run()
+	cancel()
+	<-planned
+	if err != nil {
+		return Choice{}, fmt.Errorf("error running stacks dashboard: %w", err)
+	}
+
+	result, ok := final.(model)
+	if !ok {
+		return Choice{}, fmt.Errorf("could not get the dashboard choice")
+	}
+	return Choice{Action: result.action, Cursor: result.cursor, Selected: result.selected}, nil
+}
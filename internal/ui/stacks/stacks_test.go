@@ -0,0 +1,200 @@
+package stacks
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	keyDown  = tea.KeyMsg{Type: tea.KeyDown}
+	keySpace = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	keyEnter = tea.KeyMsg{Type: tea.KeyEnter}
+	keyCtrlC = tea.KeyMsg{Type: tea.KeyCtrlC}
+)
+
+func keyRune(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+// press passes messages to the model and returns it with the last command.
+func press(m model, msgs ...tea.Msg) (model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		var updated tea.Model
+		updated, cmd = m.Update(msg)
+		m = updated.(model)
+	}
+	return m, cmd
+}
+
+func testStacks() []Stack {
+	return []Stack{
+		{Name: "envs/dev", Workspace: "default"},
+		{Name: "envs/prod", Workspace: "prod"},
+		{Name: "global/iam", Workspace: "default"},
+	}
+}
+
+func TestDashboardFollowsPlanning(t *testing.T) {
+	interrupted := false
+	m := newModel("Stacks", testStacks(), Choice{}, func() { interrupted = true })
+	m.planning = true
+
+	m, _ = press(m,
+		updateMsg{0, Stack{Name: "envs/dev", Workspace: "default", Status: StatusChanges, Changes: "+2 ~0 -0"}},
+		updateMsg{1, Stack{Name: "envs/prod", Workspace: "prod", Status: StatusPlanning}},
+	)
+	view := m.View()
+	for _, want := range []string{"1/3 planned", "envs/dev", "+2 ~0 -0", "planning...", "pending", "ctrl+c: interrupt"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q:\n%s", want, view)
+		}
+	}
+
+	// Nothing can be chosen before every stack is planned
+	m, cmd := press(m, keySpace, keyRune('a'), keyRune('q'))
+	if cmd != nil || m.count() != 0 {
+		t.Error("a key other than ctrl+c was handled while planning")
+	}
+	m, _ = press(m, keyCtrlC)
+	if !interrupted || !strings.Contains(m.View(), "Interrupting") {
+		t.Error("ctrl+c did not interrupt the planning")
+	}
+
+	m, _ = press(m,
+		updateMsg{1, Stack{Name: "envs/prod", Workspace: "prod", Status: StatusFailed, Detail: []string{"Error: interrupted"}}},
+		updateMsg{2, Stack{Name: "global/iam", Workspace: "default", Status: StatusNoChanges}},
+		doneMsg{},
+	)
+	view = m.View()
+	if !strings.Contains(view, "3 stacks: 1 with changes, 1 without changes, 1 failed to plan") {
+		t.Errorf("view does not show the totals:\n%s", view)
+	}
+}
+
+func TestDashboardSelectsAndApplies(t *testing.T) {
+	stacks := testStacks()
+	stacks[0].Status, stacks[0].Changes = StatusChanges, "+1 ~0 -0"
+	stacks[1].Status, stacks[1].Changes = StatusChanges, "+0 ~1 -1"
+	stacks[2].Status = StatusNoChanges
+	m := newModel("Stacks", stacks, Choice{}, nil)
+
+	// Applying needs a selection
+	m, cmd := press(m, keyRune('a'))
+	if cmd != nil || !strings.Contains(m.View(), "Select the stacks to apply") {
+		t.Error("apply without a selection was not refused")
+	}
+
+	// Stacks without changes cannot be selected
+	m, _ = press(m, keyDown, keyDown, keySpace)
+	if m.selected[2] || !strings.Contains(m.View(), "Only stacks with changes") {
+		t.Error("a stack without changes was selected")
+	}
+
+	m, _ = press(m, keyRune('t'))
+	if !m.selected[0] || !m.selected[1] || m.selected[2] {
+		t.Errorf("t selected %v, want the stacks with changes", m.selected)
+	}
+	m, _ = press(m, keyRune('k'), keySpace)
+	if m.selected[1] {
+		t.Error("space did not deselect the stack under the cursor")
+	}
+
+	m, cmd = press(m, keyRune('a'))
+	if cmd == nil || m.action != ActionApply {
+		t.Fatal("a did not choose to apply")
+	}
+	if !m.selected[0] || m.selected[1] {
+		t.Errorf("selection = %v, want envs/dev only", m.selected)
+	}
+}
+
+func TestDashboardViewsPlan(t *testing.T) {
+	stacks := testStacks()
+	stacks[1].Status, stacks[1].Changes = StatusChanges, "+1 ~0 -0"
+
+	// The cursor and selection of the last choice are restored
+	m := newModel("Stacks", stacks, Choice{Cursor: 1, Selected: []bool{true, true, false}}, nil)
+	if m.cursor != 1 || m.selected[0] || !m.selected[1] {
+		t.Errorf("restored cursor %d and selection %v", m.cursor, m.selected)
+	}
+
+	m, cmd := press(m, keyEnter)
+	if cmd == nil || m.action != ActionView {
+		t.Error("enter did not choose to view the plan")
+	}
+
+	m = newModel("Stacks", stacks, Choice{}, nil)
+	m, cmd = press(m, keyEnter)
+	if cmd != nil || !strings.Contains(m.View(), "Only the plans of stacks with changes") {
+		t.Error("enter on a stack without a plan was not refused")
+	}
+}
+
+func TestDashboardShowsDetailOfCursor(t *testing.T) {
+	stacks := testStacks()
+	stacks[1].Status = StatusFailed
+	for i := 0; i < 12; i++ {
+		stacks[1].Detail = append(stacks[1].Detail, "line")
+	}
+	m := newModel("Stacks", stacks, Choice{}, nil)
+
+	if strings.Contains(m.View(), "line") {
+		t.Error("detail shown for a stack not under the cursor")
+	}
+	m, _ = press(m, keyDown)
+	view := m.View()
+	if strings.Count(view, "line") != maxDetail || !strings.Contains(view, "… 4 more") {
+		t.Errorf("detail not truncated to %d lines:\n%s", maxDetail, view)
+	}
+}
+
+func TestDashboardScrolls(t *testing.T) {
+	var stacks []Stack
+	for i := 0; i < 40; i++ {
+		stacks = append(stacks, Stack{Name: string(rune('a'+i%26)) + "-stack", Status: StatusNoChanges})
+	}
+	m := newModel("Stacks", stacks, Choice{Cursor: 20}, nil)
+	m, _ = press(m, tea.WindowSizeMsg{Height: 20})
+
+	first, last := m.visibleRange(0)
+	if first > 20 || last <= 20 || last-first != 12 {
+		t.Errorf("visible range = [%d, %d), want 12 rows around the cursor", first, last)
+	}
+	view := m.View()
+	if !strings.Contains(view, "above") || !strings.Contains(view, "below") {
+		t.Errorf("view does not note the stacks scrolled out:\n%s", view)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	stacks := testStacks()
+	stacks[0].Status, stacks[0].Changes = StatusApplied, "+1 ~0 -0"
+	stacks[1].Status = StatusApplyFailed
+	stacks[2].Status = StatusNoChanges
+
+	var out bytes.Buffer
+	Write(&out, stacks)
+	for _, want := range []string{"✓ envs/dev", "applied +1 ~0 -0", "✗ envs/prod", "apply failed", "= global/iam", "3 stacks: 1 without changes, 1 applied, 1 failed to apply"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteWaves(t *testing.T) {
+	stacks := testStacks()
+	stacks[0].Status, stacks[0].Changes = StatusChanges, "+1 ~0 -0"
+	stacks[1].Status, stacks[1].Changes, stacks[1].DependsOn = StatusChanges, "+0 ~1 -0", []string{"envs/dev"}
+
+	var out bytes.Buffer
+	WriteWaves(&out, [][]Stack{{stacks[0]}, {stacks[1]}})
+	for _, want := range []string{"Wave 1:\n  ~ envs/dev", "Wave 2:\n  ~ envs/prod", "after envs/dev"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package uijson
+
+import (
+	"bufio"
+	"io"
+	"time"
+)
+
+// Event is a typed message of the machine-readable UI output, one of
+// *PlannedChangeEvent, *ApplyStartEvent, *ApplyProgressEvent,
+// *ApplyCompleteEvent, *ApplyErroredEvent, *DiagnosticEvent and
+// *ChangeSummaryEvent.
+type Event interface {
+	// Text returns the human-readable form of the event, as terraform prints it without -json.
+	Text() string
+}
+
+// Envelope holds the fields common to every event.
+type Envelope struct {
+	Level     string
+	Message   string
+	Timestamp time.Time
+}
+
+// Text implements Event.
+func (e Envelope) Text() string {
+	return e.Message
+}
+
+// PlannedChangeEvent reports a change of the plan being made.
+type PlannedChangeEvent struct {
+	Envelope
+	Change
+}
+
+// ApplyStartEvent reports that terraform started applying a change to a resource.
+type ApplyStartEvent struct {
+	Envelope
+	Hook
+}
+
+// ApplyProgressEvent reports that a change is still being applied.
+type ApplyProgressEvent struct {
+	Envelope
+	Hook
+}
+
+// ApplyCompleteEvent reports that a change was applied.
+type ApplyCompleteEvent struct {
+	Envelope
+	Hook
+}
+
+// ApplyErroredEvent reports that a change failed to apply.
+type ApplyErroredEvent struct {
+	Envelope
+	Hook
+}
+
+// DiagnosticEvent reports a warning or error.
+type DiagnosticEvent struct {
+	Envelope
+	Diagnostic
+}
+
+// ChangeSummaryEvent counts the changes once a plan, apply or destroy is done.
+type ChangeSummaryEvent struct {
+	Envelope
+	ChangeSummary
+}
+
+// Event converts a message to its typed event. It returns nil for the
+// message types tfapp does not use, such as version and log messages, and for
+// messages missing the body of their type.
+func (m Message) Event() Event {
+	envelope := Envelope{Level: m.Level, Message: m.Message, Timestamp: m.Timestamp}
+
+	switch m.Type {
+	case TypePlannedChange:
+		if m.Change != nil {
+			return &PlannedChangeEvent{Envelope: envelope, Change: *m.Change}
+		}
+	case TypeApplyStart, TypeApplyProgress, TypeApplyComplete, TypeApplyErrored:
+		if m.Hook == nil {
+			return nil
+		}
+		switch m.Type {
+		case TypeApplyStart:
+			return &ApplyStartEvent{Envelope: envelope, Hook: *m.Hook}
+		case TypeApplyProgress:
+			return &ApplyProgressEvent{Envelope: envelope, Hook: *m.Hook}
+		case TypeApplyComplete:
+			return &ApplyCompleteEvent{Envelope: envelope, Hook: *m.Hook}
+		default:
+			return &ApplyErroredEvent{Envelope: envelope, Hook: *m.Hook}
+		}
+	case TypeDiagnostic:
+		if m.Diagnostic != nil {
+			return &DiagnosticEvent{Envelope: envelope, Diagnostic: *m.Diagnostic}
+		}
+	case TypeChangeSummary:
+		if m.Changes != nil {
+			return &ChangeSummaryEvent{Envelope: envelope, ChangeSummary: *m.Changes}
+		}
+	}
+	return nil
+}
+
+// Publish reads machine-readable UI output from r until it ends and sends the
+// typed event of every message to events, in order. Lines that are not UI
+// messages, such as a provider crash log, are skipped. Publish does not close
+// events.
+func Publish(r io.Reader, events chan<- Event) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024) // Messages can embed large values
+	for scanner.Scan() {
+		msg, err := Decode(scanner.Bytes())
+		if err != nil {
+			continue
+		}
+		if event := msg.Event(); event != nil {
+			events <- event
+		}
+	}
+	return scanner.Err()
+}
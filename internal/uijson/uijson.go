@@ -0,0 +1,149 @@
+// Package uijson decodes Terraform's machine-readable UI output, the stream of
+// JSON messages, one per line, that commands such as apply print with -json,
+// and the diagnostics printed by `terraform validate -json`.
+//
+// Only the fields tfapp uses are decoded. See
+// https://developer.hashicorp.com/terraform/internals/machine-readable-ui
+package uijson
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Message types.
+const (
+	TypeVersion       = "version"
+	TypeLog           = "log"
+	TypeDiagnostic    = "diagnostic"
+	TypePlannedChange = "planned_change"
+	TypeChangeSummary = "change_summary"
+	TypeOutputs       = "outputs"
+	TypeApplyStart    = "apply_start"
+	TypeApplyProgress = "apply_progress"
+	TypeApplyComplete = "apply_complete"
+	TypeApplyErrored  = "apply_errored"
+)
+
+// Message is one line of the machine-readable UI output.
+type Message struct {
+	Level     string    `json:"@level"`
+	Message   string    `json:"@message"`
+	Module    string    `json:"@module"`
+	Timestamp time.Time `json:"@timestamp"`
+	Type      string    `json:"type"`
+
+	// Set depending on the message type
+	Hook       *Hook          `json:"hook,omitempty"`       // apply_*
+	Change     *Change        `json:"change,omitempty"`     // planned_change
+	Changes    *ChangeSummary `json:"changes,omitempty"`    // change_summary
+	Diagnostic *Diagnostic    `json:"diagnostic,omitempty"` // diagnostic
+}
+
+// Resource identifies the resource instance a message is about.
+type Resource struct {
+	Addr         string `json:"addr"`
+	Module       string `json:"module"`
+	ResourceType string `json:"resource_type"`
+	ResourceName string `json:"resource_name"`
+}
+
+// Hook reports the progress of an operation on a resource during apply.
+type Hook struct {
+	Resource       Resource `json:"resource"`
+	Action         string   `json:"action"` // create, update, delete, read, ...
+	IDKey          string   `json:"id_key,omitempty"`
+	IDValue        string   `json:"id_value,omitempty"`
+	ElapsedSeconds float64  `json:"elapsed_seconds,omitempty"`
+}
+
+// Change is a change planned for a resource.
+type Change struct {
+	Resource         Resource  `json:"resource"`
+	PreviousResource *Resource `json:"previous_resource,omitempty"`
+	Action           string    `json:"action"` // noop, create, read, update, replace, delete, move, import, remove
+	Reason           string    `json:"reason,omitempty"`
+}
+
+// ChangeSummary counts the changes of a plan, apply or destroy.
+type ChangeSummary struct {
+	Add       int    `json:"add"`
+	Change    int    `json:"change"`
+	Import    int    `json:"import"`
+	Remove    int    `json:"remove"`
+	Operation string `json:"operation"` // plan, apply or destroy
+}
+
+// Diagnostic is a warning or error reported by Terraform.
+type Diagnostic struct {
+	Severity string   `json:"severity"` // error or warning
+	Summary  string   `json:"summary"`
+	Detail   string   `json:"detail"`
+	Address  string   `json:"address,omitempty"`
+	Range    *Range   `json:"range,omitempty"`   // Source the diagnostic is about, if any
+	Snippet  *Snippet `json:"snippet,omitempty"` // Source code of the range, if the file could be read
+}
+
+// Range is a span of a configuration file.
+type Range struct {
+	Filename string `json:"filename"`
+	Start    Pos    `json:"start"`
+	End      Pos    `json:"end"`
+}
+
+// Pos is a position in a configuration file. Line and Column start at 1, Byte at 0.
+type Pos struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Byte   int `json:"byte"`
+}
+
+// Snippet is the source code a diagnostic is about.
+type Snippet struct {
+	Context   *string `json:"context"` // Enclosing block, e.g. resource "aws_instance" "web"
+	Code      string  `json:"code"`    // The lines of the range
+	StartLine int     `json:"start_line"`
+
+	// Byte offsets of the range within Code
+	HighlightStartOffset int `json:"highlight_start_offset"`
+	HighlightEndOffset   int `json:"highlight_end_offset"`
+
+	Values []ExpressionValue `json:"values"`
+}
+
+// ExpressionValue describes the value of a reference in the snippet, e.g. `var.region is "eu-west-1"`.
+type ExpressionValue struct {
+	Traversal string `json:"traversal"`
+	Statement string `json:"statement"`
+}
+
+// ValidateResult is the output of `terraform validate -json`.
+type ValidateResult struct {
+	FormatVersion string       `json:"format_version"`
+	Valid         bool         `json:"valid"`
+	ErrorCount    int          `json:"error_count"`
+	WarningCount  int          `json:"warning_count"`
+	Diagnostics   []Diagnostic `json:"diagnostics"`
+}
+
+// ParseValidate parses the output of `terraform validate -json`.
+func ParseValidate(data []byte) (*ValidateResult, error) {
+	var result ValidateResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("error parsing terraform validate output: %w", err)
+	}
+	return &result, nil
+}
+
+// Decode parses one line of machine-readable UI output.
+func Decode(line []byte) (Message, error) {
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return Message{}, fmt.Errorf("error parsing terraform UI message: %w", err)
+	}
+	if msg.Type == "" {
+		return Message{}, fmt.Errorf("error parsing terraform UI message: no message type")
+	}
+	return msg, nil
+}
@@ -0,0 +1,161 @@
+package uijson
+
+import (
+	"bytes"
+	"testing"
+
+	"tfapp/internal/testutil"
+)
+
+func TestDecodeFixture(t *testing.T) {
+	var types []string
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, "apply_errored")), []byte("\n")) {
+		msg, err := Decode(line)
+		if err != nil {
+			t.Fatalf("Decode(%s) error = %v", line, err)
+		}
+		types = append(types, msg.Type)
+
+		switch msg.Type {
+		case TypeApplyStart, TypeApplyProgress, TypeApplyComplete, TypeApplyErrored:
+			if msg.Hook == nil || msg.Hook.Resource.Addr == "" || msg.Hook.Action == "" {
+				t.Errorf("%s message without a hook: %s", msg.Type, line)
+			}
+		case TypeDiagnostic:
+			if msg.Diagnostic == nil || msg.Diagnostic.Severity != "error" || msg.Diagnostic.Address != "aws_db_instance.main" {
+				t.Errorf("unexpected diagnostic: %+v", msg.Diagnostic)
+			}
+		}
+	}
+
+	if types[0] != TypeVersion || types[len(types)-1] != TypeDiagnostic {
+		t.Errorf("unexpected message types: %v", types)
+	}
+}
+
+func TestDecodeHook(t *testing.T) {
+	msg, err := Decode([]byte(`{"@level":"info","@message":"a: Still creating... [10s elapsed]","type":"apply_progress","hook":{"resource":{"addr":"aws_instance.a","resource_type":"aws_instance","resource_name":"a"},"action":"create","elapsed_seconds":10}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Hook.ElapsedSeconds != 10 || msg.Hook.Resource.ResourceType != "aws_instance" {
+		t.Errorf("unexpected hook: %+v", msg.Hook)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, line := range []string{
+		"Error: not JSON",
+		`{"@level":"info","@message":"no type"}`,
+	} {
+		if _, err := Decode([]byte(line)); err == nil {
+			t.Errorf("Decode(%q) succeeded, want an error", line)
+		}
+	}
+}
+
+func TestMessageEvent(t *testing.T) {
+	var events []Event
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, "plan")), []byte("\n")) {
+		msg, err := Decode(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event := msg.Event(); event != nil {
+			events = append(events, event)
+		}
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3 (the version message has none)", len(events))
+	}
+	change, ok := events[0].(*PlannedChangeEvent)
+	if !ok || change.Resource.Addr != "aws_instance.web" || change.Action != "create" {
+		t.Errorf("first event = %#v, want the planned creation", events[0])
+	}
+	summary, ok := events[2].(*ChangeSummaryEvent)
+	if !ok || summary.Add != 2 || summary.Operation != "plan" || summary.Text() != "Plan: 2 to add, 0 to change, 0 to destroy." {
+		t.Errorf("last event = %#v, want the plan summary", events[2])
+	}
+}
+
+func TestMessageEventWithoutBody(t *testing.T) {
+	if event := (Message{Type: TypeApplyStart}).Event(); event != nil {
+		t.Errorf("apply_start without a hook converted to %#v", event)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	events := make(chan Event, 32)
+	input := append(testutil.UIFixture(t, "apply_errored"), "panic: provider crashed\n"...)
+	if err := Publish(bytes.NewReader(input), events); err != nil {
+		t.Fatalf("Publish error = %v", err)
+	}
+	close(events)
+
+	count := 0
+	for range events {
+		count++
+	}
+	if count != 14 {
+		t.Errorf("published %d events, want 14", count)
+	}
+}
+
+func TestDecodeDiagnosticSource(t *testing.T) {
+	var diagnostic *Diagnostic
+	for _, line := range bytes.Split(bytes.TrimSpace(testutil.UIFixture(t, "plan_errored")), []byte("\n")) {
+		msg, err := Decode(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Diagnostic != nil && msg.Diagnostic.Severity == "error" {
+			diagnostic = msg.Diagnostic
+		}
+	}
+
+	if diagnostic == nil || diagnostic.Range == nil || diagnostic.Snippet == nil {
+		t.Fatalf("diagnostic without range or snippet: %+v", diagnostic)
+	}
+	if r := diagnostic.Range; r.Filename != "main.tf" || r.Start.Line != 2 || r.Start.Column != 12 || r.End.Column != 22 {
+		t.Errorf("unexpected range: %+v", r)
+	}
+	snippet := diagnostic.Snippet
+	if snippet.Context == nil || *snippet.Context != `provider "aws"` {
+		t.Errorf("unexpected snippet context: %v", snippet.Context)
+	}
+	if got := snippet.Code[snippet.HighlightStartOffset:snippet.HighlightEndOffset]; got != "var.region" {
+		t.Errorf("highlight = %q, want var.region", got)
+	}
+}
+
+func TestParseValidate(t *testing.T) {
+	result, err := ParseValidate([]byte(`{
+  "format_version": "1.0",
+  "valid": false,
+  "error_count": 1,
+  "warning_count": 0,
+  "diagnostics": [
+    {
+      "severity": "error",
+      "summary": "Unsupported argument",
+      "detail": "An argument named \"instance_typ\" is not expected here.",
+      "range": {"filename": "main.tf", "start": {"line": 7, "column": 3, "byte": 120}, "end": {"line": 7, "column": 15, "byte": 132}},
+      "snippet": {"context": "resource \"aws_instance\" \"web\"", "code": "  instance_typ = \"t3.micro\"", "start_line": 7, "highlight_start_offset": 2, "highlight_end_offset": 14, "values": []}
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Valid || result.ErrorCount != 1 || len(result.Diagnostics) != 1 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if d := result.Diagnostics[0]; d.Range == nil || d.Range.Start.Line != 7 || d.Snippet == nil || d.Snippet.StartLine != 7 {
+		t.Errorf("unexpected diagnostic: %+v", d)
+	}
+
+	if _, err := ParseValidate([]byte("Error: not JSON")); err == nil {
+		t.Error("ParseValidate accepted invalid output")
+	}
+}
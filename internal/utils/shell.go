@@ -0,0 +1,42 @@
+package utils
+
+import "strings"
+
+// ShellQuote quotes a command-line argument so a POSIX shell reads it verbatim.
+// Arguments made only of safe characters are returned unchanged.
+func ShellQuote(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+
+	safe := true
+	for _, c := range arg {
+		if !isShellSafe(c) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// ShellJoin quotes each argument with ShellQuote and joins them with spaces.
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// isShellSafe reports whether c never needs quoting in a POSIX shell.
+func isShellSafe(c rune) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.ContainsRune("_-.,/:=+@%", c)
+}
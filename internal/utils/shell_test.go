@@ -0,0 +1,22 @@
+package utils
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{"-target=aws_instance.web", "-target=aws_instance.web"},
+		{"-target=aws_instance.web[0]", "'-target=aws_instance.web[0]'"},
+		{`-target=module.app["blue"]`, `'-target=module.app["blue"]'`},
+		{`-target=aws_instance.web["it's"]`, `'-target=aws_instance.web["it'\''s"]'`},
+		{"", "''"},
+	}
+
+	for _, tt := range tests {
+		if got := ShellQuote(tt.arg); got != tt.want {
+			t.Errorf("ShellQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+		}
+	}
+}